@@ -8,29 +8,21 @@ import (
 
 	"github.com/justinpbarnett/agtop/internal/config"
 	gitpkg "github.com/justinpbarnett/agtop/internal/git"
+	"github.com/justinpbarnett/agtop/internal/process"
 	"github.com/justinpbarnett/agtop/internal/run"
 )
 
 const staleSessionAge = 7 * 24 * time.Hour
 
 func runCleanup(cfg *config.Config, dryRun bool) error {
-	projectRoot := cfg.Project.Root
-	if projectRoot == "" || projectRoot == "." {
-		var err error
-		projectRoot, err = os.Getwd()
-		if err != nil {
-			return fmt.Errorf("get working directory: %w", err)
-		}
-	}
-
-	persist, err := run.NewPersistence(projectRoot)
+	projectRoot, err := resolveProjectRoot(cfg)
 	if err != nil {
-		return fmt.Errorf("init persistence: %w", err)
+		return err
 	}
 
-	sessions, err := persist.Load()
+	persist, sessions, err := loadSessions(projectRoot)
 	if err != nil {
-		return fmt.Errorf("load sessions: %w", err)
+		return err
 	}
 
 	// Discover repos and create appropriate worktree manager
@@ -79,6 +71,9 @@ func runCleanup(cfg *config.Config, dryRun bool) error {
 
 		if shouldRemove {
 			if !dryRun {
+				if err := persist.Archive(sf.Run); err != nil {
+					fmt.Fprintf(os.Stderr, "  warning: archive session %s: %v\n", sf.Run.ID, err)
+				}
 				if err := persist.Remove(sf.Run.ID); err != nil {
 					fmt.Fprintf(os.Stderr, "  warning: remove session %s: %v\n", sf.Run.ID, err)
 				} else {
@@ -86,11 +81,8 @@ func runCleanup(cfg *config.Config, dryRun bool) error {
 					removedSessions++
 				}
 				// Clean up log files
-				for _, p := range []string{sf.StdoutLogPath, sf.StderrLogPath} {
-					if p != "" {
-						os.Remove(p)
-					}
-				}
+				process.RemoveLogFiles(sf.StdoutLogPath, sf.StderrLogPath,
+					process.EventLogPath(persist.SessionsDir(), sf.Run.ID))
 			} else {
 				removedSessions++
 			}
@@ -129,3 +121,31 @@ func runCleanup(cfg *config.Config, dryRun bool) error {
 	fmt.Printf("\n%sRemoved %d session files, %d orphaned worktrees.\n", prefix, removedSessions, removedWorktrees)
 	return nil
 }
+
+// resolveProjectRoot returns the configured project root, falling back to
+// the working directory when unset.
+func resolveProjectRoot(cfg *config.Config) (string, error) {
+	projectRoot := cfg.Project.Root
+	if projectRoot == "" || projectRoot == "." {
+		var err error
+		projectRoot, err = os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("get working directory: %w", err)
+		}
+	}
+	return projectRoot, nil
+}
+
+// loadSessions opens the project's session store and loads every persisted run.
+func loadSessions(projectRoot string) (*run.Persistence, []run.SessionFile, error) {
+	persist, err := run.NewPersistence(projectRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("init persistence: %w", err)
+	}
+
+	sessions, err := persist.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load sessions: %w", err)
+	}
+	return persist, sessions, nil
+}
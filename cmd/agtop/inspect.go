@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/process"
+	"github.com/justinpbarnett/agtop/internal/run"
+	"github.com/justinpbarnett/agtop/internal/ui/text"
+)
+
+const lsPromptWidth = 50
+
+// runList prints every persisted run for the project, newest first.
+func runList(cfg *config.Config, jsonOut bool) error {
+	sessions, err := projectSessions(cfg)
+	if err != nil {
+		return err
+	}
+
+	runs := make([]run.Run, 0, len(sessions))
+	for i := len(sessions) - 1; i >= 0; i-- {
+		runs = append(runs, sessions[i].Run)
+	}
+
+	if jsonOut {
+		return writeJSON(os.Stdout, runs)
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("No runs.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATE\tWORKFLOW\tSKILL\tTOKENS\tCOST\tCREATED\tPROMPT")
+	for _, r := range runs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d/%d\t%s\t%s\t%s\t%s\n",
+			r.ID, r.State, r.Workflow, r.SkillIndex, r.SkillTotal,
+			text.FormatTokens(r.Tokens), text.FormatCost(r.Cost),
+			text.RelativeTime(r.CreatedAt), text.Truncate(firstLine(r.Prompt), lsPromptWidth))
+	}
+	return tw.Flush()
+}
+
+// runShow prints a single run's record and per-skill costs.
+func runShow(cfg *config.Config, id string, jsonOut bool) error {
+	sf, err := findSession(cfg, id)
+	if err != nil {
+		return err
+	}
+	r := sf.Run
+
+	if jsonOut {
+		return writeJSON(os.Stdout, r)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", label, value)
+		}
+	}
+	field("ID", r.ID)
+	field("State", string(r.State))
+	field("Workflow", r.Workflow)
+	field("Skill", strings.TrimSpace(fmt.Sprintf("%d/%d %s", r.SkillIndex, r.SkillTotal, r.CurrentSkill)))
+	field("Branch", r.Branch)
+	field("Worktree", r.Worktree)
+	field("Task", r.TaskID)
+	field("Model", r.Model)
+	field("Session", r.SessionID)
+	field("Spec", r.SpecFile)
+	field("Tokens", fmt.Sprintf("%d (in %d, out %d)", r.Tokens, r.TokensIn, r.TokensOut))
+	if r.CacheRead+r.CacheWrite > 0 {
+		field("Cache", fmt.Sprintf("%.0f%% hit (read %d, written %d)", r.CacheHitRate()*100, r.CacheRead, r.CacheWrite))
+	}
+	field("Cost", text.FormatCost(r.Cost))
+	field("Created", formatTimestamp(r.CreatedAt))
+	field("Started", formatTimestamp(r.StartedAt))
+	field("Completed", formatTimestamp(r.CompletedAt))
+	if !r.StartedAt.IsZero() {
+		field("Elapsed", text.FormatElapsedVerbose(r.ElapsedTime()))
+	}
+	field("Merge", r.MergeStatus)
+	field("PR", r.PRURL)
+	field("Error", r.Error)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nPrompt:\n%s\n", r.Prompt)
+	for i, p := range r.FollowUpPrompts {
+		fmt.Printf("\nFollow-up %d:\n%s\n", i+1, p)
+	}
+
+	if len(r.Nodes) > 0 {
+		fmt.Println("\nSteps:")
+		tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "STEP\tSKILL\tSTATE\tNEEDS\tERROR")
+		for _, n := range r.Nodes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", n.Name, n.Skill, n.State, strings.Join(n.Needs, ","), n.Error)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Outputs) > 0 {
+		fmt.Println("\nOutputs:")
+		names := make([]string, 0, len(r.Outputs))
+		for name := range r.Outputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			data, err := json.Marshal(r.Outputs[name])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s: %s\n", name, data)
+		}
+	}
+
+	if len(r.SkillCosts) > 0 {
+		fmt.Println("\nSkill costs:")
+		tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SKILL\tIN\tOUT\tTOTAL\tCOST\tSOURCE\tPRICED\tDURATION")
+		for _, sc := range r.SkillCosts {
+			var dur string
+			if !sc.StartedAt.IsZero() && !sc.CompletedAt.IsZero() {
+				dur = text.FormatElapsedVerbose(sc.CompletedAt.Sub(sc.StartedAt))
+			}
+			name, source, priced := sc.SkillName, sc.CostSource, "-"
+			if sc.Partial {
+				name += " (partial)"
+			}
+			if source == "" {
+				source = "-"
+			}
+			if sc.PricedUSD > 0 {
+				priced = fmt.Sprintf("$%.4f", sc.PricedUSD)
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+				name, sc.InputTokens, sc.OutputTokens, sc.TotalTokens,
+				fmt.Sprintf("$%.4f", sc.CostUSD), source, priced, dur)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runLogs writes a run's stdout log file to stdout. With follow, it keeps
+// reading as the file grows until interrupted, like tail -f.
+func runLogs(cfg *config.Config, id string, follow bool) error {
+	sf, err := findSession(cfg, id)
+	if err != nil {
+		return err
+	}
+
+	// Sessions saved before log files existed only carry the log tail.
+	if sf.StdoutLogPath == "" {
+		for _, line := range sf.LogTail {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	f, err := os.Open(sf.StdoutLogPath)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	if !follow {
+		defer f.Close()
+		_, err := io.Copy(os.Stdout, f)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fr := process.NewFollowReader(ctx, f)
+	defer fr.Close()
+	if _, err := io.Copy(os.Stdout, fr); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+func projectSessions(cfg *config.Config) ([]run.SessionFile, error) {
+	projectRoot, err := resolveProjectRoot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	_, sessions, err := loadSessions(projectRoot)
+	return sessions, err
+}
+
+// findSession looks up a persisted run by ID. A unique ID prefix is accepted
+// so IDs can be abbreviated like git hashes.
+func findSession(cfg *config.Config, id string) (run.SessionFile, error) {
+	if id == "" {
+		return run.SessionFile{}, fmt.Errorf("run ID required")
+	}
+	sessions, err := projectSessions(cfg)
+	if err != nil {
+		return run.SessionFile{}, err
+	}
+
+	var matches []run.SessionFile
+	for _, sf := range sessions {
+		if sf.Run.ID == id {
+			return sf, nil
+		}
+		if strings.HasPrefix(sf.Run.ID, id) {
+			matches = append(matches, sf)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return run.SessionFile{}, fmt.Errorf("run not found: %s", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return run.SessionFile{}, fmt.Errorf("run ID %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
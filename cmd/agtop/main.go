@@ -41,6 +41,55 @@ func main() {
 				os.Exit(1)
 			}
 			return
+		case "run":
+			flags, promptWords := promptArgs(os.Args[2:], "--workflow")
+			workflow := flagValue(flags, "--workflow")
+			if workflow == "" {
+				workflow = "auto"
+			}
+			prompt := strings.Join(promptWords, " ")
+			code, err := runHeadless(cfg, workflow, prompt)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			}
+			os.Exit(code)
+		case "ls":
+			if err := runList(cfg, hasFlag(os.Args[2:], "--json")); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "show":
+			id := firstArg(positionalArgs(os.Args[2:]))
+			if err := runShow(cfg, id, hasFlag(os.Args[2:], "--json")); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "logs":
+			args := os.Args[2:]
+			id := firstArg(positionalArgs(args))
+			follow := hasFlag(args, "--follow") || hasFlag(args, "-f")
+			if err := runLogs(cfg, id, follow); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "replay":
+			args := os.Args[2:]
+			id := firstArg(positionalArgs(args, "--speed"))
+			if err := runReplay(cfg, id, flagValue(args, "--speed")); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "report":
+			args := os.Args[2:]
+			if err := runReport(cfg, flagValue(args, "--since"), flagValue(args, "--by"), flagValue(args, "--format")); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		case "version":
 			runVersion(cfg.Update.Repo)
 			return
@@ -113,3 +162,61 @@ func flagValue(args []string, flag string) string {
 	}
 	return ""
 }
+
+// positionalArgs returns args after the leading flags: flag parsing stops at
+// the first positional argument or at "--". valueFlags lists flags that
+// consume the following argument when not given in --flag=value form.
+func positionalArgs(args []string, valueFlags ...string) []string {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return args[i+1:]
+		}
+		if !strings.HasPrefix(a, "-") {
+			return args[i:]
+		}
+		for _, f := range valueFlags {
+			if a == f {
+				i++
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// promptArgs splits the arguments of agtop run into its leading
+// --flag value pairs and the prompt. Only the given flags are taken as
+// flags, so a prompt that starts with "-" is kept whole; "--" ends the
+// flags explicitly.
+func promptArgs(args []string, valueFlags ...string) (flags, prompt []string) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return args[:i], args[i+1:]
+		}
+		known := false
+		for _, f := range valueFlags {
+			if a == f {
+				known = true
+				i++
+				break
+			}
+			if strings.HasPrefix(a, f+"=") {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return args[:i], args[i:]
+		}
+	}
+	return args, nil
+}
+
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/process"
+	"github.com/justinpbarnett/agtop/internal/run"
+	"github.com/justinpbarnett/agtop/internal/runtime"
+	"github.com/justinpbarnett/agtop/internal/ui"
+)
+
+// runReplay plays a run's captured stdout log back in the dashboard. Each
+// skill's events are spread over its recorded duration, divided by speed.
+// The replay runs in its own store, so the original session is untouched.
+func runReplay(cfg *config.Config, id string, speedFlag string) error {
+	speed := 1.0
+	if speedFlag != "" {
+		v, err := strconv.ParseFloat(speedFlag, 64)
+		if err != nil || v <= 0 {
+			return fmt.Errorf("--speed must be a positive number, got %q", speedFlag)
+		}
+		speed = v
+	}
+
+	sf, err := findSession(cfg, id)
+	if err != nil {
+		return err
+	}
+	orig := sf.Run
+	if sf.StdoutLogPath == "" {
+		return fmt.Errorf("run %s has no captured log", orig.ID)
+	}
+	data, err := os.ReadFile(sf.StdoutLogPath)
+	if err != nil {
+		return fmt.Errorf("read log file: %w", err)
+	}
+	protocolFor := func(name string) string { return runtime.ProtocolFor(&cfg.Runtime, name) }
+	var skills []process.PlaybackSkill
+	if len(orig.LogSegments) > 0 {
+		skills = process.SegmentLog(data, orig.LogSegments, orig.CompletedAt, protocolFor)
+	} else {
+		skills = process.SplitLog(data, orig.SkillCosts, orig.CurrentSkill)
+	}
+	if len(skills) == 0 {
+		return fmt.Errorf("log for run %s is empty", orig.ID)
+	}
+
+	store := run.NewStore()
+	store.Add(&run.Run{
+		ID:         orig.ID,
+		Prompt:     orig.Prompt,
+		Workflow:   orig.Workflow,
+		Branch:     orig.Branch,
+		Model:      orig.Model,
+		State:      run.StateRunning,
+		SkillTotal: len(skills),
+		CreatedAt:  time.Now(),
+		StartedAt:  time.Now(),
+	})
+
+	// The playback only feeds the parser, so it is registered under the
+	// protocol of each runtime that wrote part of the log.
+	pb := process.NewPlayback(skills, speed)
+	// No sessions directory: the replay must not append to the original logs.
+	mgr := process.NewManager(store, pb, protocolFor(orig.Runtime), "", &config.LimitsConfig{}, cost.NewTracker(), &cost.LimitChecker{}, nil)
+	for _, s := range skills {
+		if s.Protocol != "" {
+			mgr.AddRuntime(s.Protocol, pb)
+		}
+	}
+	mgr.SetPricing(cfg.Pricing)
+
+	log.SetOutput(io.Discard)
+
+	p := tea.NewProgram(ui.NewReplayApp(cfg, store, mgr, pb), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	mgr.SetProgram(p)
+
+	go func() {
+		err := pb.Play(mgr, orig.ID)
+		store.Update(orig.ID, func(r *run.Run) {
+			r.State = orig.State
+			r.Error = orig.Error
+			if err != nil {
+				r.State = run.StateFailed
+				r.Error = err.Error()
+			}
+			r.CompletedAt = time.Now()
+		})
+	}()
+
+	_, err = p.Run()
+	return err
+}
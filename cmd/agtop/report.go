@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/report"
+	"github.com/justinpbarnett/agtop/internal/run"
+	"github.com/justinpbarnett/agtop/internal/ui/text"
+)
+
+// runReport prints the cost and outcome of the project's runs created in
+// the --since window, grouped by skill, workflow, model or day. Runs removed
+// from the dashboard are read back from the session archive.
+func runReport(cfg *config.Config, sinceFlag, by, format string) error {
+	if sinceFlag == "" {
+		sinceFlag = "30d"
+	}
+	if by == "" {
+		by = report.ByWorkflow
+	}
+	if format == "" {
+		format = "table"
+	}
+	if format != "table" && format != "csv" && format != "json" {
+		return fmt.Errorf("--format must be table, csv or json, got %q", format)
+	}
+	since, err := report.ParseSince(sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	runs, err := reportRuns(cfg)
+	if err != nil {
+		return err
+	}
+	rep, err := report.Build(runs, by, since)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return writeJSON(os.Stdout, rep)
+	case "csv":
+		return writeReportCSV(rep)
+	}
+
+	if rep.Total.Runs == 0 {
+		fmt.Println("No runs.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tRUNS\tACCEPTED\tFAILED\tSUCCESS\tTOKENS\tCOST\tAVG/RUN\tPER ACCEPTED\n", reportHeader(by))
+	for _, row := range append(rep.Rows, rep.Total) {
+		success, perAccepted := "-", "-"
+		if row.Decided() > 0 {
+			success = fmt.Sprintf("%.0f%%", row.SuccessRate*100)
+		}
+		if row.Accepted > 0 {
+			perAccepted = text.FormatCost(row.CostPerAccepted)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			row.Key, row.Runs, row.Accepted, row.Failed, success,
+			text.FormatTokens(row.Tokens), text.FormatCost(row.CostUSD),
+			text.FormatCost(row.AvgCostUSD), perAccepted)
+	}
+	return tw.Flush()
+}
+
+// reportRuns returns the project's persisted runs together with the ones
+// archived when they were removed.
+func reportRuns(cfg *config.Config) ([]run.Run, error) {
+	projectRoot, err := resolveProjectRoot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	persist, sessions, err := loadSessions(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := persist.LoadArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	// A run whose session file outlived its removal is counted once, by
+	// its archived record.
+	seen := make(map[string]int)
+	var runs []run.Run
+	add := func(r run.Run) {
+		if i, ok := seen[r.ID]; ok {
+			runs[i] = r
+			return
+		}
+		seen[r.ID] = len(runs)
+		runs = append(runs, r)
+	}
+	for _, sf := range sessions {
+		add(sf.Run)
+	}
+	for _, ar := range archived {
+		add(ar.Run)
+	}
+	return runs, nil
+}
+
+func writeReportCSV(rep report.Report) error {
+	w := csv.NewWriter(os.Stdout)
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	if err := w.Write([]string{rep.By, "runs", "accepted", "failed", "success_rate", "tokens", "cost_usd", "avg_cost_usd", "cost_per_accepted_usd"}); err != nil {
+		return err
+	}
+	for _, row := range append(rep.Rows, rep.Total) {
+		if err := w.Write([]string{
+			row.Key,
+			strconv.Itoa(row.Runs),
+			strconv.Itoa(row.Accepted),
+			strconv.Itoa(row.Failed),
+			strconv.FormatFloat(row.SuccessRate, 'f', 4, 64),
+			strconv.Itoa(row.Tokens),
+			money(row.CostUSD),
+			money(row.AvgCostUSD),
+			money(row.CostPerAccepted),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func reportHeader(by string) string {
+	switch by {
+	case report.BySkill:
+		return "SKILL"
+	case report.ByModel:
+		return "MODEL"
+	case report.ByDay:
+		return "DAY"
+	}
+	return "WORKFLOW"
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/engine"
+	gitpkg "github.com/justinpbarnett/agtop/internal/git"
+	"github.com/justinpbarnett/agtop/internal/process"
+	"github.com/justinpbarnett/agtop/internal/run"
+	"github.com/justinpbarnett/agtop/internal/runtime"
+	"github.com/justinpbarnett/agtop/internal/safety"
+	"github.com/justinpbarnett/agtop/skills"
+)
+
+// Exit codes for headless runs, derived from the final run state.
+const (
+	exitCompleted = 0
+	exitFailed    = 1
+	exitReviewing = 2
+	exitPaused    = 3
+	exitApproval  = 4
+)
+
+const headlessPollInterval = 200 * time.Millisecond
+
+// runHeadless executes a workflow without the TUI, streaming log lines to
+// stdout until the run reaches a terminal state. It returns the process exit
+// code for the final run state.
+func runHeadless(cfg *config.Config, workflow, prompt string) (int, error) {
+	if strings.TrimSpace(prompt) == "" {
+		return exitFailed, fmt.Errorf("usage: agtop run [--workflow <name>] \"prompt\"")
+	}
+
+	// Internal packages log warnings via the standard logger; keep them
+	// off stdout so they don't interleave with the run's log stream.
+	log.SetOutput(io.Discard)
+
+	projectRoot, err := resolveProjectRoot(cfg)
+	if err != nil {
+		return exitFailed, err
+	}
+
+	store := run.NewStore()
+
+	tracker := cost.NewTracker()
+	maxCostPerRun := cfg.Limits.MaxCostPerRun
+	budget := cost.Budget{
+		Daily:   cfg.Limits.DailyBudget,
+		Weekly:  cfg.Limits.WeeklyBudget,
+		Monthly: cfg.Limits.MonthlyBudget,
+	}
+	if cfg.Runtime.Default == "claude" && cfg.Runtime.Claude.Subscription {
+		// Subscription billing — disable the cost threshold and budgets.
+		maxCostPerRun = 0
+		budget = cost.Budget{}
+	}
+	limiter := &cost.LimitChecker{
+		MaxTokensPerRun: cfg.Limits.MaxTokensPerRun,
+		MaxCostPerRun:   maxCostPerRun,
+		Budget:          budget,
+	}
+
+	var safetyMatcher *safety.PatternMatcher
+	safetyEngine, safetyErr := safety.NewHookEngine(cfg.Safety)
+	if safetyErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", safetyErr)
+	}
+	if safetyEngine != nil {
+		safetyMatcher = safetyEngine.Matcher()
+	}
+
+	persist, err := run.NewPersistence(projectRoot)
+	if err != nil {
+		return exitFailed, fmt.Errorf("init persistence: %w", err)
+	}
+
+	rt, rtName, err := runtime.NewRuntime(&cfg.Runtime)
+	if err != nil {
+		return exitFailed, err
+	}
+	mgr := process.NewManager(store, rt, rtName, persist.SessionsDir(), &cfg.Limits, tracker, limiter, safetyMatcher)
+	mgr.SetPricing(cfg.Pricing)
+	mgr.SetSkillLimits(cfg.Skills)
+	if dir, err := cost.LedgerDir(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: spend ledger: %v\n", err)
+	} else {
+		mgr.SetLedger(cost.NewLedger(dir))
+	}
+	if err := mgr.CheckBudget(); err != nil {
+		return exitFailed, fmt.Errorf("not starting run: %w", err)
+	}
+	for _, name := range cfg.RuntimeOverrides() {
+		if name == rtName {
+			continue
+		}
+		extra, err := runtime.NewRuntimeByName(&cfg.Runtime, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: runtime %s: %v\n", name, err)
+			continue
+		}
+		mgr.AddRuntime(name, extra)
+	}
+
+	reg := engine.NewRegistry(cfg)
+	if err := reg.Load(projectRoot, skills.FS); err != nil {
+		return exitFailed, fmt.Errorf("load skills: %w", err)
+	}
+	for _, w := range append(cfg.Warnings(), reg.PricingWarnings()...) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	exec := engine.NewExecutor(store, mgr, reg, cfg)
+	warnForecast(persist, tracker, limiter, exec.PlannedSteps(workflow))
+
+	// Persist the run so it shows up in the TUI and can be accepted later.
+	persist.BindStore(store, func(runID string) []string {
+		if buf := mgr.Buffer(runID); buf != nil {
+			return buf.Tail(1000)
+		}
+		return nil
+	}, mgr.LogFilePaths)
+
+	runID := store.Add(&run.Run{
+		Workflow:  workflow,
+		Prompt:    prompt,
+		State:     run.StateQueued,
+		CreatedAt: time.Now(),
+	})
+
+	// The process exits right after this returns; save the run first.
+	defer persist.FinalSave(store, mgr.LogFilePaths)
+
+	wt := gitpkg.NewProjectWorktreeManager(projectRoot, gitpkg.ProjectRepos(projectRoot, cfg.Project.Repos), cfg.Project.WorktreePath)
+	if err := engine.CreateWorktree(wt, store, runID, cfg.Repos); err != nil {
+		return exitFailed, err
+	}
+
+	r, _ := store.Get(runID)
+	fmt.Printf("agtop: run %s (workflow=%s, branch=%s)\n", runID, workflow, r.Branch)
+	fmt.Printf("agtop: worktree %s\n", r.Worktree)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	exec.Execute(runID, workflow, prompt)
+
+	ticker := time.NewTicker(headlessPollInterval)
+	defer ticker.Stop()
+
+	printed := 0
+	for {
+		select {
+		case <-sigCh:
+			fmt.Fprintln(os.Stderr, "agtop: interrupted, cancelling run")
+			exec.Cancel(runID)
+		case <-ticker.C:
+		}
+
+		printed = flushLogLines(mgr.Buffer(runID), printed)
+
+		r, ok := store.Get(runID)
+		if !ok {
+			return exitFailed, fmt.Errorf("run %s disappeared from store", runID)
+		}
+		if exec.IsActive(runID) {
+			// Nothing can resume a paused run once this process exits, so
+			// its agent is killed and the run cancelled rather than left
+			// stopped. It can be restarted from the TUI.
+			if r.State == run.StatePaused {
+				done := exec.Done(runID)
+				exec.Cancel(runID)
+				mgr.KillAll()
+				<-done
+				printed = flushLogLines(mgr.Buffer(runID), printed)
+				r, _ = store.Get(runID)
+				printSummary(r)
+				return exitPaused, nil
+			}
+			// An approve gate holds no agent. Steps still running beside
+			// it are stopped, and the run stays parked at the gate so the
+			// TUI can approve it once it loads the session.
+			if r.State == run.StateAwaitingApproval {
+				exec.Shutdown()
+				mgr.KillAll()
+				printed = flushLogLines(mgr.Buffer(runID), printed)
+				printSummary(r)
+				return exitApproval, nil
+			}
+			continue
+		}
+		if !r.IsTerminal() {
+			continue
+		}
+
+		printed = flushLogLines(mgr.Buffer(runID), printed)
+		printSummary(r)
+		return headlessExitCode(r.State), nil
+	}
+}
+
+// flushLogLines prints lines appended to buf since the last flush and
+// returns the new high-water mark. Lines evicted from the ring buffer
+// before they could be printed are reported as skipped.
+func flushLogLines(buf *process.RingBuffer, printed int) int {
+	if buf == nil {
+		return printed
+	}
+	total := buf.TotalWritten()
+	if total < printed {
+		// Buffer was reset; start over.
+		printed = 0
+	}
+	pending := total - printed
+	if pending <= 0 {
+		return total
+	}
+	if n := buf.Len(); pending > n {
+		fmt.Printf("... %d lines skipped ...\n", pending-n)
+		pending = n
+	}
+	for _, line := range buf.Tail(pending) {
+		fmt.Println(line)
+	}
+	return total
+}
+
+func printSummary(r run.Run) {
+	fmt.Println()
+	fmt.Printf("agtop: run %s %s", r.ID, r.State)
+	if r.Error != "" {
+		fmt.Printf(" (%s)", r.Error)
+	}
+	fmt.Println()
+	fmt.Printf("agtop: tokens=%d cost=$%.2f elapsed=%s\n", r.Tokens, r.Cost, r.ElapsedTime().Round(time.Second))
+}
+
+// headlessExitCode maps a final run state to a process exit code.
+func headlessExitCode(state run.State) int {
+	switch state {
+	case run.StateCompleted, run.StateAccepted:
+		return exitCompleted
+	case run.StateReviewing:
+		return exitReviewing
+	case run.StatePaused:
+		return exitPaused
+	case run.StateAwaitingApproval:
+		return exitApproval
+	default:
+		return exitFailed
+	}
+}
+
+// warnForecast prints a warning when the forecast cost of the planned steps,
+// estimated from the project's past and archived runs, reaches the per-run
+// cost threshold. The run starts regardless.
+func warnForecast(persist *run.Persistence, tracker *cost.Tracker, limiter *cost.LimitChecker, steps []cost.Step) {
+	if len(steps) == 0 || limiter.MaxCostPerRun <= 0 {
+		return
+	}
+	sessions, _ := persist.Load()
+	for _, sf := range sessions {
+		tracker.AddHistory(sf.Run.SkillCosts...)
+	}
+	archived, _ := persist.LoadArchive()
+	for _, ar := range archived {
+		tracker.AddHistory(ar.Run.SkillCosts...)
+	}
+	f, ok := tracker.Forecast(steps)
+	if !ok {
+		return
+	}
+	if exceeded, reason := limiter.CheckForecast(f); exceeded {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", reason, f)
+	}
+}
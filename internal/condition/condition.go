@@ -0,0 +1,409 @@
+// Package condition parses and evaluates the `when` expressions of workflow
+// steps, such as `review.success == false`. The first segment of a path names
+// a workflow step; the rest walks that step's JSON output.
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expr is a parsed condition.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Lookup returns the decoded JSON output of a step, or nil if it has none.
+type Lookup func(step string) interface{}
+
+// Parse compiles a condition. Supported syntax: dotted paths (with numeric
+// segments indexing arrays), true/false/null, numbers, quoted strings, the
+// comparisons == != < <= > >=, and !, &&, || with parentheses.
+func Parse(src string) (*Expr, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q in condition %q", p.toks[p.pos].text, src)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source text of the condition.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Steps returns the step names the condition refers to, in order of first use.
+func (e *Expr) Steps() []string {
+	var steps []string
+	seen := make(map[string]bool)
+	walk(e.root, func(p pathNode) {
+		if !seen[p[0]] {
+			seen[p[0]] = true
+			steps = append(steps, p[0])
+		}
+	})
+	return steps
+}
+
+// Eval evaluates the condition. Paths that do not resolve evaluate to null.
+func (e *Expr) Eval(lookup Lookup) bool {
+	return truthy(e.root.eval(lookup))
+}
+
+// DecodeOutput extracts a JSON value from skill output. Skills often wrap
+// their JSON report in prose or code fences, so the outermost {...} is tried
+// when the whole text does not parse. Returns nil if nothing decodes.
+func DecodeOutput(text string) interface{} {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	var v interface{}
+	if json.Unmarshal([]byte(text), &v) == nil {
+		return v
+	}
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start >= 0 && end > start {
+		if json.Unmarshal([]byte(text[start:end+1]), &v) == nil {
+			return v
+		}
+	}
+	return nil
+}
+
+type node interface {
+	eval(Lookup) interface{}
+}
+
+type literalNode struct{ v interface{} }
+
+type pathNode []string
+
+type notNode struct{ x node }
+
+type logicNode struct {
+	op   string
+	l, r node
+}
+
+type compareNode struct {
+	op   string
+	l, r node
+}
+
+func (n literalNode) eval(Lookup) interface{} { return n.v }
+
+func (n pathNode) eval(lookup Lookup) interface{} {
+	v := lookup(n[0])
+	for _, seg := range n[1:] {
+		switch c := v.(type) {
+		case map[string]interface{}:
+			v = c[seg]
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil
+			}
+			v = c[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+func (n notNode) eval(lookup Lookup) interface{} { return !truthy(n.x.eval(lookup)) }
+
+func (n logicNode) eval(lookup Lookup) interface{} {
+	if n.op == "&&" {
+		return truthy(n.l.eval(lookup)) && truthy(n.r.eval(lookup))
+	}
+	return truthy(n.l.eval(lookup)) || truthy(n.r.eval(lookup))
+}
+
+func (n compareNode) eval(lookup Lookup) interface{} {
+	l, r := n.l.eval(lookup), n.r.eval(lookup)
+	switch n.op {
+	case "==":
+		return equal(l, r)
+	case "!=":
+		return !equal(l, r)
+	}
+
+	if lf, ok := l.(float64); ok {
+		if rf, ok := r.(float64); ok {
+			return compare(n.op, lf < rf, lf == rf)
+		}
+	}
+	if ls, ok := l.(string); ok {
+		if rs, ok := r.(string); ok {
+			return compare(n.op, ls < rs, ls == rs)
+		}
+	}
+	return false
+}
+
+func compare(op string, less, eq bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return less || eq
+	case ">":
+		return !less && !eq
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case float64:
+		bv, ok := b.(float64)
+		return ok && av == bv
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	}
+	// Objects and arrays are never equal to a literal.
+	return false
+}
+
+func truthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		return x != ""
+	case []interface{}:
+		return len(x) > 0
+	case map[string]interface{}:
+		return len(x) > 0
+	}
+	return true
+}
+
+func walk(n node, fn func(pathNode)) {
+	switch x := n.(type) {
+	case pathNode:
+		fn(x)
+	case notNode:
+		walk(x.x, fn)
+	case logicNode:
+		walk(x.l, fn)
+		walk(x.r, fn)
+	case compareNode:
+		walk(x.l, fn)
+		walk(x.r, fn)
+	}
+}
+
+// --- Parser ---
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in condition %q", src)
+			}
+			toks = append(toks, token{tokString, src[i+1 : i+1+end]})
+			i += end + 2
+		case c == '(' || c == ')':
+			toks = append(toks, token{tokOp, string(c)})
+			i++
+		case strings.HasPrefix(src[i:], "==") || strings.HasPrefix(src[i:], "!=") ||
+			strings.HasPrefix(src[i:], "<=") || strings.HasPrefix(src[i:], ">=") ||
+			strings.HasPrefix(src[i:], "&&") || strings.HasPrefix(src[i:], "||"):
+			toks = append(toks, token{tokOp, src[i : i+2]})
+			i += 2
+		case c == '<' || c == '>' || c == '!':
+			toks = append(toks, token{tokOp, string(c)})
+			i++
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(src) && (src[j] == '.' || (src[j] >= '0' && src[j] <= '9')) {
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j]})
+			i = j
+		case isIdentChar(c):
+			j := i
+			for j < len(src) && (isIdentChar(src[j]) || src[j] == '.' || src[j] == '-') {
+				j++
+			}
+			toks = append(toks, token{tokIdent, src[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q in condition %q", c, src)
+		}
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	return toks, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peekOp(ops ...string) string {
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokOp {
+		return ""
+	}
+	for _, op := range ops {
+		if p.toks[p.pos].text == op {
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") != "" {
+		p.pos++
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = logicNode{op: "||", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") != "" {
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = logicNode{op: "&&", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peekOp("!") != "" {
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x: x}, nil
+	}
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peekOp("==", "!=", "<", "<=", ">", ">="); op != "" {
+		p.pos++
+		r, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("unexpected end of condition")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokString:
+		return literalNode{t.text}, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return literalNode{f}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		case "null":
+			return literalNode{nil}, nil
+		}
+		segs := strings.Split(t.text, ".")
+		for _, s := range segs {
+			if s == "" {
+				return nil, fmt.Errorf("invalid path %q", t.text)
+			}
+		}
+		return pathNode(segs), nil
+	}
+	if t.text == "(" {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peekOp(")") == "" {
+			return nil, fmt.Errorf("missing ) in condition")
+		}
+		p.pos++
+		return x, nil
+	}
+	return nil, fmt.Errorf("unexpected %q in condition", t.text)
+}
@@ -0,0 +1,83 @@
+package condition
+
+import (
+	"strings"
+	"testing"
+)
+
+func lookupFrom(outputs map[string]string) Lookup {
+	return func(step string) interface{} {
+		return DecodeOutput(outputs[step])
+	}
+}
+
+func TestEval(t *testing.T) {
+	lookup := lookupFrom(map[string]string{
+		"review":     `{"success": false, "issues": [{"severity": "high"}], "score": 6.5}`,
+		"test":       "All checks ran.\n```json\n{\"passed\": 12, \"failed\": 0}\n```",
+		"spec-build": `{"path": "specs/x.md"}`,
+	})
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`review.success == false`, true},
+		{`review.success == true`, false},
+		{`review.success != true`, true},
+		{`!review.success`, true},
+		{`review.issues`, true},
+		{`review.issues.0.severity == "high"`, true},
+		{`review.issues.1.severity == "high"`, false},
+		{`review.score >= 6`, true},
+		{`review.score < 6`, false},
+		{`test.failed == 0 && test.passed > 10`, true},
+		{`test.failed > 0 || review.success`, false},
+		{`!(test.failed > 0 || review.success)`, true},
+		{`spec-build.path == 'specs/x.md'`, true},
+		{`missing.success == null`, true},
+		{`missing.success`, false},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := e.Eval(lookup); got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`review.success ==`,
+		`review.success = false`,
+		`(review.success`,
+		`review..success`,
+		`"unterminated`,
+		`review.success false`,
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error", expr)
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+	e, err := Parse(`review.success == false && (test.failed > 0 || review.score < 5)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(e.Steps(), ","); got != "review,test" {
+		t.Errorf("Steps() = %s, want review,test", got)
+	}
+}
+
+func TestDecodeOutputNonJSON(t *testing.T) {
+	if v := DecodeOutput("no json here"); v != nil {
+		t.Errorf("expected nil, got %v", v)
+	}
+}
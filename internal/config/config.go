@@ -1,5 +1,10 @@
 package config
 
+import (
+	"sort"
+	"strings"
+)
+
 type Config struct {
 	Project      ProjectConfig             `toml:"project"`
 	Repos        []RepoConfig              `toml:"repos"`
@@ -8,6 +13,7 @@ type Config struct {
 	Skills       map[string]SkillConfig    `toml:"skills"`
 	Safety       SafetyConfig              `toml:"safety"`
 	Limits       LimitsConfig              `toml:"limits"`
+	Pricing      map[string]PricingConfig  `toml:"pricing"`
 	Merge        MergeConfig               `toml:"merge"`
 	UI           UIConfig                  `toml:"ui"`
 	Update       UpdateConfig              `toml:"update"`
@@ -47,9 +53,13 @@ type DevServerConfig struct {
 }
 
 type RuntimeConfig struct {
-	Default  string         `toml:"default"`
-	Claude   ClaudeConfig   `toml:"claude"`
-	OpenCode OpenCodeConfig `toml:"opencode"`
+	Default  string                         `toml:"default"`
+	Claude   ClaudeConfig                   `toml:"claude"`
+	OpenCode OpenCodeConfig                 `toml:"opencode"`
+	Codex    CodexConfig                    `toml:"codex"`
+	Aider    AiderConfig                    `toml:"aider"`
+	Replay   ReplayConfig                   `toml:"replay"`
+	Custom   map[string]CustomRuntimeConfig `toml:"custom"`
 }
 
 type ClaudeConfig struct {
@@ -65,8 +75,96 @@ type OpenCodeConfig struct {
 	Agent string `toml:"agent"`
 }
 
+type CodexConfig struct {
+	Model   string `toml:"model"`
+	Sandbox string `toml:"sandbox"`
+}
+
+type AiderConfig struct {
+	Model string `toml:"model"`
+}
+
+type ReplayConfig struct {
+	Dir   string `toml:"dir"`
+	Delay int    `toml:"delay"`
+}
+
+type CustomRuntimeConfig struct {
+	Command  []string `toml:"command"`
+	Protocol string   `toml:"protocol"`
+	Model    string   `toml:"model"`
+}
+
 type WorkflowConfig struct {
-	Skills []string `toml:"skills"`
+	Skills  []string     `toml:"skills"`
+	Steps   []StepConfig `toml:"steps"`
+	Runtime string       `toml:"runtime"`
+	Session string       `toml:"session"`
+}
+
+// Session modes of a workflow. By default every skill starts a fresh agent
+// conversation; resume and continue let later skills and follow-ups carry on
+// the run's conversation instead, with claude's --resume <id> or --continue.
+const (
+	SessionFresh    = "fresh"
+	SessionResume   = "resume"
+	SessionContinue = "continue"
+)
+
+// RuntimeOverrides returns the runtimes named by [skills.<name>] or
+// [workflows.<name>] runtime settings, sorted and without duplicates. These
+// must be started alongside the default runtime.
+func (c *Config) RuntimeOverrides() []string {
+	seen := make(map[string]bool)
+	for _, sc := range c.Skills {
+		if sc.Runtime != "" {
+			seen[sc.Runtime] = true
+		}
+	}
+	for _, wf := range c.Workflows {
+		if wf.Runtime != "" {
+			seen[wf.Runtime] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApproveGate is the built-in workflow step that waits for a person to
+// approve the run before later skills start.
+const ApproveGate = "approve"
+
+// WorkflowRefPrefix marks a workflow skills entry that includes another
+// workflow, e.g. "workflow:plan-build".
+const WorkflowRefPrefix = "workflow:"
+
+// WorkflowRef returns the included workflow name if entry is a workflow reference.
+func WorkflowRef(entry string) (string, bool) {
+	if strings.HasPrefix(entry, WorkflowRefPrefix) {
+		return strings.TrimPrefix(entry, WorkflowRefPrefix), true
+	}
+	return "", false
+}
+
+type StepConfig struct {
+	Name     string   `toml:"name"`
+	Skill    string   `toml:"skill"`
+	Needs    []string `toml:"needs"`
+	When     string   `toml:"when"`
+	Loop     string   `toml:"loop"`
+	MaxLoops int      `toml:"max_loops"`
+}
+
+// StepName returns the node name of a step, defaulting to its skill.
+func (s StepConfig) StepName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Skill
 }
 
 type SkillConfig struct {
@@ -75,11 +173,23 @@ type SkillConfig struct {
 	Parallel     bool     `toml:"parallel"`
 	AllowedTools []string `toml:"allowed_tools"`
 	Ignore       bool     `toml:"ignore"`
+	Runtime      string   `toml:"runtime"`
+	MaxCost      float64  `toml:"max_cost"`
+	MaxTurns     int      `toml:"max_turns"`
 }
 
 type SafetyConfig struct {
-	BlockedPatterns []string `toml:"blocked_patterns"`
-	AllowOverrides  *bool    `toml:"allow_overrides"`
+	BlockedPatterns []string      `toml:"blocked_patterns"`
+	AllowOverrides  *bool         `toml:"allow_overrides"`
+	Sandbox         SandboxConfig `toml:"sandbox"`
+}
+
+type SandboxConfig struct {
+	Type    string   `toml:"type"`
+	Network *bool    `toml:"network"`
+	Image   string   `toml:"image"`
+	Expose  []string `toml:"expose"`
+	Env     []string `toml:"env"`
 }
 
 type LimitsConfig struct {
@@ -88,6 +198,16 @@ type LimitsConfig struct {
 	MaxConcurrentRuns   int     `toml:"max_concurrent_runs"`
 	RateLimitBackoff    int     `toml:"rate_limit_backoff"`
 	RateLimitMaxRetries int     `toml:"rate_limit_max_retries"`
+	DailyBudget         float64 `toml:"daily_budget"`
+	WeeklyBudget        float64 `toml:"weekly_budget"`
+	MonthlyBudget       float64 `toml:"monthly_budget"`
+}
+
+type PricingConfig struct {
+	Input      float64 `toml:"input"`
+	Output     float64 `toml:"output"`
+	CacheRead  float64 `toml:"cache_read"`
+	CacheWrite float64 `toml:"cache_write"`
 }
 
 type MergeConfig struct {
@@ -23,6 +23,16 @@ func DefaultConfig() Config {
 				Model: "anthropic/claude-sonnet-4-6",
 				Agent: "build",
 			},
+			Codex: CodexConfig{
+				Model:   "gpt-5-codex",
+				Sandbox: "workspace-write",
+			},
+			Aider: AiderConfig{
+				Model: "sonnet",
+			},
+			Replay: ReplayConfig{
+				Dir: ".agtop/replay",
+			},
 		},
 		Workflows: map[string]WorkflowConfig{
 			"auto":       {Skills: []string{"route"}},
@@ -52,6 +62,17 @@ func DefaultConfig() Config {
 				`:(){.*};`,
 			},
 			AllowOverrides: boolPtr(false),
+			Sandbox: SandboxConfig{
+				Network: boolPtr(true),
+				Expose: []string{
+					".claude",
+					".claude.json",
+					".codex",
+					".config/opencode",
+					".local/share/opencode",
+				},
+				Env: []string{"ANTHROPIC_API_KEY", "OPENAI_API_KEY"},
+			},
 		},
 		Limits: LimitsConfig{
 			MaxTokensPerRun:     500000,
@@ -60,6 +81,22 @@ func DefaultConfig() Config {
 			RateLimitBackoff:    60,
 			RateLimitMaxRetries: 3,
 		},
+		// USD per million tokens. Keys match exactly or as part of a model name.
+		Pricing: map[string]PricingConfig{
+			"opus":             {Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25},
+			"claude-opus-4":    {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+			"claude-opus-4-5":  {Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25},
+			"claude-opus-4-6":  {Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25},
+			"sonnet":           {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+			"haiku":            {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+			"claude-3-5-haiku": {Input: 0.8, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+			"gpt-5":            {Input: 1.25, Output: 10, CacheRead: 0.125},
+			"gpt-5-mini":       {Input: 0.25, Output: 2, CacheRead: 0.025},
+			"gpt-5-nano":       {Input: 0.05, Output: 0.4, CacheRead: 0.005},
+			"gpt-4.1":          {Input: 2, Output: 8, CacheRead: 0.5},
+			"gemini-2.5-pro":   {Input: 1.25, Output: 10, CacheRead: 0.31},
+			"gemini-2.5-flash": {Input: 0.3, Output: 2.5, CacheRead: 0.075},
+		},
 		Merge: MergeConfig{
 			ConflictResolutionAttempts: 3,
 		},
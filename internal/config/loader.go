@@ -103,6 +103,8 @@ func loadFromFile(path string) (*Config, error) {
 
 // normalizeWorkflows converts shorthand workflow arrays into table form.
 // e.g. workflows.quick = ["build"] -> workflows.quick = {skills: ["build"]}
+// An array of tables is treated as a step graph:
+// workflows.x = [{skill = "spec"}, ...] -> workflows.x = {steps: [...]}
 func normalizeWorkflows(raw map[string]interface{}) {
 	wf, ok := raw["workflows"]
 	if !ok {
@@ -116,7 +118,20 @@ func normalizeWorkflows(raw map[string]interface{}) {
 		if arr, ok := v.([]interface{}); ok {
 			skills := make([]interface{}, len(arr))
 			copy(skills, arr)
-			wfMap[k] = map[string]interface{}{"skills": skills}
+			key := "skills"
+			if len(arr) > 0 {
+				if _, isTable := arr[0].(map[string]interface{}); isTable {
+					key = "steps"
+				}
+			}
+			wfMap[k] = map[string]interface{}{key: skills}
+		}
+		if tables, ok := v.([]map[string]interface{}); ok {
+			steps := make([]interface{}, len(tables))
+			for i, t := range tables {
+				steps[i] = t
+			}
+			wfMap[k] = map[string]interface{}{"steps": steps}
 		}
 	}
 }
@@ -190,6 +205,29 @@ func merge(base *Config, override *Config) {
 	if override.Runtime.OpenCode.Agent != "" {
 		base.Runtime.OpenCode.Agent = override.Runtime.OpenCode.Agent
 	}
+	if override.Runtime.Codex.Model != "" {
+		base.Runtime.Codex.Model = override.Runtime.Codex.Model
+	}
+	if override.Runtime.Codex.Sandbox != "" {
+		base.Runtime.Codex.Sandbox = override.Runtime.Codex.Sandbox
+	}
+	if override.Runtime.Aider.Model != "" {
+		base.Runtime.Aider.Model = override.Runtime.Aider.Model
+	}
+	if override.Runtime.Replay.Dir != "" {
+		base.Runtime.Replay.Dir = override.Runtime.Replay.Dir
+	}
+	if override.Runtime.Replay.Delay != 0 {
+		base.Runtime.Replay.Delay = override.Runtime.Replay.Delay
+	}
+	if override.Runtime.Custom != nil {
+		if base.Runtime.Custom == nil {
+			base.Runtime.Custom = make(map[string]CustomRuntimeConfig)
+		}
+		for k, v := range override.Runtime.Custom {
+			base.Runtime.Custom[k] = v
+		}
+	}
 
 	// Workflows — merge at key level
 	if override.Workflows != nil {
@@ -201,6 +239,16 @@ func merge(base *Config, override *Config) {
 		}
 	}
 
+	// Pricing — merge at key level
+	if override.Pricing != nil {
+		if base.Pricing == nil {
+			base.Pricing = make(map[string]PricingConfig)
+		}
+		for k, v := range override.Pricing {
+			base.Pricing[k] = v
+		}
+	}
+
 	// Skills — merge at key level
 	if override.Skills != nil {
 		if base.Skills == nil {
@@ -218,6 +266,21 @@ func merge(base *Config, override *Config) {
 	if override.Safety.AllowOverrides != nil {
 		base.Safety.AllowOverrides = override.Safety.AllowOverrides
 	}
+	if override.Safety.Sandbox.Type != "" {
+		base.Safety.Sandbox.Type = override.Safety.Sandbox.Type
+	}
+	if override.Safety.Sandbox.Network != nil {
+		base.Safety.Sandbox.Network = override.Safety.Sandbox.Network
+	}
+	if override.Safety.Sandbox.Image != "" {
+		base.Safety.Sandbox.Image = override.Safety.Sandbox.Image
+	}
+	if override.Safety.Sandbox.Expose != nil {
+		base.Safety.Sandbox.Expose = override.Safety.Sandbox.Expose
+	}
+	if override.Safety.Sandbox.Env != nil {
+		base.Safety.Sandbox.Env = override.Safety.Sandbox.Env
+	}
 
 	// Limits
 	if override.Limits.MaxTokensPerRun != 0 {
@@ -229,6 +292,15 @@ func merge(base *Config, override *Config) {
 	if override.Limits.MaxConcurrentRuns != 0 {
 		base.Limits.MaxConcurrentRuns = override.Limits.MaxConcurrentRuns
 	}
+	if override.Limits.DailyBudget != 0 {
+		base.Limits.DailyBudget = override.Limits.DailyBudget
+	}
+	if override.Limits.WeeklyBudget != 0 {
+		base.Limits.WeeklyBudget = override.Limits.WeeklyBudget
+	}
+	if override.Limits.MonthlyBudget != 0 {
+		base.Limits.MonthlyBudget = override.Limits.MonthlyBudget
+	}
 	if override.Limits.RateLimitBackoff != 0 {
 		base.Limits.RateLimitBackoff = override.Limits.RateLimitBackoff
 	}
@@ -531,3 +531,120 @@ func TestLoadMergeConfigDefaults(t *testing.T) {
 		t.Errorf("expected default poll_timeout 0, got %d", cfg.Merge.PollTimeout)
 	}
 }
+
+func TestLoadWorkflowSteps(t *testing.T) {
+	t.Parallel()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "agtop.toml"), []byte(`
+[workflows]
+parallel = [
+  { skill = "spec" },
+  { skill = "build", needs = ["spec"] },
+  { skill = "document", needs = ["spec"] },
+]
+
+[[workflows.tables.steps]]
+skill = "build"
+
+[[workflows.tables.steps]]
+name = "verify"
+skill = "test"
+needs = ["build"]
+`), 0644)
+
+	cfg, err := LoadFrom(tmp)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+
+	wf := cfg.Workflows["parallel"]
+	if len(wf.Skills) != 0 || len(wf.Steps) != 3 {
+		t.Fatalf("expected 3 steps and no skills, got %+v", wf)
+	}
+	if wf.Steps[2].Skill != "document" || len(wf.Steps[2].Needs) != 1 || wf.Steps[2].Needs[0] != "spec" {
+		t.Errorf("unexpected third step: %+v", wf.Steps[2])
+	}
+
+	tables := cfg.Workflows["tables"]
+	if len(tables.Steps) != 2 || tables.Steps[1].StepName() != "verify" {
+		t.Errorf("unexpected table steps: %+v", tables.Steps)
+	}
+}
+
+func TestLoadCustomRuntime(t *testing.T) {
+	t.Parallel()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "agtop.toml"), []byte(`
+[runtime]
+default = "wrapper"
+
+[runtime.custom.wrapper]
+command = ["./scripts/agent.sh", "--model", "{model}", "{prompt}"]
+protocol = "stream-json"
+model = "opus"
+`), 0644)
+
+	cfg, err := LoadFrom(tmp)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+
+	c, ok := cfg.Runtime.Custom["wrapper"]
+	if !ok {
+		t.Fatal("expected runtime.custom.wrapper to be loaded")
+	}
+	if len(c.Command) != 4 || c.Command[3] != "{prompt}" || c.Protocol != "stream-json" || c.Model != "opus" {
+		t.Errorf("unexpected custom runtime: %+v", c)
+	}
+}
+
+func TestLoadPricing(t *testing.T) {
+	t.Parallel()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "agtop.toml"), []byte(`
+[pricing.sonnet]
+input = 2.5
+output = 12
+
+[pricing."qwen3-coder"]
+input = 0.4
+output = 1.6
+cache_read = 0.1
+`), 0644)
+
+	cfg, err := LoadFrom(tmp)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+
+	if p := cfg.Pricing["sonnet"]; p.Input != 2.5 || p.Output != 12 || p.CacheRead != 0 {
+		t.Errorf("expected sonnet pricing to be overridden, got %+v", p)
+	}
+	if p := cfg.Pricing["qwen3-coder"]; p.Input != 0.4 || p.Output != 1.6 || p.CacheRead != 0.1 {
+		t.Errorf("expected qwen3-coder pricing to be added, got %+v", p)
+	}
+	if _, ok := cfg.Pricing["opus"]; !ok {
+		t.Error("expected built-in opus pricing to be kept")
+	}
+}
+
+func TestLoadBudgets(t *testing.T) {
+	t.Parallel()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "agtop.toml"), []byte(`
+[limits]
+daily_budget = 25.00
+monthly_budget = 400
+`), 0644)
+
+	cfg, err := LoadFrom(tmp)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+	if cfg.Limits.DailyBudget != 25 || cfg.Limits.WeeklyBudget != 0 || cfg.Limits.MonthlyBudget != 400 {
+		t.Errorf("unexpected budgets: %+v", cfg.Limits)
+	}
+	if cfg.Limits.MaxCostPerRun != DefaultConfig().Limits.MaxCostPerRun {
+		t.Errorf("expected other limits to keep their defaults, got %+v", cfg.Limits)
+	}
+}
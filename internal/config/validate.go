@@ -3,8 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/justinpbarnett/agtop/internal/condition"
 )
 
 // ValidationError collects multiple validation failures.
@@ -23,10 +27,38 @@ func validate(cfg *Config) error {
 	var errs []string
 
 	// Runtime must be a known value
-	switch cfg.Runtime.Default {
-	case "claude", "opencode":
+	if !knownRuntime(cfg, cfg.Runtime.Default) {
+		errs = append(errs, fmt.Sprintf("runtime.default %q must be %s", cfg.Runtime.Default, runtimeNames))
+	}
+	for name, sc := range cfg.Skills {
+		if sc.Runtime != "" && !knownRuntime(cfg, sc.Runtime) {
+			errs = append(errs, fmt.Sprintf("skills.%s.runtime %q must be %s", name, sc.Runtime, runtimeNames))
+		}
+		if sc.MaxCost < 0 || sc.MaxTurns < 0 {
+			errs = append(errs, fmt.Sprintf("skills.%s limits must be >= 0", name))
+		}
+	}
+
+	switch cfg.Runtime.Codex.Sandbox {
+	case "", "read-only", "workspace-write", "danger-full-access":
 	default:
-		errs = append(errs, fmt.Sprintf("runtime.default %q must be \"claude\" or \"opencode\"", cfg.Runtime.Default))
+		errs = append(errs, fmt.Sprintf("runtime.codex.sandbox %q must be \"read-only\", \"workspace-write\" or \"danger-full-access\"", cfg.Runtime.Codex.Sandbox))
+	}
+	if cfg.Runtime.Replay.Delay < 0 {
+		errs = append(errs, "runtime.replay.delay must be >= 0")
+	}
+	for name, c := range cfg.Runtime.Custom {
+		if builtinRuntime(name) {
+			errs = append(errs, fmt.Sprintf("runtime.custom.%s: name is taken by a built-in runtime", name))
+		}
+		if len(c.Command) == 0 || c.Command[0] == "" {
+			errs = append(errs, fmt.Sprintf("runtime.custom.%s.command must not be empty", name))
+		}
+		switch c.Protocol {
+		case "", "stream-json", "opencode", "codex", "aider", "text":
+		default:
+			errs = append(errs, fmt.Sprintf("runtime.custom.%s.protocol %q must be \"stream-json\", \"opencode\", \"codex\", \"aider\" or \"text\"", name, c.Protocol))
+		}
 	}
 
 	// Permission mode must be a known value
@@ -44,14 +76,46 @@ func validate(cfg *Config) error {
 	}
 
 	// Workflow integrity: every skill referenced must exist in the skills map
+	// and every included workflow must exist
 	for wfName, wf := range cfg.Workflows {
+		if wf.Runtime != "" && !knownRuntime(cfg, wf.Runtime) {
+			errs = append(errs, fmt.Sprintf("workflows.%s.runtime %q must be %s", wfName, wf.Runtime, runtimeNames))
+		}
+		switch wf.Session {
+		case "", SessionFresh, SessionResume, SessionContinue:
+		default:
+			errs = append(errs, fmt.Sprintf("workflows.%s.session %q must be \"fresh\", \"resume\" or \"continue\"", wfName, wf.Session))
+		}
+		if wf.Session != "" && wf.Session != SessionFresh && len(wf.Steps) > 0 {
+			errs = append(errs, fmt.Sprintf("workflows.%s.session cannot be set on a graph workflow; parallel steps cannot share a conversation", wfName))
+		}
 		for _, skillName := range wf.Skills {
+			if ref, ok := WorkflowRef(skillName); ok {
+				inc, exists := cfg.Workflows[ref]
+				if !exists {
+					errs = append(errs, fmt.Sprintf("workflow %q includes undefined workflow %q", wfName, ref))
+				} else if len(inc.Steps) > 0 {
+					errs = append(errs, fmt.Sprintf("workflow %q cannot include graph workflow %q", wfName, ref))
+				}
+				continue
+			}
+			if skillName == ApproveGate {
+				continue
+			}
 			if _, ok := cfg.Skills[skillName]; !ok {
 				errs = append(errs, fmt.Sprintf("workflow %q references undefined skill %q", wfName, skillName))
 			}
 		}
+		if len(wf.Steps) > 0 {
+			if len(wf.Skills) > 0 {
+				errs = append(errs, fmt.Sprintf("workflow %q cannot set both skills and steps", wfName))
+			}
+			errs = append(errs, validateSteps(wfName, wf.Steps, cfg.Skills)...)
+		}
 	}
 
+	errs = append(errs, validateIncludes(cfg.Workflows)...)
+
 	// Positive value checks
 	if cfg.Runtime.Claude.MaxTurns <= 0 {
 		errs = append(errs, "runtime.claude.max_turns must be positive")
@@ -65,9 +129,17 @@ func validate(cfg *Config) error {
 	if cfg.Limits.MaxConcurrentRuns <= 0 {
 		errs = append(errs, "limits.max_concurrent_runs must be positive")
 	}
+	if cfg.Limits.DailyBudget < 0 || cfg.Limits.WeeklyBudget < 0 || cfg.Limits.MonthlyBudget < 0 {
+		errs = append(errs, "limits budgets must be >= 0")
+	}
 	if cfg.Project.DevServer.BasePort <= 0 {
 		errs = append(errs, "project.dev_server.base_port must be positive")
 	}
+	for model, p := range cfg.Pricing {
+		if p.Input < 0 || p.Output < 0 || p.CacheRead < 0 || p.CacheWrite < 0 {
+			errs = append(errs, fmt.Sprintf("pricing.%s rates must be >= 0", model))
+		}
+	}
 
 	// Safety patterns must be valid regex
 	for i, pattern := range cfg.Safety.BlockedPatterns {
@@ -76,6 +148,22 @@ func validate(cfg *Config) error {
 		}
 	}
 
+	// Sandbox must be a known type; docker needs an image with the agent CLIs
+	switch cfg.Safety.Sandbox.Type {
+	case "", "bwrap":
+	case "docker":
+		if cfg.Safety.Sandbox.Image == "" {
+			errs = append(errs, "safety.sandbox.image is required when safety.sandbox.type is \"docker\"")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("safety.sandbox.type %q must be \"bwrap\" or \"docker\"", cfg.Safety.Sandbox.Type))
+	}
+	for i, p := range cfg.Safety.Sandbox.Expose {
+		if !filepath.IsLocal(p) {
+			errs = append(errs, fmt.Sprintf("safety.sandbox.expose[%d] %q must be a path inside $HOME", i, p))
+		}
+	}
+
 	// Repos — validate when configured
 	seenRepoNames := make(map[string]int)
 	seenRepoPaths := make(map[string]int)
@@ -111,17 +199,203 @@ func validate(cfg *Config) error {
 		if j.UserEnv == "" {
 			errs = append(errs, "integrations.jira.user_env must be non-empty when jira is configured")
 		}
-		// Non-fatal warnings: help users catch missing env vars early
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Warnings returns non-fatal problems with the config, such as the env vars
+// of an integration not being set. Load does not print them; commands that
+// use the affected settings do.
+func (c *Config) Warnings() []string {
+	var warnings []string
+	if j := c.Integrations.Jira; j != nil {
 		if j.AuthEnv != "" && os.Getenv(j.AuthEnv) == "" {
-			fmt.Fprintf(os.Stderr, "warning: env var %s (integrations.jira.auth_env) is not set\n", j.AuthEnv)
+			warnings = append(warnings, fmt.Sprintf("env var %s (integrations.jira.auth_env) is not set", j.AuthEnv))
 		}
 		if j.UserEnv != "" && os.Getenv(j.UserEnv) == "" {
-			fmt.Fprintf(os.Stderr, "warning: env var %s (integrations.jira.user_env) is not set\n", j.UserEnv)
+			warnings = append(warnings, fmt.Sprintf("env var %s (integrations.jira.user_env) is not set", j.UserEnv))
 		}
 	}
+	return warnings
+}
 
-	if len(errs) > 0 {
-		return &ValidationError{Errors: errs}
+const runtimeNames = `"claude", "opencode", "codex", "aider", "replay" or a runtime.custom name`
+
+// builtinRuntime reports whether name is one of the runtimes agtop ships.
+func builtinRuntime(name string) bool {
+	switch name {
+	case "claude", "opencode", "codex", "aider", "replay":
+		return true
 	}
-	return nil
+	return false
+}
+
+// knownRuntime reports whether name is a runtime agtop can start.
+func knownRuntime(cfg *Config, name string) bool {
+	_, custom := cfg.Runtime.Custom[name]
+	return builtinRuntime(name) || custom
+}
+
+// validateSteps checks a step graph: every step names a known skill (or is a
+// loop), step names are unique, needs and conditions reference existing steps,
+// loops jump back to an ancestor, and there are no cycles.
+func validateSteps(wfName string, steps []StepConfig, skills map[string]SkillConfig) []string {
+	var errs []string
+
+	byName := make(map[string]StepConfig, len(steps))
+	for i, step := range steps {
+		switch {
+		case step.Loop != "":
+			if step.Skill != "" {
+				errs = append(errs, fmt.Sprintf("workflow %q loop step %q cannot also run a skill", wfName, step.StepName()))
+			}
+			if step.Name == "" {
+				errs = append(errs, fmt.Sprintf("workflow %q loop step %d has no name", wfName, i))
+				continue
+			}
+			if step.MaxLoops < 0 {
+				errs = append(errs, fmt.Sprintf("workflow %q step %q: max_loops must be >= 0", wfName, step.Name))
+			}
+		case step.Skill == "":
+			errs = append(errs, fmt.Sprintf("workflow %q step %d has no skill", wfName, i))
+			continue
+		case step.Skill == ApproveGate:
+		default:
+			if _, ok := skills[step.Skill]; !ok {
+				errs = append(errs, fmt.Sprintf("workflow %q references undefined skill %q", wfName, step.Skill))
+			}
+			if step.Skill == "route" {
+				errs = append(errs, fmt.Sprintf("workflow %q: route cannot be used as a step", wfName))
+			}
+		}
+		name := step.StepName()
+		if _, dup := byName[name]; dup {
+			errs = append(errs, fmt.Sprintf("workflow %q has duplicate step %q", wfName, name))
+			continue
+		}
+		byName[name] = step
+	}
+
+	for _, step := range steps {
+		for _, need := range step.Needs {
+			if _, ok := byName[need]; !ok {
+				errs = append(errs, fmt.Sprintf("workflow %q step %q needs unknown step %q", wfName, step.StepName(), need))
+			}
+		}
+		if step.When != "" {
+			expr, err := condition.Parse(step.When)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("workflow %q step %q: %v", wfName, step.StepName(), err))
+			} else {
+				for _, ref := range expr.Steps() {
+					if _, ok := byName[ref]; !ok {
+						errs = append(errs, fmt.Sprintf("workflow %q step %q condition references unknown step %q", wfName, step.StepName(), ref))
+					} else if !isAncestor(byName, ref, step.StepName()) {
+						errs = append(errs, fmt.Sprintf("workflow %q step %q condition references step %q, which it does not depend on through needs", wfName, step.StepName(), ref))
+					}
+				}
+			}
+		}
+		if step.Loop != "" && step.Name != "" && !isAncestor(byName, step.Loop, step.Name) {
+			errs = append(errs, fmt.Sprintf("workflow %q loop step %q must loop back to a step it depends on, not %q", wfName, step.Name, step.Loop))
+		}
+	}
+
+	// Depth-first search for cycles. 1 = visiting, 2 = done.
+	mark := make(map[string]int, len(byName))
+	var visit func(name string, path []string) bool
+	visit = func(name string, path []string) bool {
+		switch mark[name] {
+		case 1:
+			errs = append(errs, fmt.Sprintf("workflow %q has a dependency cycle: %s", wfName, strings.Join(append(path, name), " -> ")))
+			return false
+		case 2:
+			return true
+		}
+		mark[name] = 1
+		for _, need := range byName[name].Needs {
+			if _, ok := byName[need]; !ok {
+				continue
+			}
+			if !visit(need, append(path, name)) {
+				return false
+			}
+		}
+		mark[name] = 2
+		return true
+	}
+	for _, step := range steps {
+		if !visit(step.StepName(), nil) {
+			break
+		}
+	}
+
+	return errs
+}
+
+// isAncestor reports whether step anc is reachable from step name through needs.
+func isAncestor(byName map[string]StepConfig, anc, name string) bool {
+	seen := make(map[string]bool)
+	stack := append([]string(nil), byName[name].Needs...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == anc {
+			return true
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		stack = append(stack, byName[n].Needs...)
+	}
+	return false
+}
+
+// validateIncludes reports workflows that include themselves, directly or
+// through other workflows.
+func validateIncludes(workflows map[string]WorkflowConfig) []string {
+	var errs []string
+	names := make([]string, 0, len(workflows))
+	for name := range workflows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// 1 = visiting, 2 = done. Each cycle is reported once.
+	mark := make(map[string]int, len(workflows))
+	var visit func(name string, path []string)
+	visit = func(name string, path []string) {
+		switch mark[name] {
+		case 1:
+			cycle := append(path, name)
+			for i, n := range path {
+				if n == name {
+					cycle = cycle[i:]
+					break
+				}
+			}
+			errs = append(errs, fmt.Sprintf("workflow %q includes itself: %s", name, strings.Join(cycle, " -> ")))
+			return
+		case 2:
+			return
+		}
+		mark[name] = 1
+		for _, entry := range workflows[name].Skills {
+			if ref, ok := WorkflowRef(entry); ok {
+				if _, exists := workflows[ref]; exists {
+					visit(ref, append(path, name))
+				}
+			}
+		}
+		mark[name] = 2
+	}
+	for _, name := range names {
+		visit(name, nil)
+	}
+	return errs
 }
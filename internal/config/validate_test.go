@@ -1,7 +1,6 @@
 package config
 
 import (
-	"os"
 	"strings"
 	"testing"
 )
@@ -67,6 +66,24 @@ func TestValidateWorkflowMissingSkill(t *testing.T) {
 	}
 }
 
+func TestValidateWorkflowApproveGate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["gated"] = WorkflowConfig{
+		Skills: []string{"spec", ApproveGate, "build"},
+	}
+	cfg.Workflows["gated-graph"] = WorkflowConfig{
+		Steps: []StepConfig{
+			{Skill: "spec"},
+			{Skill: ApproveGate, Needs: []string{"spec"}},
+			{Skill: "build", Needs: []string{ApproveGate}},
+		},
+	}
+
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("expected approve gate to be valid, got: %v", err)
+	}
+}
+
 func TestValidateBadRegex(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Safety.BlockedPatterns = append(cfg.Safety.BlockedPatterns, "[invalid")
@@ -189,12 +206,7 @@ func TestValidateJiraNilPassesValidation(t *testing.T) {
 	}
 }
 
-func TestValidateJiraEnvVarWarning(t *testing.T) {
-	// Capture stderr to verify warnings are emitted
-	oldStderr := os.Stderr
-	r, w, _ := os.Pipe()
-	os.Stderr = w
-
+func TestJiraEnvVarWarnings(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Integrations.Jira = &JiraConfig{
 		BaseURL:    "https://company.atlassian.net",
@@ -203,23 +215,16 @@ func TestValidateJiraEnvVarWarning(t *testing.T) {
 		UserEnv:    "AGTOP_TEST_JIRA_USER_NONEXISTENT",
 	}
 
-	err := validate(&cfg)
-	w.Close()
-	os.Stderr = oldStderr
-
-	if err != nil {
+	if err := validate(&cfg); err != nil {
 		t.Fatalf("expected valid config to pass, got: %v", err)
 	}
 
-	buf := make([]byte, 4096)
-	n, _ := r.Read(buf)
-	stderr := string(buf[:n])
-
-	if !strings.Contains(stderr, "AGTOP_TEST_JIRA_AUTH_NONEXISTENT") {
-		t.Errorf("expected stderr warning about auth env var, got: %q", stderr)
+	warnings := strings.Join(cfg.Warnings(), "\n")
+	if !strings.Contains(warnings, "AGTOP_TEST_JIRA_AUTH_NONEXISTENT") {
+		t.Errorf("expected a warning about the auth env var, got: %q", warnings)
 	}
-	if !strings.Contains(stderr, "AGTOP_TEST_JIRA_USER_NONEXISTENT") {
-		t.Errorf("expected stderr warning about user env var, got: %q", stderr)
+	if !strings.Contains(warnings, "AGTOP_TEST_JIRA_USER_NONEXISTENT") {
+		t.Errorf("expected a warning about the user env var, got: %q", warnings)
 	}
 }
 
@@ -266,3 +271,366 @@ func TestValidateJiraRequiredFields(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateWorkflowSteps(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["graph"] = WorkflowConfig{
+		Steps: []StepConfig{
+			{Skill: "spec"},
+			{Name: "impl", Skill: "build", Needs: []string{"spec"}},
+			{Skill: "test", Needs: []string{"impl"}},
+			{Skill: "review", Needs: []string{"impl", "test"}},
+		},
+	}
+
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("expected valid step graph, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowStepsUnknownNeed(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["graph"] = WorkflowConfig{
+		Steps: []StepConfig{
+			{Skill: "build", Needs: []string{"spec"}},
+		},
+	}
+
+	err := validate(&cfg)
+	if err == nil {
+		t.Fatal("expected validation error for unknown need")
+	}
+	if !strings.Contains(err.Error(), `needs unknown step "spec"`) {
+		t.Errorf("expected unknown step error, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowStepsCycle(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["graph"] = WorkflowConfig{
+		Steps: []StepConfig{
+			{Skill: "build", Needs: []string{"test"}},
+			{Skill: "test", Needs: []string{"build"}},
+		},
+	}
+
+	err := validate(&cfg)
+	if err == nil {
+		t.Fatal("expected validation error for dependency cycle")
+	}
+	if !strings.Contains(err.Error(), "dependency cycle: build -> test -> build") {
+		t.Errorf("expected cycle error, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowStepsDuplicateName(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["graph"] = WorkflowConfig{
+		Steps: []StepConfig{
+			{Skill: "build"},
+			{Skill: "build"},
+		},
+	}
+
+	err := validate(&cfg)
+	if err == nil {
+		t.Fatal("expected validation error for duplicate step")
+	}
+	if !strings.Contains(err.Error(), `duplicate step "build"`) {
+		t.Errorf("expected duplicate step error, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowSkillsAndSteps(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["graph"] = WorkflowConfig{
+		Skills: []string{"build"},
+		Steps:  []StepConfig{{Skill: "build"}},
+	}
+
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "both skills and steps") {
+		t.Errorf("expected skills/steps conflict error, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowSession(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["resumed"] = WorkflowConfig{Skills: []string{"spec", "build"}, Session: SessionResume}
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("expected valid session, got: %v", err)
+	}
+
+	cfg.Workflows["bad"] = WorkflowConfig{Skills: []string{"build"}, Session: "forever"}
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), `workflows.bad.session "forever"`) {
+		t.Errorf("expected session value error, got: %v", err)
+	}
+
+	delete(cfg.Workflows, "bad")
+	cfg.Workflows["graph"] = WorkflowConfig{Steps: []StepConfig{{Skill: "build"}}, Session: SessionContinue}
+	err = validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "workflows.graph.session cannot be set on a graph workflow") {
+		t.Errorf("expected graph session error, got: %v", err)
+	}
+}
+
+func TestValidateBudgets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Limits.MonthlyBudget = -1
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "limits budgets must be >= 0") {
+		t.Errorf("expected negative budget error, got: %v", err)
+	}
+}
+
+func TestValidatePricing(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Pricing["local"] = PricingConfig{}
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("expected zero rates to be valid, got: %v", err)
+	}
+
+	cfg.Pricing["bad"] = PricingConfig{Input: 3, Output: -15}
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "pricing.bad rates must be >= 0") {
+		t.Errorf("expected negative rate error, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowStepsLoop(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["graph"] = WorkflowConfig{
+		Steps: []StepConfig{
+			{Skill: "build"},
+			{Skill: "review", Needs: []string{"build"}},
+			{Name: "fix", Needs: []string{"review"}, When: "review.success == false", Loop: "build", MaxLoops: 3},
+		},
+	}
+
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("expected valid loop, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowStepsLoopErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []StepConfig
+		want  string
+	}{
+		{
+			"not an ancestor",
+			[]StepConfig{{Skill: "build"}, {Skill: "review"}, {Name: "fix", Needs: []string{"review"}, Loop: "build"}},
+			"must loop back",
+		},
+		{
+			"runs a skill",
+			[]StepConfig{{Skill: "build"}, {Name: "fix", Skill: "review", Needs: []string{"build"}, Loop: "build"}},
+			"cannot also run a skill",
+		},
+		{
+			"unnamed",
+			[]StepConfig{{Skill: "build"}, {Needs: []string{"build"}, Loop: "build"}},
+			"has no name",
+		},
+		{
+			"bad condition",
+			[]StepConfig{{Skill: "build"}, {Skill: "review", Needs: []string{"build"}, When: "build.success = true"}},
+			"unexpected",
+		},
+		{
+			"unknown condition step",
+			[]StepConfig{{Skill: "build"}, {Skill: "review", Needs: []string{"build"}, When: "test.success"}},
+			`references unknown step "test"`,
+		},
+		{
+			"condition on a step it does not need",
+			[]StepConfig{{Skill: "build"}, {Skill: "test"}, {Skill: "review", Needs: []string{"build"}, When: "test.success"}},
+			`references step "test", which it does not depend on`,
+		},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Workflows["graph"] = WorkflowConfig{Steps: tt.steps}
+		err := validate(&cfg)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got: %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestValidateWorkflowIncludes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["plan"] = WorkflowConfig{Skills: []string{"spec", "build"}}
+	cfg.Workflows["ship"] = WorkflowConfig{Skills: []string{"workflow:plan", "review", "commit"}}
+
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("expected valid include, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowIncludeUndefined(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["ship"] = WorkflowConfig{Skills: []string{"workflow:nope", "review"}}
+
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), `includes undefined workflow "nope"`) {
+		t.Errorf("expected undefined include error, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowIncludeCycle(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["a"] = WorkflowConfig{Skills: []string{"build", "workflow:b"}}
+	cfg.Workflows["b"] = WorkflowConfig{Skills: []string{"workflow:c"}}
+	cfg.Workflows["c"] = WorkflowConfig{Skills: []string{"review", "workflow:a"}}
+	cfg.Workflows["self"] = WorkflowConfig{Skills: []string{"workflow:self"}}
+
+	err := validate(&cfg)
+	if err == nil {
+		t.Fatal("expected validation error for include cycle")
+	}
+	if !strings.Contains(err.Error(), "includes itself: a -> b -> c -> a") {
+		t.Errorf("expected a -> b -> c -> a cycle, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "includes itself: self -> self") {
+		t.Errorf("expected self cycle, got: %v", err)
+	}
+}
+
+func TestValidateWorkflowIncludeGraph(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workflows["graph"] = WorkflowConfig{Steps: []StepConfig{{Skill: "build"}}}
+	cfg.Workflows["ship"] = WorkflowConfig{Skills: []string{"workflow:graph"}}
+
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), `cannot include graph workflow "graph"`) {
+		t.Errorf("expected graph include error, got: %v", err)
+	}
+}
+
+func TestValidateRuntimeOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Skills["review"] = SkillConfig{Runtime: "gpt"}
+	cfg.Workflows["build"] = WorkflowConfig{Skills: []string{"build"}, Runtime: "cursor"}
+
+	err := validate(&cfg)
+	if err == nil {
+		t.Fatal("expected validation error for unknown runtimes")
+	}
+	if !strings.Contains(err.Error(), `skills.review.runtime "gpt"`) {
+		t.Errorf("expected error about skills.review.runtime, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), `workflows.build.runtime "cursor"`) {
+		t.Errorf("expected error about workflows.build.runtime, got: %v", err)
+	}
+}
+
+func TestValidateSkillLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Skills["build"] = SkillConfig{MaxCost: 2.5, MaxTurns: 40}
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Skills["build"] = SkillConfig{MaxCost: -1}
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "skills.build limits must be >= 0") {
+		t.Errorf("expected error about skills.build limits, got: %v", err)
+	}
+}
+
+func TestRuntimeOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.RuntimeOverrides(); len(got) != 0 {
+		t.Fatalf("expected no overrides by default, got %v", got)
+	}
+	cfg.Skills["review"] = SkillConfig{Runtime: "opencode"}
+	cfg.Skills["spec"] = SkillConfig{Runtime: "claude"}
+	cfg.Workflows["build"] = WorkflowConfig{Skills: []string{"build"}, Runtime: "opencode"}
+	if got := strings.Join(cfg.RuntimeOverrides(), ","); got != "claude,opencode" {
+		t.Errorf("RuntimeOverrides() = %s, want claude,opencode", got)
+	}
+}
+
+func TestValidateReplayRuntime(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Runtime.Default = "replay"
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("replay runtime should pass validation, got: %v", err)
+	}
+
+	cfg.Runtime.Replay.Delay = -1
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "runtime.replay.delay") {
+		t.Errorf("expected error about runtime.replay.delay, got: %v", err)
+	}
+}
+
+func TestValidateCodexSandbox(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Runtime.Default = "codex"
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("codex runtime should pass validation, got: %v", err)
+	}
+
+	cfg.Runtime.Codex.Sandbox = "yolo"
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "runtime.codex.sandbox") {
+		t.Errorf("expected error about runtime.codex.sandbox, got: %v", err)
+	}
+}
+
+func TestValidateCustomRuntime(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Runtime.Custom = map[string]CustomRuntimeConfig{
+		"wrapper": {Command: []string{"./scripts/agent.sh", "{prompt}"}, Protocol: "stream-json"},
+	}
+	cfg.Runtime.Default = "wrapper"
+	cfg.Skills["review"] = SkillConfig{Runtime: "wrapper"}
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("custom runtime should pass validation, got: %v", err)
+	}
+
+	cfg.Runtime.Custom = map[string]CustomRuntimeConfig{
+		"claude": {Command: []string{"claude"}},
+		"broken": {Protocol: "xml"},
+	}
+	cfg.Runtime.Default = "claude"
+	cfg.Skills["review"] = SkillConfig{}
+	err := validate(&cfg)
+	if err == nil {
+		t.Fatal("expected validation errors for custom runtimes")
+	}
+	for _, want := range []string{
+		"runtime.custom.claude: name is taken",
+		"runtime.custom.broken.command must not be empty",
+		`runtime.custom.broken.protocol "xml"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestValidateSandbox(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Safety.Sandbox.Type = "bwrap"
+	if err := validate(&cfg); err != nil {
+		t.Fatalf("bwrap sandbox should pass validation, got: %v", err)
+	}
+
+	cfg.Safety.Sandbox.Type = "docker"
+	err := validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "safety.sandbox.image is required") {
+		t.Errorf("expected error about safety.sandbox.image, got: %v", err)
+	}
+
+	cfg.Safety.Sandbox.Type = "chroot"
+	cfg.Safety.Sandbox.Expose = []string{"/etc"}
+	err = validate(&cfg)
+	if err == nil || !strings.Contains(err.Error(), `safety.sandbox.type "chroot"`) || !strings.Contains(err.Error(), "safety.sandbox.expose[0]") {
+		t.Errorf("expected errors about safety.sandbox.type and expose, got: %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+// SocketName is the file name of the control socket inside a sessions directory.
+const SocketName = "control.sock"
+
+// JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeServerError    = -32000
+)
+
+// NotifyRunsChanged is the notification method pushed to subscribed clients
+// whenever the run store changes.
+const NotifyRunsChanged = "runs.changed"
+
+// Handler performs the actions exposed over the socket. The TUI implements it
+// so that socket clients go through the same code paths as key bindings.
+type Handler interface {
+	Execute(prompt, workflow, model string) (string, error)
+	Cancel(runID string) error
+	Resume(runID string) error
+	FollowUp(runID, prompt string) error
+	Interject(runID, message string) error
+	Accept(runID string) error
+	Reject(runID string) error
+}
+
+// Request is a JSON-RPC 2.0 request. Requests without an ID are treated as
+// notifications and receive no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is a server-initiated JSON-RPC 2.0 message with no ID.
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// ExecuteParams are the params for the "execute" method.
+type ExecuteParams struct {
+	Prompt   string `json:"prompt"`
+	Workflow string `json:"workflow"`
+	Model    string `json:"model,omitempty"`
+}
+
+// RunParams are the params for methods that act on a single run.
+type RunParams struct {
+	RunID  string `json:"run_id"`
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// RunsChangedParams are the params of a runs.changed notification.
+type RunsChangedParams struct {
+	Runs []run.Run `json:"runs"`
+}
+
+// Server accepts newline-delimited JSON-RPC 2.0 requests on a Unix socket.
+type Server struct {
+	path    string
+	store   *run.Store
+	handler Handler
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[*conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+type conn struct {
+	nc      net.Conn
+	writeMu sync.Mutex
+	changes chan struct{} // nil until the client subscribes
+}
+
+// SocketPath returns the control socket path for a sessions directory.
+func SocketPath(sessionsDir string) string {
+	return filepath.Join(sessionsDir, SocketName)
+}
+
+func NewServer(path string, store *run.Store, handler Handler) *Server {
+	s := &Server{
+		path:    path,
+		store:   store,
+		handler: handler,
+		conns:   make(map[*conn]struct{}),
+	}
+	store.Subscribe(s.broadcast)
+	return s
+}
+
+// Path returns the socket path the server listens on.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Start binds the socket and begins accepting connections in the background.
+// A stale socket left behind by a crashed instance is replaced; a socket with
+// a live listener is reported as an error.
+func (s *Server) Start() error {
+	if _, err := os.Stat(s.path); err == nil {
+		if c, dialErr := net.Dial("unix", s.path); dialErr == nil {
+			c.Close()
+			return fmt.Errorf("control socket %s is in use by another agtop instance", s.path)
+		}
+		if err := os.Remove(s.path); err != nil {
+			return fmt.Errorf("remove stale control socket: %w", err)
+		}
+	}
+
+	// The socket is created owner-only. Chmod after Listen would leave a
+	// window in which another user could connect.
+	mask := syscall.Umask(0o177)
+	ln, err := net.Listen("unix", s.path)
+	syscall.Umask(mask)
+	if err != nil {
+		return fmt.Errorf("listen on control socket: %w", err)
+	}
+
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.acceptLoop(ln)
+	return nil
+}
+
+// Close stops the listener, disconnects all clients and removes the socket file.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	ln := s.listener
+	for c := range s.conns {
+		c.nc.Close()
+	}
+	s.mu.Unlock()
+
+	var err error
+	if ln != nil {
+		err = ln.Close()
+		os.Remove(s.path)
+	}
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) acceptLoop(ln net.Listener) {
+	defer s.wg.Done()
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("warning: control socket accept: %v", err)
+			}
+			return
+		}
+
+		c := &conn{nc: nc}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			nc.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serveConn(c)
+	}
+}
+
+func (s *Server) serveConn(c *conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		if c.changes != nil {
+			close(c.changes)
+		}
+		s.mu.Unlock()
+		c.nc.Close()
+	}()
+
+	scanner := bufio.NewScanner(c.nc)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			c.write(Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: err.Error()}})
+			continue
+		}
+
+		result, rpcErr := s.dispatch(c, req)
+		if len(req.ID) == 0 {
+			continue
+		}
+		resp := Response{JSONRPC: "2.0", ID: req.ID}
+		if rpcErr != nil {
+			resp.Error = rpcErr
+		} else {
+			resp.Result = result
+		}
+		c.write(resp)
+
+		if req.Method == "subscribe" && rpcErr == nil {
+			s.sendRuns(c)
+		}
+	}
+}
+
+func (s *Server) dispatch(c *conn, req Request) (interface{}, *Error) {
+	if req.JSONRPC != "2.0" {
+		return nil, &Error{Code: CodeInvalidRequest, Message: `jsonrpc must be "2.0"`}
+	}
+
+	switch req.Method {
+	case "execute":
+		var p ExecuteParams
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if p.Prompt == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "prompt is required"}
+		}
+		if p.Workflow == "" {
+			p.Workflow = "auto"
+		}
+		id, err := s.handler.Execute(p.Prompt, p.Workflow, p.Model)
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return map[string]string{"run_id": id}, nil
+
+	case "cancel", "resume", "accept", "reject":
+		p, rpcErr := decodeRunParams(req.Params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		var err error
+		switch req.Method {
+		case "cancel":
+			err = s.handler.Cancel(p.RunID)
+		case "resume":
+			err = s.handler.Resume(p.RunID)
+		case "accept":
+			err = s.handler.Accept(p.RunID)
+		case "reject":
+			err = s.handler.Reject(p.RunID)
+		}
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return map[string]bool{"ok": true}, nil
+
+	case "follow_up", "interject":
+		p, rpcErr := decodeRunParams(req.Params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		if p.Prompt == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "prompt is required"}
+		}
+		var err error
+		if req.Method == "follow_up" {
+			err = s.handler.FollowUp(p.RunID, p.Prompt)
+		} else {
+			err = s.handler.Interject(p.RunID, p.Prompt)
+		}
+		if err != nil {
+			return nil, serverError(err)
+		}
+		return map[string]bool{"ok": true}, nil
+
+	case "list":
+		return s.store.List(), nil
+
+	case "get":
+		p, rpcErr := decodeRunParams(req.Params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		r, ok := s.store.Get(p.RunID)
+		if !ok {
+			return nil, &Error{Code: CodeServerError, Message: fmt.Sprintf("run not found: %s", p.RunID)}
+		}
+		return r, nil
+
+	case "subscribe":
+		s.subscribe(c)
+		return map[string]bool{"ok": true}, nil
+
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+// subscribe registers c for runs.changed notifications. Notifications are
+// coalesced: a slow client receives the latest run list rather than a backlog.
+func (s *Server) subscribe(c *conn) {
+	s.mu.Lock()
+	if c.changes != nil {
+		s.mu.Unlock()
+		return
+	}
+	ch := make(chan struct{}, 1)
+	c.changes = ch
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for range ch {
+			if err := s.sendRuns(c); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// broadcast is the store subscriber. It must not block.
+func (s *Server) broadcast() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		if c.changes == nil {
+			continue
+		}
+		select {
+		case c.changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *Server) sendRuns(c *conn) error {
+	return c.write(Notification{
+		JSONRPC: "2.0",
+		Method:  NotifyRunsChanged,
+		Params:  RunsChangedParams{Runs: s.store.List()},
+	})
+}
+
+func (c *conn) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.nc.Write(data)
+	return err
+}
+
+func decodeParams(raw json.RawMessage, v interface{}) *Error {
+	if len(raw) == 0 {
+		return &Error{Code: CodeInvalidParams, Message: "params are required"}
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func decodeRunParams(raw json.RawMessage) (RunParams, *Error) {
+	var p RunParams
+	if err := decodeParams(raw, &p); err != nil {
+		return p, err
+	}
+	if p.RunID == "" {
+		return p, &Error{Code: CodeInvalidParams, Message: "run_id is required"}
+	}
+	return p, nil
+}
+
+func serverError(err error) *Error {
+	return &Error{Code: CodeServerError, Message: err.Error()}
+}
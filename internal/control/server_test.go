@@ -0,0 +1,330 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+type fakeHandler struct {
+	mu    sync.Mutex
+	store *run.Store
+	calls []string
+}
+
+func (h *fakeHandler) record(call string) {
+	h.mu.Lock()
+	h.calls = append(h.calls, call)
+	h.mu.Unlock()
+}
+
+func (h *fakeHandler) Calls() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.calls...)
+}
+
+func (h *fakeHandler) Execute(prompt, workflow, model string) (string, error) {
+	h.record("execute:" + workflow + ":" + prompt)
+	return h.store.Add(&run.Run{Prompt: prompt, Workflow: workflow, State: run.StateQueued}), nil
+}
+
+func (h *fakeHandler) Cancel(runID string) error {
+	h.record("cancel:" + runID)
+	return nil
+}
+
+func (h *fakeHandler) Resume(runID string) error {
+	h.record("resume:" + runID)
+	return nil
+}
+
+func (h *fakeHandler) FollowUp(runID, prompt string) error {
+	h.record("follow_up:" + runID + ":" + prompt)
+	return nil
+}
+
+func (h *fakeHandler) Interject(runID, message string) error {
+	h.record("interject:" + runID + ":" + message)
+	return nil
+}
+
+func (h *fakeHandler) Accept(runID string) error {
+	h.record("accept:" + runID)
+	return errors.New("cannot accept: run is running")
+}
+
+func (h *fakeHandler) Reject(runID string) error {
+	h.record("reject:" + runID)
+	return nil
+}
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func startTestServer(t *testing.T) (*Server, *run.Store, *fakeHandler) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "agtopctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	store := run.NewStore()
+	h := &fakeHandler{store: store}
+	srv := NewServer(SocketPath(dir), store, h)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv, store, h
+}
+
+func dial(t *testing.T, srv *Server) *testClient {
+	t.Helper()
+	c, err := net.Dial("unix", srv.Path())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return &testClient{t: t, conn: c, r: bufio.NewReader(c)}
+}
+
+func (c *testClient) send(raw string) {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(raw + "\n")); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *testClient) read() map[string]json.RawMessage {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		c.t.Fatalf("unmarshal %q: %v", line, err)
+	}
+	return msg
+}
+
+func TestServerExecute(t *testing.T) {
+	srv, store, h := startTestServer(t)
+	c := dial(t, srv)
+
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"execute","params":{"prompt":"fix bug","workflow":"build"}}`)
+	msg := c.read()
+	if string(msg["id"]) != "1" {
+		t.Errorf("id = %s, want 1", msg["id"])
+	}
+	var result map[string]string
+	if err := json.Unmarshal(msg["result"], &result); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if _, ok := store.Get(result["run_id"]); !ok {
+		t.Errorf("run %q not in store", result["run_id"])
+	}
+	calls := h.Calls()
+	if len(calls) != 1 || calls[0] != "execute:build:fix bug" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestServerExecuteDefaultsWorkflow(t *testing.T) {
+	srv, _, h := startTestServer(t)
+	c := dial(t, srv)
+
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"execute","params":{"prompt":"x"}}`)
+	c.read()
+	calls := h.Calls()
+	if len(calls) != 1 || calls[0] != "execute:auto:x" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestServerRunMethods(t *testing.T) {
+	srv, _, h := startTestServer(t)
+	c := dial(t, srv)
+
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"cancel","params":{"run_id":"abc"}}`)
+	c.read()
+	c.send(`{"jsonrpc":"2.0","id":2,"method":"resume","params":{"run_id":"abc"}}`)
+	c.read()
+	c.send(`{"jsonrpc":"2.0","id":3,"method":"follow_up","params":{"run_id":"abc","prompt":"more"}}`)
+	c.read()
+	c.send(`{"jsonrpc":"2.0","id":4,"method":"reject","params":{"run_id":"abc"}}`)
+	c.read()
+	c.send(`{"jsonrpc":"2.0","id":5,"method":"interject","params":{"run_id":"abc","prompt":"stop"}}`)
+	c.read()
+
+	want := []string{"cancel:abc", "resume:abc", "follow_up:abc:more", "reject:abc", "interject:abc:stop"}
+	calls := h.Calls()
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+func TestServerHandlerError(t *testing.T) {
+	srv, _, _ := startTestServer(t)
+	c := dial(t, srv)
+
+	c.send(`{"jsonrpc":"2.0","id":"a","method":"accept","params":{"run_id":"abc"}}`)
+	msg := c.read()
+	var rpcErr Error
+	if err := json.Unmarshal(msg["error"], &rpcErr); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if rpcErr.Code != CodeServerError {
+		t.Errorf("code = %d, want %d", rpcErr.Code, CodeServerError)
+	}
+	if rpcErr.Message != "cannot accept: run is running" {
+		t.Errorf("message = %q", rpcErr.Message)
+	}
+	if _, ok := msg["result"]; ok {
+		t.Error("expected no result on error")
+	}
+}
+
+func TestServerProtocolErrors(t *testing.T) {
+	srv, _, _ := startTestServer(t)
+	c := dial(t, srv)
+
+	tests := []struct {
+		req  string
+		code int
+	}{
+		{`not json`, CodeParseError},
+		{`{"jsonrpc":"1.0","id":1,"method":"list"}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":1,"method":"nope"}`, CodeMethodNotFound},
+		{`{"jsonrpc":"2.0","id":1,"method":"cancel","params":{}}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"execute","params":{"workflow":"build"}}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"get","params":{"run_id":"missing"}}`, CodeServerError},
+	}
+	for _, tt := range tests {
+		c.send(tt.req)
+		msg := c.read()
+		var rpcErr Error
+		if err := json.Unmarshal(msg["error"], &rpcErr); err != nil {
+			t.Fatalf("%s: error: %v", tt.req, err)
+		}
+		if rpcErr.Code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.req, rpcErr.Code, tt.code)
+		}
+	}
+}
+
+func TestServerNotificationGetsNoResponse(t *testing.T) {
+	srv, _, h := startTestServer(t)
+	c := dial(t, srv)
+
+	c.send(`{"jsonrpc":"2.0","method":"cancel","params":{"run_id":"abc"}}`)
+	c.send(`{"jsonrpc":"2.0","id":7,"method":"list"}`)
+	msg := c.read()
+	if string(msg["id"]) != "7" {
+		t.Errorf("first response id = %s, want 7", msg["id"])
+	}
+	calls := h.Calls()
+	if len(calls) != 1 || calls[0] != "cancel:abc" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestServerSubscribe(t *testing.T) {
+	srv, store, _ := startTestServer(t)
+	c := dial(t, srv)
+
+	id := store.Add(&run.Run{Prompt: "p", State: run.StateRunning})
+
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"subscribe"}`)
+	if msg := c.read(); string(msg["id"]) != "1" {
+		t.Fatalf("expected subscribe response, got %v", msg)
+	}
+	// Initial snapshot
+	msg := c.read()
+	if string(msg["method"]) != `"`+NotifyRunsChanged+`"` {
+		t.Fatalf("method = %s, want %s", msg["method"], NotifyRunsChanged)
+	}
+
+	store.Update(id, func(r *run.Run) { r.State = run.StateCompleted })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		msg := c.read()
+		var params RunsChangedParams
+		if err := json.Unmarshal(msg["params"], &params); err != nil {
+			t.Fatalf("params: %v", err)
+		}
+		if len(params.Runs) == 1 && params.Runs[0].State == run.StateCompleted {
+			return
+		}
+	}
+	t.Fatal("did not receive completed state notification")
+}
+
+func TestServerStartReplacesStaleSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "agtopctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, SocketName)
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer(path, run.NewStore(), &fakeHandler{})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start with stale socket: %v", err)
+	}
+	defer srv.Close()
+
+	second := NewServer(path, run.NewStore(), &fakeHandler{})
+	if err := second.Start(); err == nil {
+		second.Close()
+		t.Fatal("expected error when socket is in use")
+	}
+}
+
+func TestServerCloseRemovesSocket(t *testing.T) {
+	srv, _, _ := startTestServer(t)
+	dial(t, srv)
+
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(srv.Path()); !os.IsNotExist(err) {
+		t.Errorf("socket still exists after Close: %v", err)
+	}
+}
+
+func TestServerSocketIsOwnerOnly(t *testing.T) {
+	srv, _, _ := startTestServer(t)
+
+	info, err := os.Stat(srv.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+}
@@ -0,0 +1,83 @@
+package cost
+
+import "fmt"
+
+// BudgetWarnRatio is the share of a budget spent at which it is shown as
+// running low and new runs are refused, leaving the rest to runs already
+// going.
+const BudgetWarnRatio = 0.8
+
+// Budget caps the spend of all runs per calendar day, week and month. A
+// zero cap is disabled.
+type Budget struct {
+	Daily   float64
+	Weekly  float64
+	Monthly float64
+}
+
+// Enabled reports whether any cap is set.
+func (b Budget) Enabled() bool {
+	return b.Daily > 0 || b.Weekly > 0 || b.Monthly > 0
+}
+
+// BudgetStatus is the spend against one budget cap.
+type BudgetStatus struct {
+	Period string // "daily", "weekly" or "monthly"
+	Spent  float64
+	Limit  float64
+}
+
+// Remaining returns what is left of the cap, never below zero.
+func (s BudgetStatus) Remaining() float64 {
+	if s.Spent >= s.Limit {
+		return 0
+	}
+	return s.Limit - s.Spent
+}
+
+// Ratio returns the share of the cap spent.
+func (s BudgetStatus) Ratio() float64 {
+	return s.Spent / s.Limit
+}
+
+// Tightest returns the status of the cap with the largest share spent. It
+// reports false when no cap is set.
+func (b Budget) Tightest(s Spend) (BudgetStatus, bool) {
+	var best BudgetStatus
+	var ok bool
+	for _, st := range []BudgetStatus{
+		{Period: "daily", Spent: s.Day, Limit: b.Daily},
+		{Period: "weekly", Spent: s.Week, Limit: b.Weekly},
+		{Period: "monthly", Spent: s.Month, Limit: b.Monthly},
+	} {
+		if st.Limit <= 0 {
+			continue
+		}
+		if !ok || st.Ratio() > best.Ratio() {
+			best, ok = st, true
+		}
+	}
+	return best, ok
+}
+
+// Check returns whether spend has reached a cap, and which.
+func (b Budget) Check(s Spend) (exceeded bool, reason string) {
+	st, ok := b.Tightest(s)
+	if !ok || st.Spent < st.Limit {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s budget reached ($%.2f >= $%.2f)", st.Period, st.Spent, st.Limit)
+}
+
+// CheckNew returns whether a new run should be refused: spend has reached
+// BudgetWarnRatio of a cap, so the run would likely take it over.
+func (b Budget) CheckNew(s Spend) (refused bool, reason string) {
+	if exceeded, reason := b.Check(s); exceeded {
+		return true, reason
+	}
+	st, ok := b.Tightest(s)
+	if !ok || st.Ratio() < BudgetWarnRatio {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s budget nearly reached ($%.2f of $%.2f)", st.Period, st.Spent, st.Limit)
+}
@@ -0,0 +1,48 @@
+package cost
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudgetCheck(t *testing.T) {
+	b := Budget{Daily: 10, Monthly: 100}
+
+	if exceeded, _ := b.Check(Spend{Day: 9, Week: 50, Month: 90}); exceeded {
+		t.Error("expected spend under both caps to pass")
+	}
+	exceeded, reason := b.Check(Spend{Day: 4, Week: 80, Month: 100})
+	if !exceeded || !strings.HasPrefix(reason, "monthly budget reached") {
+		t.Errorf("expected the monthly cap to be reached, got %v %q", exceeded, reason)
+	}
+	if exceeded, _ := (Budget{}).Check(Spend{Day: 1000}); exceeded {
+		t.Error("expected no caps to never be exceeded")
+	}
+}
+
+func TestBudgetCheckNew(t *testing.T) {
+	b := Budget{Daily: 10, Monthly: 100}
+
+	if refused, _ := b.CheckNew(Spend{Day: 7, Month: 70}); refused {
+		t.Error("expected spend well under both caps to allow new runs")
+	}
+	refused, reason := b.CheckNew(Spend{Day: 8.5, Month: 20})
+	if !refused || !strings.HasPrefix(reason, "daily budget nearly reached") {
+		t.Errorf("expected new runs refused near the daily cap, got %v %q", refused, reason)
+	}
+	refused, reason = b.CheckNew(Spend{Day: 4, Month: 100})
+	if !refused || !strings.HasPrefix(reason, "monthly budget reached") {
+		t.Errorf("expected new runs refused at the monthly cap, got %v %q", refused, reason)
+	}
+}
+
+func TestBudgetTightest(t *testing.T) {
+	b := Budget{Daily: 10, Weekly: 40, Monthly: 100}
+	st, ok := b.Tightest(Spend{Day: 5, Week: 36, Month: 60})
+	if !ok || st.Period != "weekly" || st.Remaining() != 4 {
+		t.Errorf("expected the weekly cap with $4 left, got %+v", st)
+	}
+	if _, ok := (Budget{}).Tightest(Spend{}); ok {
+		t.Error("expected no status without caps")
+	}
+}
@@ -0,0 +1,121 @@
+package cost
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Step is a skill a run has yet to execute, with the model it will run on.
+type Step struct {
+	Skill string
+	Model string
+}
+
+// Forecast estimates what a run's remaining skills will cost.
+type Forecast struct {
+	P50     float64 // median total
+	P90     float64 // 90th-percentile total
+	Steps   int     // steps estimated from history
+	Unknown int     // steps without history, left out of the totals
+}
+
+// String formats the forecast: "≈ $3.20 (p50), $7.80 (p90)".
+func (f Forecast) String() string {
+	return f.format("")
+}
+
+// Remaining formats the forecast as the cost still to come for a run in
+// flight: "≈ $3.20 more (p50), $7.80 (p90)".
+func (f Forecast) Remaining() string {
+	return f.format(" more")
+}
+
+func (f Forecast) format(more string) string {
+	s := fmt.Sprintf("≈ $%.2f%s (p50), $%.2f (p90)", f.P50, more, f.P90)
+	if f.Unknown > 0 {
+		noun := "skills"
+		if f.Unknown == 1 {
+			noun = "skill"
+		}
+		s += fmt.Sprintf(", %d %s without history", f.Unknown, noun)
+	}
+	return s
+}
+
+type costSample struct {
+	model string
+	cost  float64
+}
+
+// History holds the costs of past skill executions by skill name. It is
+// safe for concurrent use.
+type History struct {
+	mu      sync.RWMutex
+	samples map[string][]costSample
+}
+
+func NewHistory() *History {
+	return &History{samples: make(map[string][]costSample)}
+}
+
+// Add records finished skill executions. Entries without a cost are
+// skipped: they come from runtimes that report none and would pull the
+// estimates toward zero.
+func (h *History) Add(scs ...SkillCost) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sc := range scs {
+		if sc.SkillName == "" || sc.CostUSD <= 0 {
+			continue
+		}
+		h.samples[sc.SkillName] = append(h.samples[sc.SkillName], costSample{model: sc.Model, cost: sc.CostUSD})
+	}
+}
+
+// Forecast estimates the cost of steps. Each step is estimated from past
+// executions of its skill on the same model, or on any model when there are
+// none. The totals add up the steps' percentiles, so P90 errs on the high
+// side. ok is false when no step has history.
+func (h *History) Forecast(steps []Step) (f Forecast, ok bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, step := range steps {
+		costs := h.costs(step)
+		if len(costs) == 0 {
+			f.Unknown++
+			continue
+		}
+		sort.Float64s(costs)
+		f.P50 += percentile(costs, 0.5)
+		f.P90 += percentile(costs, 0.9)
+		f.Steps++
+	}
+	return f, f.Steps > 0
+}
+
+func (h *History) costs(step Step) []float64 {
+	samples := h.samples[step.Skill]
+	var same, all []float64
+	for _, s := range samples {
+		all = append(all, s.cost)
+		if step.Model != "" && strings.EqualFold(s.model, step.Model) {
+			same = append(same, s.cost)
+		}
+	}
+	if len(same) > 0 {
+		return same
+	}
+	return all
+}
+
+// percentile returns the nearest-rank p-quantile of sorted.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
@@ -0,0 +1,85 @@
+package cost
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestHistoryForecast(t *testing.T) {
+	h := NewHistory()
+	for _, c := range []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10} {
+		h.Add(SkillCost{SkillName: "build", Model: "sonnet", CostUSD: c})
+	}
+	h.Add(
+		SkillCost{SkillName: "spec", Model: "opus", CostUSD: 2},
+		SkillCost{SkillName: "spec", Model: "opus", CostUSD: 4},
+		SkillCost{SkillName: "spec", Model: "opus", CostUSD: 0}, // no cost reported
+	)
+
+	f, ok := h.Forecast([]Step{{Skill: "spec", Model: "opus"}, {Skill: "build", Model: "sonnet"}, {Skill: "review"}})
+	if !ok {
+		t.Fatal("expected a forecast")
+	}
+	// spec: p50 2, p90 4; build: p50 5, p90 9.
+	if !near(f.P50, 7) || !near(f.P90, 13) {
+		t.Errorf("forecast = p50 %v p90 %v, want 7 and 13", f.P50, f.P90)
+	}
+	if f.Steps != 2 || f.Unknown != 1 {
+		t.Errorf("steps = %d unknown = %d, want 2 and 1", f.Steps, f.Unknown)
+	}
+}
+
+func TestHistoryForecastPrefersSameModel(t *testing.T) {
+	h := NewHistory()
+	h.Add(
+		SkillCost{SkillName: "build", Model: "opus", CostUSD: 10},
+		SkillCost{SkillName: "build", Model: "sonnet", CostUSD: 2},
+	)
+
+	f, _ := h.Forecast([]Step{{Skill: "build", Model: "Sonnet"}})
+	if !near(f.P50, 2) {
+		t.Errorf("same-model p50 = %v, want 2", f.P50)
+	}
+	// No history on haiku: every model's executions count.
+	f, _ = h.Forecast([]Step{{Skill: "build", Model: "haiku"}})
+	if !near(f.P50, 2) || !near(f.P90, 10) {
+		t.Errorf("any-model forecast = p50 %v p90 %v, want 2 and 10", f.P50, f.P90)
+	}
+}
+
+func TestHistoryForecastWithoutHistory(t *testing.T) {
+	h := NewHistory()
+	if _, ok := h.Forecast([]Step{{Skill: "build"}}); ok {
+		t.Error("expected no forecast without history")
+	}
+	if _, ok := h.Forecast(nil); ok {
+		t.Error("expected no forecast without steps")
+	}
+}
+
+func TestForecastFormat(t *testing.T) {
+	f := Forecast{P50: 3.2, P90: 7.8, Steps: 2}
+	if got, want := f.Remaining(), "≈ $3.20 more (p50), $7.80 (p90)"; got != want {
+		t.Errorf("Remaining() = %q, want %q", got, want)
+	}
+	f.Unknown = 1
+	if got, want := f.String(), "≈ $3.20 (p50), $7.80 (p90), 1 skill without history"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestTrackerForecastKeepsRemovedRuns(t *testing.T) {
+	tr := NewTracker()
+	tr.Record("run-1", SkillCost{SkillName: "build", CostUSD: 3})
+	tr.AddHistory(SkillCost{SkillName: "build", CostUSD: 1})
+	tr.Remove("run-1")
+
+	f, ok := tr.Forecast([]Step{{Skill: "build"}})
+	if !ok || !near(f.P50, 1) || !near(f.P90, 3) {
+		t.Errorf("forecast = %+v ok=%v, want p50 1 and p90 3", f, ok)
+	}
+}
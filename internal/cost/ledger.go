@@ -0,0 +1,184 @@
+package cost
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ledgerRefresh is how long a Ledger serves spend from memory before
+// reading the files again to pick up other agtop instances.
+const ledgerRefresh = 10 * time.Second
+
+// LedgerEntry is one recorded cost in a spend ledger.
+type LedgerEntry struct {
+	Time    time.Time `json:"time"`
+	RunID   string    `json:"run_id"`
+	CostUSD float64   `json:"cost_usd"`
+}
+
+// Spend is the money spent in the current calendar day, week (from
+// Monday) and month, in local time.
+type Spend struct {
+	Day   float64
+	Week  float64
+	Month float64
+}
+
+// Ledger records spend across runs, sessions and projects. Entries are
+// appended to one file per month in dir, which every agtop instance on the
+// machine shares. It is safe for concurrent use.
+type Ledger struct {
+	dir string
+
+	mu     sync.Mutex
+	spend  Spend
+	readAt time.Time // when spend was last read from disk; zero forces a read
+}
+
+// NewLedger returns a ledger stored in dir. The directory is created on
+// the first record.
+func NewLedger(dir string) *Ledger {
+	return &Ledger{dir: dir}
+}
+
+// LedgerDir returns the default ledger directory, ~/.agtop/spend.
+func LedgerDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home dir: %w", err)
+	}
+	return filepath.Join(home, ".agtop", "spend"), nil
+}
+
+func (l *Ledger) monthPath(t time.Time) string {
+	return filepath.Join(l.dir, t.Format("2006-01")+".jsonl")
+}
+
+// Record appends a cost spent by runID at at.
+func (l *Ledger) Record(runID string, costUSD float64, at time.Time) error {
+	if costUSD <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(LedgerEntry{Time: at, RunID: runID, CostUSD: costUSD})
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return fmt.Errorf("create ledger dir: %w", err)
+	}
+	f, err := os.OpenFile(l.monthPath(at), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if !l.readAt.IsZero() {
+		l.spend = l.spend.add(at, costUSD, at)
+	}
+	return nil
+}
+
+// Spend returns the money spent as of now, including what other agtop
+// instances recorded up to a few seconds ago.
+func (l *Ledger) Spend(now time.Time) (Spend, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Spend read on another day counts toward the wrong periods.
+	if !l.readAt.IsZero() && now.Sub(l.readAt) < ledgerRefresh && sameDay(l.readAt, now) {
+		return l.spend, nil
+	}
+
+	paths := []string{l.monthPath(now)}
+	if week := startOfWeek(now); week.Month() != now.Month() {
+		// The week started in the previous month.
+		paths = append(paths, l.monthPath(week))
+	}
+	var s Spend
+	for _, path := range paths {
+		entries, err := readLedgerFile(path)
+		if err != nil {
+			return s, err
+		}
+		for _, e := range entries {
+			s = s.add(e.Time, e.CostUSD, now)
+		}
+	}
+	l.spend, l.readAt = s, now
+	return s, nil
+}
+
+// add returns s with a cost spent at at counted in the periods of now it
+// falls in.
+func (s Spend) add(at time.Time, costUSD float64, now time.Time) Spend {
+	if at.After(now) {
+		return s
+	}
+	if !at.Before(startOfDay(now)) {
+		s.Day += costUSD
+	}
+	if !at.Before(startOfWeek(now)) {
+		s.Week += costUSD
+	}
+	if !at.Before(startOfMonth(now)) {
+		s.Month += costUSD
+	}
+	return s
+}
+
+func readLedgerFile(path string) ([]LedgerEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []LedgerEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e LedgerEntry
+		// A line cut short by a crash is skipped.
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	// time.Weekday starts on Sunday; weeks here start on Monday.
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
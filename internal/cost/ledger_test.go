@@ -0,0 +1,97 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLedgerSpendPeriods(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLedger(dir)
+	// Wednesday 2026-04-15, local time.
+	now := time.Date(2026, 4, 15, 12, 0, 0, 0, time.Local)
+
+	records := []struct {
+		at   time.Time
+		cost float64
+	}{
+		{now.Add(-time.Hour), 1},                            // today
+		{time.Date(2026, 4, 13, 9, 0, 0, 0, time.Local), 2}, // Monday, this week
+		{time.Date(2026, 4, 12, 9, 0, 0, 0, time.Local), 4}, // Sunday, last week
+		{time.Date(2026, 3, 31, 9, 0, 0, 0, time.Local), 8}, // last month
+	}
+	for _, r := range records {
+		if err := l.Record("001", r.cost, r.at); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	s, err := NewLedger(dir).Spend(now)
+	if err != nil {
+		t.Fatalf("spend: %v", err)
+	}
+	if s.Day != 1 || s.Week != 3 || s.Month != 7 {
+		t.Errorf("expected day 1, week 3, month 7, got %+v", s)
+	}
+}
+
+func TestLedgerWeekSpansMonths(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLedger(dir)
+	// Thursday 2026-10-01; the week started on Monday 2026-09-28.
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	l.Record("001", 2, time.Date(2026, 9, 29, 9, 0, 0, 0, time.Local))
+	l.Record("001", 1, now.Add(-time.Hour))
+
+	s, err := NewLedger(dir).Spend(now)
+	if err != nil {
+		t.Fatalf("spend: %v", err)
+	}
+	if s.Day != 1 || s.Week != 3 || s.Month != 1 {
+		t.Errorf("expected day 1, week 3, month 1, got %+v", s)
+	}
+}
+
+func TestLedgerSharedBetweenInstances(t *testing.T) {
+	dir := t.TempDir()
+	a, b := NewLedger(dir), NewLedger(dir)
+	now := time.Now()
+
+	if _, err := a.Spend(now); err != nil {
+		t.Fatal(err)
+	}
+	a.Record("001", 1.5, now)
+	b.Record("002", 2.5, now)
+
+	// a counts its own record at once and b's after the refresh interval.
+	if s, _ := a.Spend(now); s.Day != 1.5 {
+		t.Errorf("expected own spend of 1.5 before refreshing, got %+v", s)
+	}
+	if s, _ := a.Spend(now.Add(ledgerRefresh)); s.Day != 4 {
+		t.Errorf("expected shared spend of 4 after refreshing, got %+v", s)
+	}
+}
+
+func TestLedgerSkipsTruncatedLine(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	l := NewLedger(dir)
+	l.Record("001", 1, now)
+
+	f, err := os.OpenFile(filepath.Join(dir, now.Format("2006-01")+".jsonl"), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"`)
+	f.Close()
+
+	s, err := NewLedger(dir).Spend(now)
+	if err != nil {
+		t.Fatalf("spend: %v", err)
+	}
+	if s.Month != 1 {
+		t.Errorf("expected month spend 1, got %+v", s)
+	}
+}
@@ -5,11 +5,12 @@ import (
 	"strings"
 )
 
-// LimitChecker enforces per-run token and cost thresholds
-// and detects rate limit errors.
+// LimitChecker enforces per-run token and cost thresholds and budgets
+// across runs, and detects rate limit errors.
 type LimitChecker struct {
 	MaxTokensPerRun int
 	MaxCostPerRun   float64
+	Budget          Budget
 }
 
 // CheckRun returns whether a run has exceeded its configured thresholds.
@@ -24,6 +25,64 @@ func (lc *LimitChecker) CheckRun(runTokens int, runCost float64) (exceeded bool,
 	return false, ""
 }
 
+// CheckForecast returns whether a forecast reaches the per-run cost
+// threshold, judged by its median first and then its 90th percentile.
+func (lc *LimitChecker) CheckForecast(f Forecast) (exceeded bool, reason string) {
+	if lc.MaxCostPerRun <= 0 {
+		return false, ""
+	}
+	if f.P50 >= lc.MaxCostPerRun {
+		return true, fmt.Sprintf("forecast cost exceeds threshold ($%.2f p50 >= $%.2f)", f.P50, lc.MaxCostPerRun)
+	}
+	if f.P90 >= lc.MaxCostPerRun {
+		return true, fmt.Sprintf("forecast cost may exceed threshold ($%.2f p90 >= $%.2f)", f.P90, lc.MaxCostPerRun)
+	}
+	return false, ""
+}
+
+// Grace a skill gets past its limit to wrap up before it is stopped.
+const (
+	SkillGraceCost  = 0.25 // share of MaxCost
+	SkillGraceTurns = 3
+)
+
+// SkillLimit caps the cost and turns of one skill execution. A zero field
+// is disabled.
+type SkillLimit struct {
+	MaxCost  float64
+	MaxTurns int
+}
+
+// Enabled reports whether any cap is set.
+func (l SkillLimit) Enabled() bool {
+	return l.MaxCost > 0 || l.MaxTurns > 0
+}
+
+// WithGrace returns the limit raised by the grace allowance, at which a
+// skill that was asked to wrap up is stopped.
+func (l SkillLimit) WithGrace() SkillLimit {
+	g := l
+	if g.MaxCost > 0 {
+		g.MaxCost *= 1 + SkillGraceCost
+	}
+	if g.MaxTurns > 0 {
+		g.MaxTurns += SkillGraceTurns
+	}
+	return g
+}
+
+// Check returns whether a skill's cost or turns so far have reached the
+// limit.
+func (l SkillLimit) Check(cost float64, turns int) (exceeded bool, reason string) {
+	if l.MaxCost > 0 && cost >= l.MaxCost {
+		return true, fmt.Sprintf("skill cost limit reached ($%.2f >= $%.2f)", cost, l.MaxCost)
+	}
+	if l.MaxTurns > 0 && turns >= l.MaxTurns {
+		return true, fmt.Sprintf("skill turn limit reached (%d >= %d)", turns, l.MaxTurns)
+	}
+	return false, ""
+}
+
 // IsRateLimit returns true if the error text indicates an API rate limit.
 func (lc *LimitChecker) IsRateLimit(errorText string) bool {
 	lower := strings.ToLower(errorText)
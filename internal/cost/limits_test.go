@@ -1,6 +1,9 @@
 package cost
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestCheckRunCostExceeded(t *testing.T) {
 	lc := &LimitChecker{MaxCostPerRun: 5.00}
@@ -88,3 +91,42 @@ func TestIsRateLimitMatches(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckForecast(t *testing.T) {
+	lc := &LimitChecker{MaxCostPerRun: 5.00}
+	if exceeded, _ := lc.CheckForecast(Forecast{P50: 2, P90: 4}); exceeded {
+		t.Error("expected forecast under threshold to pass")
+	}
+	exceeded, reason := lc.CheckForecast(Forecast{P50: 2, P90: 7.8})
+	if !exceeded || !strings.Contains(reason, "p90") {
+		t.Errorf("p90 over threshold: exceeded=%v reason=%q", exceeded, reason)
+	}
+	exceeded, reason = lc.CheckForecast(Forecast{P50: 6, P90: 9})
+	if !exceeded || !strings.Contains(reason, "p50") {
+		t.Errorf("p50 over threshold: exceeded=%v reason=%q", exceeded, reason)
+	}
+	if exceeded, _ := (&LimitChecker{}).CheckForecast(Forecast{P50: 100, P90: 100}); exceeded {
+		t.Error("expected zero threshold to disable the check")
+	}
+}
+
+func TestSkillLimitCheck(t *testing.T) {
+	l := SkillLimit{MaxCost: 2, MaxTurns: 10}
+	if exceeded, _ := l.Check(1.5, 9); exceeded {
+		t.Error("expected usage under the limit to pass")
+	}
+	if exceeded, reason := l.Check(2, 0); !exceeded || !strings.Contains(reason, "cost") {
+		t.Errorf("cost at limit: exceeded=%v reason=%q", exceeded, reason)
+	}
+	if exceeded, reason := l.Check(0, 10); !exceeded || !strings.Contains(reason, "turn") {
+		t.Errorf("turns at limit: exceeded=%v reason=%q", exceeded, reason)
+	}
+
+	g := l.WithGrace()
+	if g.MaxCost != 2.5 || g.MaxTurns != 13 {
+		t.Errorf("WithGrace() = %+v, want cost 2.5 and 13 turns", g)
+	}
+	if (SkillLimit{}).Enabled() || (SkillLimit{}).WithGrace().Enabled() {
+		t.Error("expected a zero limit to stay disabled")
+	}
+}
@@ -0,0 +1,58 @@
+package cost
+
+import "strings"
+
+// Rates are a model's token prices in USD per million tokens. Zero cache
+// rates fall back to the input rate.
+type Rates struct {
+	Input      float64
+	Output     float64
+	CacheRead  float64
+	CacheWrite float64
+}
+
+// Cost returns the price of a skill's token usage. Input tokens exclude
+// the cached prompt tokens, which are priced separately.
+func (r Rates) Cost(input, output, cacheRead, cacheWrite int) float64 {
+	cacheReadRate, cacheWriteRate := r.CacheRead, r.CacheWrite
+	if cacheReadRate == 0 {
+		cacheReadRate = r.Input
+	}
+	if cacheWriteRate == 0 {
+		cacheWriteRate = r.Input
+	}
+	return (float64(input)*r.Input +
+		float64(output)*r.Output +
+		float64(cacheRead)*cacheReadRate +
+		float64(cacheWrite)*cacheWriteRate) / 1e6
+}
+
+// Pricing maps model names to their rates.
+type Pricing map[string]Rates
+
+// Lookup returns the rates of model. An exact match wins, ignoring case and
+// a provider prefix such as "anthropic/". Otherwise the longest name that
+// model contains is used, so "sonnet" prices "claude-sonnet-4-5-20250929".
+func (p Pricing) Lookup(model string) (Rates, bool) {
+	if model == "" {
+		return Rates{}, false
+	}
+	name := strings.ToLower(model)
+	bare := name
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		bare = name[i+1:]
+	}
+
+	var best string
+	var rates Rates
+	for key, r := range p {
+		k := strings.ToLower(key)
+		if k == name || k == bare {
+			return r, true
+		}
+		if k != "" && strings.Contains(bare, k) && len(k) > len(best) {
+			best, rates = k, r
+		}
+	}
+	return rates, best != ""
+}
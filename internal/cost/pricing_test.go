@@ -0,0 +1,53 @@
+package cost
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRatesCost(t *testing.T) {
+	r := Rates{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	got := r.Cost(1000, 2000, 10000, 4000)
+	want := (1000*3 + 2000*15 + 10000*0.3 + 4000*3.75) / 1e6
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("expected %f, got %f", want, got)
+	}
+}
+
+func TestRatesCostCacheFallsBackToInput(t *testing.T) {
+	r := Rates{Input: 2, Output: 8}
+	if got, want := r.Cost(0, 0, 500000, 500000), 2.0; math.Abs(got-want) > 1e-12 {
+		t.Errorf("expected %f, got %f", want, got)
+	}
+}
+
+func TestPricingLookup(t *testing.T) {
+	p := Pricing{
+		"sonnet":          {Input: 3},
+		"opus":            {Input: 5},
+		"claude-opus-4":   {Input: 15},
+		"claude-opus-4-5": {Input: 5},
+		"My-Model":        {Input: 1},
+	}
+	cases := []struct {
+		model string
+		want  float64
+		ok    bool
+	}{
+		{"sonnet", 3, true},
+		{"claude-sonnet-4-5-20250929", 3, true},
+		{"anthropic/claude-sonnet-4-6", 3, true},
+		{"claude-opus-4-1-20250805", 15, true},
+		{"claude-opus-4-5-20251101", 5, true},
+		{"opus", 5, true},
+		{"my-model", 1, true},
+		{"gpt-5-codex", 0, false},
+		{"", 0, false},
+	}
+	for _, tc := range cases {
+		r, ok := p.Lookup(tc.model)
+		if ok != tc.ok || r.Input != tc.want {
+			t.Errorf("Lookup(%q) = %v, %v; want input %v, %v", tc.model, r, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -7,36 +7,64 @@ import (
 
 // SkillCost records the token usage and cost for a single skill execution.
 type SkillCost struct {
-	SkillName    string    `json:"skill_name"`
-	InputTokens  int       `json:"input_tokens"`
-	OutputTokens int       `json:"output_tokens"`
-	TotalTokens  int       `json:"total_tokens"`
-	CostUSD      float64   `json:"cost_usd"`
-	StartedAt    time.Time `json:"started_at"`
-	CompletedAt  time.Time `json:"completed_at"`
+	SkillName        string    `json:"skill_name"`
+	Model            string    `json:"model,omitempty"`
+	InputTokens      int       `json:"input_tokens"`
+	OutputTokens     int       `json:"output_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	CacheReadTokens  int       `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int       `json:"cache_write_tokens,omitempty"`
+	CostUSD          float64   `json:"cost_usd"`
+	CostSource       string    `json:"cost_source,omitempty"` // CostSourceRuntime or CostSourcePricing; empty when unknown
+	PricedUSD        float64   `json:"priced_usd,omitempty"`  // cost by the pricing table, when it knows the model
+	Partial          bool      `json:"partial,omitempty"`     // stopped at the skill's cost or turn limit
+	StartedAt        time.Time `json:"started_at"`
+	CompletedAt      time.Time `json:"completed_at"`
 }
 
+// Where a SkillCost's CostUSD came from.
+const (
+	CostSourceRuntime = "runtime" // reported by the runtime
+	CostSourcePricing = "pricing" // computed from the pricing table
+)
+
 // Tracker maintains per-run skill cost ledgers and session-wide aggregates.
+// Every recorded entry also joins the history that forecasts are made
+// from, which outlives the removal of its run.
 type Tracker struct {
 	mu            sync.RWMutex
 	runs          map[string][]SkillCost
 	sessionTokens int
 	sessionCost   float64
+	history       *History
 }
 
 func NewTracker() *Tracker {
 	return &Tracker{
-		runs: make(map[string][]SkillCost),
+		runs:    make(map[string][]SkillCost),
+		history: NewHistory(),
 	}
 }
 
 // Record appends a skill cost entry and updates session totals.
 func (t *Tracker) Record(runID string, sc SkillCost) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 	t.runs[runID] = append(t.runs[runID], sc)
 	t.sessionTokens += sc.TotalTokens
 	t.sessionCost += sc.CostUSD
+	t.mu.Unlock()
+	t.history.Add(sc)
+}
+
+// AddHistory adds skill cost entries of runs that are not tracked, such as
+// archived ones, to the forecast history.
+func (t *Tracker) AddHistory(scs ...SkillCost) {
+	t.history.Add(scs...)
+}
+
+// Forecast estimates the cost of a run's remaining steps from the history.
+func (t *Tracker) Forecast(steps []Step) (Forecast, bool) {
+	return t.history.Forecast(steps)
 }
 
 // RunCosts returns the per-skill cost ledger for a run.
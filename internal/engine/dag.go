@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+// Graph is a workflow whose steps declare dependencies on each other.
+// Steps are stored in topological order; ties keep their config order.
+type Graph struct {
+	Steps []config.StepConfig
+	index map[string]int
+}
+
+// NewGraph builds a graph from workflow steps. Returns an error for
+// duplicate step names, unknown needs, or dependency cycles.
+func NewGraph(steps []config.StepConfig) (*Graph, error) {
+	byName := make(map[string]config.StepConfig, len(steps))
+	for _, s := range steps {
+		name := s.StepName()
+		if _, dup := byName[name]; dup {
+			return nil, fmt.Errorf("duplicate step %q", name)
+		}
+		byName[name] = s
+	}
+
+	// Kahn's algorithm, always taking the earliest ready step in config order.
+	indegree := make(map[string]int, len(steps))
+	for _, s := range steps {
+		for _, need := range s.Needs {
+			if _, ok := byName[need]; !ok {
+				return nil, fmt.Errorf("step %q needs unknown step %q", s.StepName(), need)
+			}
+		}
+		indegree[s.StepName()] = len(s.Needs)
+	}
+
+	g := &Graph{index: make(map[string]int, len(steps))}
+	placed := make(map[string]bool, len(steps))
+	for len(g.Steps) < len(steps) {
+		progressed := false
+		for _, s := range steps {
+			name := s.StepName()
+			if placed[name] || indegree[name] > 0 {
+				continue
+			}
+			placed[name] = true
+			g.index[name] = len(g.Steps)
+			g.Steps = append(g.Steps, s)
+			for _, other := range steps {
+				for _, need := range other.Needs {
+					if need == name {
+						indegree[other.StepName()]--
+					}
+				}
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, fmt.Errorf("dependency cycle among steps")
+		}
+	}
+	return g, nil
+}
+
+// Skills returns the skill of every step in topological order. Loop steps
+// run no skill and are left out.
+func (g *Graph) Skills() []string {
+	skills := make([]string, 0, len(g.Steps))
+	for _, s := range g.Steps {
+		if s.Skill != "" {
+			skills = append(skills, s.Skill)
+		}
+	}
+	return skills
+}
+
+// Step returns the step with the given name.
+func (g *Graph) Step(name string) (config.StepConfig, bool) {
+	i, ok := g.index[name]
+	if !ok {
+		return config.StepConfig{}, false
+	}
+	return g.Steps[i], true
+}
+
+// Ready returns the pending steps whose needs have all completed.
+func (g *Graph) Ready(states map[string]run.NodeState) []config.StepConfig {
+	var ready []config.StepConfig
+	for _, s := range g.Steps {
+		if states[s.StepName()] != run.NodePending {
+			continue
+		}
+		ok := true
+		for _, need := range s.Needs {
+			if states[need] != run.NodeCompleted {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			ready = append(ready, s)
+		}
+	}
+	return ready
+}
+
+// Descendants returns every step that transitively needs the named step.
+func (g *Graph) Descendants(name string) []string {
+	start, ok := g.index[name]
+	if !ok {
+		return nil
+	}
+	seen := map[string]bool{name: true}
+	var out []string
+	// Steps are topologically sorted, so one forward pass reaches every descendant.
+	for _, s := range g.Steps[start+1:] {
+		for _, need := range s.Needs {
+			if seen[need] {
+				seen[s.StepName()] = true
+				out = append(out, s.StepName())
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+func diamondSteps() []config.StepConfig {
+	return []config.StepConfig{
+		{Skill: "review", Needs: []string{"build", "docs"}},
+		{Skill: "build", Needs: []string{"spec"}},
+		{Name: "docs", Skill: "document", Needs: []string{"spec"}},
+		{Skill: "spec"},
+	}
+}
+
+func TestNewGraphTopologicalOrder(t *testing.T) {
+	g, err := NewGraph(diamondSteps())
+	if err != nil {
+		t.Fatalf("NewGraph: %v", err)
+	}
+	want := []string{"spec", "build", "document", "review"}
+	got := g.Skills()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Skills() = %v, want %v", got, want)
+	}
+	if s, ok := g.Step("docs"); !ok || s.Skill != "document" {
+		t.Errorf("Step(docs) = %+v, %v", s, ok)
+	}
+}
+
+func TestNewGraphErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []config.StepConfig
+		want  string
+	}{
+		{"cycle", []config.StepConfig{{Skill: "a", Needs: []string{"b"}}, {Skill: "b", Needs: []string{"a"}}}, "cycle"},
+		{"unknown", []config.StepConfig{{Skill: "a", Needs: []string{"x"}}}, "unknown step"},
+		{"duplicate", []config.StepConfig{{Skill: "a"}, {Skill: "a"}}, "duplicate"},
+	}
+	for _, tt := range tests {
+		_, err := NewGraph(tt.steps)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestGraphReady(t *testing.T) {
+	g, _ := NewGraph(diamondSteps())
+	states := map[string]run.NodeState{
+		"spec":   run.NodeCompleted,
+		"build":  run.NodePending,
+		"docs":   run.NodeRunning,
+		"review": run.NodePending,
+	}
+	ready := g.Ready(states)
+	if len(ready) != 1 || ready[0].StepName() != "build" {
+		t.Errorf("Ready() = %v, want [build]", ready)
+	}
+
+	states["build"] = run.NodeCompleted
+	if ready := g.Ready(states); len(ready) != 0 {
+		t.Errorf("review should wait for docs, got %v", ready)
+	}
+	states["docs"] = run.NodeCompleted
+	if ready := g.Ready(states); len(ready) != 1 || ready[0].StepName() != "review" {
+		t.Errorf("Ready() = %v, want [review]", ready)
+	}
+}
+
+func TestGraphDescendants(t *testing.T) {
+	g, _ := NewGraph(diamondSteps())
+	if got := g.Descendants("spec"); strings.Join(got, ",") != "build,docs,review" {
+		t.Errorf("Descendants(spec) = %v", got)
+	}
+	if got := g.Descendants("docs"); strings.Join(got, ",") != "review" {
+		t.Errorf("Descendants(docs) = %v", got)
+	}
+	if got := g.Descendants("review"); len(got) != 0 {
+		t.Errorf("Descendants(review) = %v, want none", got)
+	}
+}
+
+func TestResolveWorkflowGraph(t *testing.T) {
+	cfg := executorTestConfig()
+	cfg.Workflows["graph"] = config.WorkflowConfig{Steps: diamondSteps()}
+
+	skills, err := ResolveWorkflow(cfg, "graph")
+	if err != nil {
+		t.Fatalf("ResolveWorkflow: %v", err)
+	}
+	if len(skills) != 4 || skills[0] != "spec" {
+		t.Errorf("ResolveWorkflow(graph) = %v", skills)
+	}
+	if !IsGraphWorkflow(cfg, "graph") || IsGraphWorkflow(cfg, "build") {
+		t.Error("IsGraphWorkflow misreports workflow kind")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/justinpbarnett/agtop/internal/condition"
 	"github.com/justinpbarnett/agtop/internal/config"
 	"github.com/justinpbarnett/agtop/internal/cost"
 	"github.com/justinpbarnett/agtop/internal/jira"
@@ -28,6 +29,8 @@ type Executor struct {
 	jiraExpander *jira.Expander
 	mu           sync.Mutex
 	active       map[string]context.CancelFunc
+	gates        map[string]chan bool
+	done         map[string]chan struct{} // closed when a run's worker exits
 	wg           sync.WaitGroup
 	shuttingDown bool
 }
@@ -46,6 +49,8 @@ func NewExecutor(store *run.Store, manager *process.Manager, registry *Registry,
 		cfg:      cfg,
 		limiter:  &cost.LimitChecker{},
 		active:   make(map[string]context.CancelFunc),
+		gates:    make(map[string]chan bool),
+		done:     make(map[string]chan struct{}),
 	}
 }
 
@@ -62,9 +67,11 @@ func (e *Executor) resolveSkills(workflow string) ([]string, error) {
 // that calls fn(ctx), and removes the cancel registration when fn returns.
 func (e *Executor) spawnWorker(runID string, fn func(context.Context)) {
 	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
 
 	e.mu.Lock()
 	e.active[runID] = cancel
+	e.done[runID] = done
 	e.mu.Unlock()
 
 	e.wg.Add(1)
@@ -73,12 +80,31 @@ func (e *Executor) spawnWorker(runID string, fn func(context.Context)) {
 		defer func() {
 			e.mu.Lock()
 			delete(e.active, runID)
+			delete(e.gates, runID)
+			if e.done[runID] == done {
+				delete(e.done, runID)
+			}
 			e.mu.Unlock()
+			close(done)
 		}()
 		fn(ctx)
 	}()
 }
 
+// Done returns a channel that is closed once the run's worker has exited,
+// including every step it still had running. It is already closed when the
+// run has no worker.
+func (e *Executor) Done(runID string) <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ch, ok := e.done[runID]; ok {
+		return ch
+	}
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
 // expandJIRA expands a JIRA issue key in the prompt. If the prompt contains a
 // recognized key and expansion succeeds, the run's Prompt and TaskID are updated
 // in the store and the expanded prompt is returned. On error or no match, the
@@ -123,6 +149,27 @@ func (e *Executor) Execute(runID string, workflowName string, userPrompt string)
 		return
 	}
 
+	if IsGraphWorkflow(e.cfg, workflowName) {
+		g, err := ResolveGraph(e.cfg, workflowName)
+		if err != nil {
+			e.store.Update(runID, func(r *run.Run) {
+				r.State = run.StateFailed
+				r.Error = err.Error()
+				r.CompletedAt = time.Now()
+			})
+			return
+		}
+		e.store.Update(runID, func(r *run.Run) {
+			r.Workflow = workflowName
+			r.State = run.StateRunning
+			r.StartedAt = time.Now()
+		})
+		e.spawnWorker(runID, func(ctx context.Context) {
+			e.executeGraph(ctx, runID, g, userPrompt)
+		})
+		return
+	}
+
 	skills, err := e.resolveSkills(workflowName)
 	if err != nil {
 		e.store.Update(runID, func(r *run.Run) {
@@ -218,6 +265,18 @@ func (e *Executor) Resume(runID string, userPrompt string) error {
 		return nil
 	}
 
+	// Graph workflows re-run every step that has not completed.
+	if IsGraphWorkflow(e.cfg, r.Workflow) {
+		g, err := ResolveGraph(e.cfg, r.Workflow)
+		if err != nil {
+			return err
+		}
+		e.spawnWorker(runID, func(ctx context.Context) {
+			e.executeGraph(ctx, runID, g, userPrompt)
+		})
+		return nil
+	}
+
 	skills, err := e.resolveSkills(r.Workflow)
 	if err != nil {
 		return err
@@ -256,6 +315,17 @@ func (e *Executor) ResumeReconnected(runID string, userPrompt string) {
 		return
 	}
 
+	if IsGraphWorkflow(e.cfg, r.Workflow) {
+		g, err := ResolveGraph(e.cfg, r.Workflow)
+		if err != nil {
+			return
+		}
+		e.spawnWorker(runID, func(ctx context.Context) {
+			e.executeGraph(ctx, runID, g, userPrompt)
+		})
+		return
+	}
+
 	skills, err := e.resolveSkills(r.Workflow)
 	if err != nil {
 		return
@@ -276,6 +346,141 @@ func (e *Executor) ResumeReconnected(runID string, userPrompt string) {
 	})
 }
 
+// ApproveGate lets a run waiting at an approve gate continue. A gated run
+// with no worker (e.g. one started headlessly, or from before a restart)
+// resumes from the step after the gate.
+func (e *Executor) ApproveGate(runID string, userPrompt string) error {
+	r, ok := e.store.Get(runID)
+	if !ok {
+		return fmt.Errorf("run not found: %s", runID)
+	}
+	if r.State != run.StateAwaitingApproval {
+		return fmt.Errorf("run %s is %s, not awaiting approval", runID, r.State)
+	}
+
+	if e.IsActive(runID) {
+		e.signalGate(runID, true)
+		return nil
+	}
+
+	e.store.Update(runID, func(r *run.Run) {
+		r.State = run.StateRunning
+		r.Error = ""
+	})
+
+	if IsGraphWorkflow(e.cfg, r.Workflow) {
+		g, err := ResolveGraph(e.cfg, r.Workflow)
+		if err != nil {
+			return err
+		}
+		e.store.Update(runID, func(r *run.Run) {
+			nodes := make([]run.NodeStatus, len(r.Nodes))
+			copy(nodes, r.Nodes)
+			outputs := make(map[string]string, len(nodes))
+			for _, n := range nodes {
+				if n.State == run.NodeCompleted {
+					outputs[n.Name] = n.Output
+				}
+			}
+			for i := range nodes {
+				if nodes[i].Skill == config.ApproveGate && nodes[i].State == run.NodeRunning {
+					nodes[i].State = run.NodeCompleted
+					nodes[i].Output = needsOutput(nodes[i].Needs, outputs)
+					nodes[i].CompletedAt = time.Now()
+				}
+			}
+			r.Nodes = nodes
+		})
+		e.spawnWorker(runID, func(ctx context.Context) {
+			e.executeGraph(ctx, runID, g, userPrompt)
+		})
+		return nil
+	}
+
+	skills, err := e.resolveSkills(r.Workflow)
+	if err != nil {
+		return err
+	}
+	// SkillIndex is the gate's 1-based position, which is the 0-based
+	// position of the skill after it.
+	startIdx := r.SkillIndex
+	if startIdx > len(skills) {
+		startIdx = len(skills)
+	}
+	e.spawnWorker(runID, func(ctx context.Context) {
+		e.executeWorkflow(ctx, runID, skills[startIdx:], userPrompt, startIdx)
+	})
+	return nil
+}
+
+// RejectGate stops a run waiting at an approve gate and marks it rejected.
+func (e *Executor) RejectGate(runID string) error {
+	r, ok := e.store.Get(runID)
+	if !ok {
+		return fmt.Errorf("run not found: %s", runID)
+	}
+	if r.State != run.StateAwaitingApproval {
+		return fmt.Errorf("run %s is %s, not awaiting approval", runID, r.State)
+	}
+
+	if e.IsActive(runID) {
+		e.signalGate(runID, false)
+		return nil
+	}
+	e.markRejected(runID)
+	return nil
+}
+
+// waitForApproval parks the run at an approve gate until ApproveGate or
+// RejectGate is called. Returns false if the run was rejected.
+func (e *Executor) waitForApproval(ctx context.Context, runID string) (bool, error) {
+	ch := e.gate(runID)
+	e.store.Update(runID, func(r *run.Run) {
+		r.State = run.StateAwaitingApproval
+	})
+	e.logToBuffer(runID, config.ApproveGate, "Waiting for approval (a: approve, x: reject)")
+
+	select {
+	case approved := <-ch:
+		if approved {
+			e.logToBuffer(runID, config.ApproveGate, "Approved")
+			e.store.Update(runID, func(r *run.Run) {
+				r.State = run.StateRunning
+			})
+		}
+		return approved, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (e *Executor) gate(runID string) chan bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ch, ok := e.gates[runID]
+	if !ok {
+		ch = make(chan bool, 1)
+		e.gates[runID] = ch
+	}
+	return ch
+}
+
+func (e *Executor) signalGate(runID string, approved bool) {
+	select {
+	case e.gate(runID) <- approved:
+	default:
+	}
+}
+
+func (e *Executor) markRejected(runID string) {
+	e.logToBuffer(runID, config.ApproveGate, "Rejected")
+	e.store.Update(runID, func(r *run.Run) {
+		r.State = run.StateRejected
+		r.CurrentSkill = ""
+		r.CompletedAt = time.Now()
+	})
+}
+
 // FollowUp sends a follow-up prompt to a completed run, reusing its worktree.
 func (e *Executor) FollowUp(runID, followUpPrompt string) error {
 	r, ok := e.store.Get(runID)
@@ -301,7 +506,8 @@ func (e *Executor) FollowUp(runID, followUpPrompt string) error {
 }
 
 func (e *Executor) executeFollowUp(ctx context.Context, runID string, followUpPrompt string) {
-	skill, opts, ok := e.registry.SkillForRun("build")
+	r, _ := e.store.Get(runID)
+	skill, opts, ok := e.registry.SkillForWorkflow(r.Workflow, "build")
 	if !ok {
 		e.store.Update(runID, func(r *run.Run) {
 			r.State = run.StateFailed
@@ -310,10 +516,15 @@ func (e *Executor) executeFollowUp(ctx context.Context, runID string, followUpPr
 		})
 		return
 	}
-
-	r, _ := e.store.Get(runID)
 	opts.WorkDir = r.Worktree
 
+	// A follow-up that carries on the run's conversation already has its
+	// history; the summary is only for a fresh agent.
+	followUpContext := buildFollowUpContext(r)
+	if e.continueSession(r, &opts) {
+		followUpContext = ""
+	}
+
 	e.store.Update(runID, func(r *run.Run) {
 		r.SkillIndex = 1
 		r.SkillTotal = 1
@@ -337,7 +548,7 @@ func (e *Executor) executeFollowUp(ctx context.Context, runID string, followUpPr
 		SafetyPatterns: e.cfg.Safety.BlockedPatterns,
 		SpecFile:       r.SpecFile,
 		ModifiedFiles:  modifiedFiles,
-		PreviousOutput: buildFollowUpContext(r),
+		PreviousOutput: followUpContext,
 	})
 
 	_, err := e.runSkill(ctx, runID, prompt, opts, skill.Timeout)
@@ -363,6 +574,34 @@ func (e *Executor) executeFollowUp(ctx context.Context, runID string, followUpPr
 	})
 }
 
+// continueSession points opts at the run's earlier agent conversation when
+// the run's workflow sets session = "resume" or "continue", and reports
+// whether it did. Only claude can carry on a conversation, and there is
+// none to carry on until a skill has reported its session ID.
+func (e *Executor) continueSession(r run.Run, opts *runtime.RunOptions) bool {
+	if r.SessionID == "" {
+		return false
+	}
+	rtName := opts.Runtime
+	if rtName == "" {
+		rtName = e.cfg.Runtime.Default
+	}
+	if rtName != "" && rtName != "claude" {
+		return false
+	}
+	switch e.cfg.Workflows[r.Workflow].Session {
+	case config.SessionResume:
+		opts.Resume = r.SessionID
+		e.logToBuffer(r.ID, opts.Skill, fmt.Sprintf("Resuming session %s", r.SessionID))
+	case config.SessionContinue:
+		opts.Continue = true
+		e.logToBuffer(r.ID, opts.Skill, "Continuing the previous session")
+	default:
+		return false
+	}
+	return true
+}
+
 // executeQuickFix sends the user prompt directly to the model without skill
 // wrapping, then commits. Used for trivial changes where the build skill's
 // spec-parsing and plan-following overhead isn't needed.
@@ -436,6 +675,12 @@ func (e *Executor) executeWorkflow(ctx context.Context, runID string, skills []s
 	var specFile string
 	var modifiedFiles []string
 
+	// A resumed run keeps the spec and the output written before it stopped.
+	if r, ok := e.store.Get(runID); ok {
+		specFile = r.SpecFile
+		previousOutput = r.LastOutput
+	}
+
 	for i := 0; i < len(skills); i++ {
 		skillName := skills[i]
 
@@ -467,8 +712,31 @@ func (e *Executor) executeWorkflow(ctx context.Context, runID string, skills []s
 			r.State = run.StateRunning
 		})
 
+		// Approve gate: wait for a person before running later skills.
+		// previousOutput and specFile carry over to the next skill.
+		if skillName == config.ApproveGate {
+			approved, err := e.waitForApproval(ctx, runID)
+			if err != nil {
+				if e.isShuttingDown() {
+					return
+				}
+				e.store.Update(runID, func(r *run.Run) {
+					r.State = run.StateFailed
+					r.Error = "cancelled"
+					r.CompletedAt = time.Now()
+				})
+				return
+			}
+			if !approved {
+				e.markRejected(runID)
+				return
+			}
+			continue
+		}
+
 		// Get skill and options
-		skill, opts, ok := e.registry.SkillForRun(skillName)
+		r, _ := e.store.Get(runID)
+		skill, opts, ok := e.registry.SkillForWorkflow(r.Workflow, skillName)
 		if !ok {
 			e.store.Update(runID, func(r *run.Run) {
 				r.State = run.StateFailed
@@ -479,8 +747,8 @@ func (e *Executor) executeWorkflow(ctx context.Context, runID string, skills []s
 		}
 
 		// Set worktree from run
-		r, _ := e.store.Get(runID)
 		opts.WorkDir = r.Worktree
+		e.continueSession(r, &opts)
 
 		// Build prompt
 		pctx := PromptContext{
@@ -492,6 +760,7 @@ func (e *Executor) executeWorkflow(ctx context.Context, runID string, skills []s
 			SpecFile:       specFile,
 			ModifiedFiles:  modifiedFiles,
 			Repos:          r.Worktrees,
+			Outputs:        r.Outputs,
 		}
 		if skillName == "route" {
 			pctx.WorkflowNames = workflowNames(e.cfg)
@@ -514,15 +783,27 @@ func (e *Executor) executeWorkflow(ctx context.Context, runID string, skills []s
 		}
 
 		previousOutput = result.ResultText
+		e.store.Update(runID, func(r *run.Run) {
+			r.LastOutput = previousOutput
+		})
 
 		// Auto-commit after modifying skills
 		if !isNonModifyingSkill(skillName) && skillName != "commit" {
 			e.commitAfterStep(ctx, runID, skillName)
 		}
 
+		if err := e.recordOutputs(runID, skillName, skill, previousOutput); err != nil {
+			e.store.Update(runID, func(r *run.Run) {
+				r.State = run.StateFailed
+				r.Error = err.Error()
+				r.CompletedAt = time.Now()
+			})
+			return
+		}
+
 		// Populate structured handoff context for downstream skills.
 		if skillName == "spec" {
-			specFile = parseSpecFilePath(previousOutput)
+			specFile = e.specPath(runID, skillName, previousOutput)
 			e.store.Update(runID, func(r *run.Run) {
 				r.SpecFile = specFile
 			})
@@ -560,6 +841,23 @@ func (e *Executor) executeWorkflow(ctx context.Context, runID string, skills []s
 				}
 			}
 
+			if IsGraphWorkflow(e.cfg, resolvedWorkflow) {
+				g, err := ResolveGraph(e.cfg, resolvedWorkflow)
+				if err != nil {
+					e.store.Update(runID, func(r *run.Run) {
+						r.State = run.StateFailed
+						r.Error = err.Error()
+						r.CompletedAt = time.Now()
+					})
+					return
+				}
+				e.store.Update(runID, func(r *run.Run) {
+					r.Workflow = resolvedWorkflow
+				})
+				e.executeGraph(ctx, runID, g, userPrompt)
+				return
+			}
+
 			skills = newSkills
 			i = -1 // will be incremented to 0 by the for loop
 			startOffset = 0
@@ -599,6 +897,525 @@ func (e *Executor) executeWorkflow(ctx context.Context, runID string, skills []s
 	})
 }
 
+// nodeResult is the outcome of one graph step, sent back to the scheduler.
+type nodeResult struct {
+	name   string
+	skill  string
+	output string
+	err    error
+	onMain bool
+}
+
+// executeGraph runs a workflow declared with steps. Each step starts as soon
+// as every step it needs has completed, so independent branches run
+// concurrently. A failed step skips its descendants while the rest of the
+// graph keeps going. Steps whose when condition is false are skipped the same
+// way, and loop steps send the graph back to an earlier step. Steps that
+// completed before a pause or restart are kept.
+func (e *Executor) executeGraph(ctx context.Context, runID string, g *Graph, userPrompt string) {
+	r, _ := e.store.Get(runID)
+	prev := make(map[string]run.NodeStatus, len(r.Nodes))
+	for _, n := range r.Nodes {
+		prev[n.Name] = n
+	}
+
+	states := make(map[string]run.NodeState, len(g.Steps))
+	nodes := make([]run.NodeStatus, len(g.Steps))
+	started := 0
+	for i, step := range g.Steps {
+		n := run.NodeStatus{Name: step.StepName(), Skill: step.Skill, Needs: step.Needs, State: run.NodePending}
+		if step.Loop != "" {
+			n.Loop = step.Loop
+			n.MaxLoops = maxLoops(step)
+		}
+		if p, ok := prev[n.Name]; ok {
+			// Loop counts survive a resume so the bound still holds.
+			n.Loops = p.Loops
+			if p.State == run.NodeCompleted && p.Skill == step.Skill {
+				n = p
+				if step.Skill != "" {
+					started++
+				}
+			}
+		}
+		nodes[i] = n
+		states[n.Name] = n.State
+	}
+	e.store.Update(runID, func(r *run.Run) {
+		r.Nodes = nodes
+		// Loop steps run no skill of their own, so they are not counted.
+		r.SkillTotal = len(g.Skills())
+		r.SkillIndex = started
+		r.State = run.StateRunning
+	})
+
+	// Buffered so workers never block while the scheduler waits on a pause.
+	results := make(chan nodeResult, len(g.Steps))
+	// Steps completed before a pause, restart or approve gate hand their
+	// saved output to the steps and conditions that need it.
+	outputs := make(map[string]string, len(g.Steps))
+	for _, n := range nodes {
+		if n.State == run.NodeCompleted && n.Output != "" {
+			outputs[n.Name] = n.Output
+		}
+	}
+	// loopInput carries a loop step's findings to the step it jumped back to.
+	loopInput := make(map[string]string)
+	specFile := r.SpecFile
+	workflow := r.Workflow
+	var modifiedFiles []string
+	var firstErr string
+	running := 0
+	mainBusy := false
+	cancelled := false
+	rejected := false
+	disconnected := false
+
+	lookup := func(step string) interface{} {
+		return condition.DecodeOutput(outputs[step])
+	}
+
+	for {
+		if !cancelled {
+			select {
+			case <-ctx.Done():
+				cancelled = true
+			default:
+			}
+		}
+
+		if !cancelled && len(g.Ready(states)) > 0 && !e.waitIfPaused(ctx, runID) {
+			cancelled = true
+		}
+
+		// Skipping a step or taking a loop can make other steps ready without
+		// anything running, so keep scheduling until nothing changes.
+		for progressed := !cancelled; progressed; {
+			progressed = false
+			for _, step := range g.Ready(states) {
+				name := step.StepName()
+
+				if step.When != "" {
+					expr, err := condition.Parse(step.When)
+					if err != nil {
+						msg := fmt.Sprintf("step %s: %v", name, err)
+						if firstErr == "" {
+							firstErr = msg
+						}
+						e.failNode(runID, g, states, name, msg)
+						progressed = true
+						continue
+					}
+					if !expr.Eval(lookup) {
+						e.logToBuffer(runID, name, fmt.Sprintf("skipped: %s is false", step.When))
+						e.skipNode(runID, g, states, name, fmt.Sprintf("condition not met: %s", step.When))
+						progressed = true
+						continue
+					}
+				}
+
+				if step.Loop != "" {
+					// Wait for any step the loop would reset to finish first.
+					if e.loopBlocked(g, states, step) {
+						continue
+					}
+					e.takeLoop(runID, g, states, outputs, loopInput, step)
+					progressed = true
+					// The loop reset part of the graph; recompute what is ready.
+					break
+				}
+
+				if step.Skill == config.ApproveGate {
+					// Gates hold back only their descendants; independent
+					// steps keep running while the run waits for sign-off.
+					running++
+					progressed = true
+					states[name] = run.NodeRunning
+					e.updateNode(runID, name, func(n *run.NodeStatus) {
+						n.State = run.NodeRunning
+						n.Error = ""
+						n.StartedAt = time.Now()
+						n.CompletedAt = time.Time{}
+					})
+					e.store.Update(runID, func(r *run.Run) {
+						r.SkillIndex++
+						r.CurrentSkill = runningNodes(r.Nodes)
+					})
+					// The gate passes its input on to the steps after it.
+					input := needsOutput(step.Needs, outputs)
+					go func(name string) {
+						approved, err := e.waitForApproval(ctx, runID)
+						if err == nil && !approved {
+							err = errGateRejected
+						}
+						results <- nodeResult{name: name, skill: config.ApproveGate, output: input, err: err}
+					}(name)
+					continue
+				}
+
+				skill, opts, ok := e.registry.SkillForWorkflow(workflow, step.Skill)
+				if !ok {
+					msg := fmt.Sprintf("skill not found: %s", step.Skill)
+					if firstErr == "" {
+						firstErr = msg
+					}
+					e.failNode(runID, g, states, name, msg)
+					progressed = true
+					continue
+				}
+
+				previousOutput := needsOutput(step.Needs, outputs)
+				if in, ok := loopInput[name]; ok {
+					previousOutput = in
+					delete(loopInput, name)
+				}
+
+				r, _ := e.store.Get(runID)
+				opts.WorkDir = r.Worktree
+				prompt := BuildPrompt(skill, PromptContext{
+					WorkDir:        r.Worktree,
+					Branch:         r.Branch,
+					PreviousOutput: previousOutput,
+					UserPrompt:     userPrompt,
+					SafetyPatterns: e.cfg.Safety.BlockedPatterns,
+					SpecFile:       specFile,
+					ModifiedFiles:  modifiedFiles,
+					Repos:          r.Worktrees,
+					Outputs:        r.Outputs,
+				})
+
+				// The first concurrent step runs under the run's own ID so its
+				// output lands in the main log; others get a composite ID.
+				onMain := !mainBusy
+				if onMain {
+					mainBusy = true
+				}
+				running++
+				progressed = true
+				states[name] = run.NodeRunning
+				e.updateNode(runID, name, func(n *run.NodeStatus) {
+					n.State = run.NodeRunning
+					n.Error = ""
+					n.StartedAt = time.Now()
+					n.CompletedAt = time.Time{}
+				})
+				e.store.Update(runID, func(r *run.Run) {
+					r.SkillIndex++
+					r.CurrentSkill = runningNodes(r.Nodes)
+				})
+
+				go func(name, skillName string, opts runtime.RunOptions, timeout int) {
+					var result process.SkillResult
+					var err error
+					if onMain {
+						result, err = e.runSkill(ctx, runID, prompt, opts, timeout)
+					} else {
+						result, err = e.runParallelSkill(ctx, fmt.Sprintf("%s:%s", runID, name), runID, skillName, prompt, opts, timeout)
+					}
+					results <- nodeResult{name: name, skill: skillName, output: result.ResultText, err: err, onMain: onMain}
+				}(name, step.Skill, opts, skill.Timeout)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		if res.onMain {
+			mainBusy = false
+		}
+
+		if res.err != nil {
+			if errors.Is(res.err, process.ErrDisconnected) || e.isShuttingDown() {
+				disconnected = true
+				cancelled = true
+				continue
+			}
+			if cancelled {
+				continue
+			}
+			if errors.Is(res.err, errGateRejected) {
+				// Stop the steps still running; nothing after a rejected
+				// gate should land in the worktree.
+				rejected = true
+				cancelled = true
+				e.failNode(runID, g, states, res.name, res.err.Error())
+				e.Cancel(runID)
+				continue
+			}
+			msg := fmt.Sprintf("skill %s failed: %v", res.name, res.err)
+			if firstErr == "" {
+				firstErr = msg
+			}
+			e.failNode(runID, g, states, res.name, res.err.Error())
+			continue
+		}
+
+		output := res.output
+		if !isNonModifyingSkill(res.skill) && res.skill != "commit" {
+			e.commitAfterStep(ctx, runID, res.skill)
+		}
+		if skill, ok := e.registry.Get(res.skill); ok {
+			if err := e.recordOutputs(runID, res.name, skill, output); err != nil {
+				if firstErr == "" {
+					firstErr = err.Error()
+				}
+				e.failNode(runID, g, states, res.name, err.Error())
+				continue
+			}
+		}
+		if res.skill == "spec" {
+			specFile = e.specPath(runID, res.name, output)
+			e.store.Update(runID, func(r *run.Run) {
+				r.SpecFile = specFile
+			})
+		}
+		if r, ok := e.store.Get(runID); ok {
+			if out, err := exec.CommandContext(ctx, "git", "-C", r.Worktree, "diff", "--name-only", "HEAD~1").Output(); err == nil {
+				modifiedFiles = nil
+				for _, l := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+					if l != "" {
+						modifiedFiles = append(modifiedFiles, l)
+					}
+				}
+			}
+		}
+		if res.skill == "decompose" {
+			decomposed, err := ParseDecomposeResult(output)
+			if err == nil && len(decomposed.Tasks) > 0 {
+				merged, err := e.executeParallelGroups(ctx, runID, decomposed.GroupByParallel(), userPrompt, output)
+				if err != nil {
+					msg := fmt.Sprintf("parallel execution failed: %v", err)
+					if firstErr == "" {
+						firstErr = msg
+					}
+					e.failNode(runID, g, states, res.name, msg)
+					continue
+				}
+				output = merged
+			}
+		}
+
+		outputs[res.name] = output
+		states[res.name] = run.NodeCompleted
+		e.updateNode(runID, res.name, func(n *run.NodeStatus) {
+			n.State = run.NodeCompleted
+			n.Output = output
+			n.CompletedAt = time.Now()
+		})
+		e.store.Update(runID, func(r *run.Run) {
+			r.CurrentSkill = runningNodes(r.Nodes)
+		})
+	}
+
+	// If TUI is shutting down, leave state as-is for reconnection
+	if disconnected || (cancelled && e.isShuttingDown()) {
+		return
+	}
+	if rejected {
+		e.markRejected(runID)
+		return
+	}
+	if cancelled {
+		e.store.Update(runID, func(r *run.Run) {
+			r.State = run.StateFailed
+			r.Error = "cancelled"
+			r.CurrentSkill = ""
+			r.CompletedAt = time.Now()
+		})
+		return
+	}
+
+	e.appendRunSummary(runID)
+	if firstErr != "" {
+		e.store.Update(runID, func(r *run.Run) {
+			r.State = run.StateFailed
+			r.Error = firstErr
+			r.CurrentSkill = ""
+			r.CompletedAt = time.Now()
+		})
+		return
+	}
+
+	finalState := run.StateCompleted
+	for _, step := range g.Steps {
+		if step.Skill != "review" {
+			continue
+		}
+		// Review steps completed before their output was saved on the node
+		// have none; they do not decide the outcome.
+		if out, ok := outputs[step.StepName()]; ok && !reviewPassed(out) {
+			finalState = run.StateReviewing
+		}
+	}
+	e.store.Update(runID, func(r *run.Run) {
+		r.State = finalState
+		r.CurrentSkill = ""
+		r.CompletedAt = time.Now()
+	})
+}
+
+// failNode marks a graph step failed and every step that depends on it skipped.
+func (e *Executor) failNode(runID string, g *Graph, states map[string]run.NodeState, name string, msg string) {
+	states[name] = run.NodeFailed
+	e.updateNode(runID, name, func(n *run.NodeStatus) {
+		n.State = run.NodeFailed
+		n.Error = msg
+		n.CompletedAt = time.Now()
+	})
+	e.skipDescendants(runID, g, states, name, fmt.Sprintf("needs %s, which failed", name))
+	e.logToBuffer(runID, name, fmt.Sprintf("step failed: %s", msg))
+	e.store.Update(runID, func(r *run.Run) {
+		r.CurrentSkill = runningNodes(r.Nodes)
+	})
+}
+
+// skipNode marks a graph step and every step that depends on it skipped.
+func (e *Executor) skipNode(runID string, g *Graph, states map[string]run.NodeState, name string, msg string) {
+	states[name] = run.NodeSkipped
+	e.updateNode(runID, name, func(n *run.NodeStatus) {
+		n.State = run.NodeSkipped
+		n.Error = msg
+	})
+	e.skipDescendants(runID, g, states, name, fmt.Sprintf("needs %s, which was skipped", name))
+}
+
+func (e *Executor) skipDescendants(runID string, g *Graph, states map[string]run.NodeState, name string, msg string) {
+	for _, d := range g.Descendants(name) {
+		if states[d] != run.NodePending {
+			continue
+		}
+		states[d] = run.NodeSkipped
+		e.updateNode(runID, d, func(n *run.NodeStatus) {
+			n.State = run.NodeSkipped
+			n.Error = msg
+		})
+	}
+}
+
+// errGateRejected ends an approve gate step that was rejected.
+var errGateRejected = errors.New("rejected at approval gate")
+
+// defaultMaxLoops bounds a loop step that does not set max_loops.
+const defaultMaxLoops = 3
+
+func maxLoops(step config.StepConfig) int {
+	if step.MaxLoops > 0 {
+		return step.MaxLoops
+	}
+	return defaultMaxLoops
+}
+
+// loopBlocked reports whether a step the loop would reset is still running.
+func (e *Executor) loopBlocked(g *Graph, states map[string]run.NodeState, step config.StepConfig) bool {
+	if states[step.Loop] == run.NodeRunning {
+		return true
+	}
+	for _, d := range g.Descendants(step.Loop) {
+		if states[d] == run.NodeRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// takeLoop sends the graph back to the loop's target step, handing it the
+// loop's inputs (e.g. review findings) as previous output. Once the loop has
+// run max_loops times it completes without jumping and the graph moves on.
+func (e *Executor) takeLoop(runID string, g *Graph, states map[string]run.NodeState, outputs map[string]string, loopInput map[string]string, step config.StepConfig) {
+	name := step.StepName()
+	r, _ := e.store.Get(runID)
+	count := 0
+	for _, n := range r.Nodes {
+		if n.Name == name {
+			count = n.Loops
+		}
+	}
+
+	limit := maxLoops(step)
+	if count >= limit {
+		e.logToBuffer(runID, name, fmt.Sprintf("loop limit reached (%d/%d), not returning to %s", count, limit, step.Loop))
+		states[name] = run.NodeCompleted
+		e.updateNode(runID, name, func(n *run.NodeStatus) {
+			n.State = run.NodeCompleted
+			n.Error = "loop limit reached"
+			n.CompletedAt = time.Now()
+		})
+		return
+	}
+
+	count++
+	e.logToBuffer(runID, name, fmt.Sprintf("looping back to %s (%d/%d)", step.Loop, count, limit))
+	loopInput[step.Loop] = needsOutput(step.Needs, outputs)
+
+	reset := append([]string{step.Loop}, g.Descendants(step.Loop)...)
+	rerun := 0
+	for _, n := range reset {
+		if s, ok := g.Step(n); ok && s.Skill != "" && states[n] != run.NodePending {
+			rerun++
+		}
+		states[n] = run.NodePending
+		delete(outputs, n)
+		e.updateNode(runID, n, func(ns *run.NodeStatus) {
+			ns.State = run.NodePending
+			ns.Error = ""
+			ns.StartedAt = time.Time{}
+			ns.CompletedAt = time.Time{}
+		})
+	}
+	e.updateNode(runID, name, func(n *run.NodeStatus) {
+		n.Loops = count
+	})
+	e.store.Update(runID, func(r *run.Run) {
+		r.SkillTotal += rerun
+	})
+}
+
+// updateNode applies fn to the named node. Nodes is copied so readers holding
+// an earlier snapshot of the run never see it change underneath them.
+func (e *Executor) updateNode(runID, name string, fn func(*run.NodeStatus)) {
+	e.store.Update(runID, func(r *run.Run) {
+		nodes := make([]run.NodeStatus, len(r.Nodes))
+		copy(nodes, r.Nodes)
+		for i := range nodes {
+			if nodes[i].Name == name {
+				fn(&nodes[i])
+			}
+		}
+		r.Nodes = nodes
+	})
+}
+
+// runningNodes joins the names of the currently running nodes for display.
+func runningNodes(nodes []run.NodeStatus) string {
+	var names []string
+	for _, n := range nodes {
+		if n.State == run.NodeRunning {
+			names = append(names, n.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// needsOutput builds the previous-output context for a graph step. A step
+// with one dependency gets its output verbatim; several are labelled by step.
+func needsOutput(needs []string, outputs map[string]string) string {
+	if len(needs) == 1 {
+		return outputs[needs[0]]
+	}
+	var b strings.Builder
+	for _, need := range needs {
+		out, ok := outputs[need]
+		if !ok {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("### %s\n%s\n\n", need, out))
+	}
+	return b.String()
+}
+
 func (e *Executor) runSkill(ctx context.Context, runID string, prompt string, opts runtime.RunOptions, timeout int) (process.SkillResult, error) {
 	maxRetries := e.cfg.Limits.RateLimitMaxRetries
 	backoff := time.Duration(e.cfg.Limits.RateLimitBackoff) * time.Second
@@ -721,13 +1538,12 @@ func (e *Executor) executeParallelGroup(ctx context.Context, runID string, tasks
 		go func(idx int, t DecomposeTask) {
 			defer wg.Done()
 
-			skill, opts, ok := e.registry.SkillForRun("build")
+			r, _ := e.store.Get(runID)
+			skill, opts, ok := e.registry.SkillForWorkflow(r.Workflow, "build")
 			if !ok {
 				errs[idx] = fmt.Errorf("build skill not found")
 				return
 			}
-
-			r, _ := e.store.Get(runID)
 			opts.WorkDir = r.Worktree
 
 			taskPrompt := BuildPrompt(skill, PromptContext{
@@ -743,7 +1559,7 @@ func (e *Executor) executeParallelGroup(ctx context.Context, runID string, tasks
 
 			// Create a temporary entry in the store for the sub-task
 			// that mirrors the parent run so logging works
-			result, err := e.runParallelSkill(ctx, taskRunID, runID, taskPrompt, opts, skill.Timeout)
+			result, err := e.runParallelSkill(ctx, taskRunID, runID, "build", taskPrompt, opts, skill.Timeout)
 			results[idx] = result.ResultText
 			errs[idx] = err
 		}(i, task)
@@ -765,12 +1581,11 @@ func (e *Executor) executeParallelGroup(ctx context.Context, runID string, tasks
 }
 
 func (e *Executor) executeSingleTask(ctx context.Context, runID string, task DecomposeTask, userPrompt string, previousOutput string) (string, error) {
-	skill, opts, ok := e.registry.SkillForRun("build")
+	r, _ := e.store.Get(runID)
+	skill, opts, ok := e.registry.SkillForWorkflow(r.Workflow, "build")
 	if !ok {
 		return "", fmt.Errorf("build skill not found")
 	}
-
-	r, _ := e.store.Get(runID)
 	opts.WorkDir = r.Worktree
 
 	taskPrompt := BuildPrompt(skill, PromptContext{
@@ -790,7 +1605,7 @@ func (e *Executor) executeSingleTask(ctx context.Context, runID string, task Dec
 
 // runParallelSkill runs a skill for a parallel sub-task. It uses a composite
 // key for process tracking but accumulates tokens/cost to the parent run.
-func (e *Executor) runParallelSkill(ctx context.Context, taskRunID string, parentRunID string, prompt string, opts runtime.RunOptions, timeout int) (process.SkillResult, error) {
+func (e *Executor) runParallelSkill(ctx context.Context, taskRunID string, parentRunID string, skillName string, prompt string, opts runtime.RunOptions, timeout int) (process.SkillResult, error) {
 	// For parallel tasks we create a temporary run entry so the process manager
 	// can track the process. We copy essential fields from the parent.
 	parent, ok := e.store.Get(parentRunID)
@@ -804,7 +1619,7 @@ func (e *Executor) runParallelSkill(ctx context.Context, taskRunID string, paren
 		Worktree:     parent.Worktree,
 		Workflow:     parent.Workflow,
 		State:        run.StateRunning,
-		CurrentSkill: "build",
+		CurrentSkill: skillName,
 	})
 
 	defer e.store.Remove(taskRunID)
@@ -836,6 +1651,8 @@ func (e *Executor) runParallelSkill(ctx context.Context, taskRunID string, paren
 			r.Tokens += taskRun.Tokens
 			r.TokensIn += taskRun.TokensIn
 			r.TokensOut += taskRun.TokensOut
+			r.CacheRead += taskRun.CacheRead
+			r.CacheWrite += taskRun.CacheWrite
 			r.Cost += taskRun.Cost
 			r.SkillCosts = append(r.SkillCosts, taskRun.SkillCosts...)
 		})
@@ -1014,7 +1831,7 @@ func formatRunDuration(d time.Duration) string {
 // isNonModifyingSkill returns true for skills that don't modify files in the worktree.
 func isNonModifyingSkill(name string) bool {
 	switch name {
-	case "route", "decompose", "review":
+	case "route", "decompose", "review", config.ApproveGate:
 		return true
 	}
 	return false
@@ -1058,6 +1875,45 @@ func buildFollowUpContext(r run.Run) string {
 	return b.String()
 }
 
+// recordOutputs checks a skill's result against the outputs schema it
+// declares and stores the decoded JSON on the run under key. Skills without
+// a schema are left alone.
+func (e *Executor) recordOutputs(runID, key string, skill *Skill, text string) error {
+	if skill.Outputs == nil {
+		return nil
+	}
+	v := condition.DecodeOutput(text)
+	if v == nil {
+		return fmt.Errorf("skill %s: output is not JSON but the skill declares outputs", key)
+	}
+	if err := skill.Outputs.Validate(v); err != nil {
+		return fmt.Errorf("skill %s: output does not match its outputs schema: %w", key, err)
+	}
+	e.store.Update(runID, func(r *run.Run) {
+		outputs := make(map[string]interface{}, len(r.Outputs)+1)
+		for k, old := range r.Outputs {
+			outputs[k] = old
+		}
+		outputs[key] = v
+		r.Outputs = outputs
+	})
+	return nil
+}
+
+// specPath returns the spec file written by a spec skill: the "path" field
+// of its typed outputs when it declares one, otherwise the first specs/*.md
+// path mentioned in its output.
+func (e *Executor) specPath(runID, key, text string) string {
+	if r, ok := e.store.Get(runID); ok {
+		if fields, ok := r.Outputs[key].(map[string]interface{}); ok {
+			if p, ok := fields["path"].(string); ok && p != "" {
+				return p
+			}
+		}
+	}
+	return parseSpecFilePath(text)
+}
+
 // parseSpecFilePath extracts the first specs/*.md path from skill output text.
 func parseSpecFilePath(text string) string {
 	for _, line := range strings.Split(text, "\n") {
@@ -21,6 +21,7 @@ import (
 	"github.com/justinpbarnett/agtop/internal/process"
 	"github.com/justinpbarnett/agtop/internal/run"
 	"github.com/justinpbarnett/agtop/internal/runtime"
+	"github.com/justinpbarnett/agtop/internal/schema"
 )
 
 func executorTestConfig() *config.Config {
@@ -98,6 +99,31 @@ func TestResolveWorkflowSDLC(t *testing.T) {
 	}
 }
 
+func TestResolveWorkflowIncludes(t *testing.T) {
+	cfg := executorTestConfig()
+	cfg.Workflows["ship"] = config.WorkflowConfig{Skills: []string{"workflow:plan-build", "document", "workflow:build"}}
+
+	skills, err := ResolveWorkflow(cfg, "ship")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"spec", "build", "test", "review", "document", "build", "test"}
+	if strings.Join(skills, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, skills)
+	}
+}
+
+func TestResolveWorkflowIncludeCycle(t *testing.T) {
+	cfg := executorTestConfig()
+	cfg.Workflows["a"] = config.WorkflowConfig{Skills: []string{"build", "workflow:b"}}
+	cfg.Workflows["b"] = config.WorkflowConfig{Skills: []string{"workflow:a"}}
+
+	_, err := ResolveWorkflow(cfg, "a")
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+}
+
 // --- ValidateWorkflow tests ---
 
 func TestValidateWorkflowAllPresent(t *testing.T) {
@@ -1075,3 +1101,740 @@ func TestResumePreservesSkillIndexAcrossMultipleResumes(t *testing.T) {
 		t.Errorf("expected SkillIndex=4 after second resume, got %d (Bug B regression: SkillIndex was reset to a relative value)", r.SkillIndex)
 	}
 }
+
+// scriptedRuntime returns a mock runtime whose result text and exit error are
+// chosen per prompt by fn. Skill content is set by the caller so fn can tell
+// skills apart.
+func scriptedRuntime(fn func(prompt string) (string, error)) *executorMockRuntime {
+	return &executorMockRuntime{
+		startFn: func(_ context.Context, prompt string, _ runtime.RunOptions) (*runtime.Process, error) {
+			text, exitErr := fn(prompt)
+			resultJSON, _ := json.Marshal(text)
+
+			pr, pw := io.Pipe()
+			doneCh := make(chan error, 1)
+			go func() {
+				pw.Write([]byte(`{"type":"result","result":` + string(resultJSON) + `,"usage":{"input_tokens":10,"output_tokens":5},"total_cost_usd":0.001}` + "\n"))
+				pw.Close()
+				doneCh <- exitErr
+			}()
+
+			return &runtime.Process{
+				PID:    12345,
+				Stdout: pr,
+				Stderr: io.NopCloser(strings.NewReader("")),
+				Done:   doneCh,
+			}, nil
+		},
+	}
+}
+
+func newGraphTestExecutor(rt runtime.Runtime) (*Executor, *run.Store) {
+	exec, store := newTestExecutor(rt)
+	exec.cfg.Workflows["graph"] = config.WorkflowConfig{
+		Steps: []config.StepConfig{
+			{Skill: "spec"},
+			{Skill: "build", Needs: []string{"spec"}},
+			{Name: "lint", Skill: "test", Needs: []string{"spec"}},
+			{Skill: "review", Needs: []string{"build", "lint"}},
+		},
+	}
+	for name, s := range exec.registry.skills {
+		s.Content = "SKILL:" + name
+	}
+	return exec, store
+}
+
+func waitTerminal(t *testing.T, store *run.Store, id string) run.Run {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r, _ := store.Get(id)
+		if r.IsTerminal() {
+			return r
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	r, _ := store.Get(id)
+	t.Fatalf("run did not finish, state %s", r.State)
+	return r
+}
+
+func nodeStates(r run.Run) map[string]run.NodeState {
+	states := make(map[string]run.NodeState, len(r.Nodes))
+	for _, n := range r.Nodes {
+		states[n.Name] = n.State
+	}
+	return states
+}
+
+func TestExecuteGraphWorkflow(t *testing.T) {
+	var mu sync.Mutex
+	var reviewPrompt string
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		switch {
+		case strings.HasPrefix(prompt, "SKILL:build"):
+			return "built it", nil
+		case strings.HasPrefix(prompt, "SKILL:test"):
+			return "lint clean", nil
+		case strings.HasPrefix(prompt, "SKILL:review"):
+			mu.Lock()
+			reviewPrompt = prompt
+			mu.Unlock()
+			return `{"success": true}`, nil
+		}
+		return "ok", nil
+	})
+	exec, store := newGraphTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "graph", "add feature")
+	r := waitTerminal(t, store, id)
+
+	if r.State != run.StateCompleted {
+		t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+	}
+	for name, state := range nodeStates(r) {
+		if state != run.NodeCompleted {
+			t.Errorf("node %s: expected completed, got %s", name, state)
+		}
+	}
+	if r.SkillIndex != 4 || r.SkillTotal != 4 {
+		t.Errorf("expected progress 4/4, got %d/%d", r.SkillIndex, r.SkillTotal)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(reviewPrompt, "### build\nbuilt it") || !strings.Contains(reviewPrompt, "### lint\nlint clean") {
+		t.Errorf("review prompt should include both upstream outputs, got:\n%s", reviewPrompt)
+	}
+}
+
+func TestExecuteGraphFailureSkipsDescendants(t *testing.T) {
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		if strings.HasPrefix(prompt, "SKILL:build") {
+			return "", fmt.Errorf("exit status 1")
+		}
+		return "ok", nil
+	})
+	exec, store := newGraphTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "graph", "add feature")
+	r := waitTerminal(t, store, id)
+
+	if r.State != run.StateFailed {
+		t.Fatalf("expected StateFailed, got %s", r.State)
+	}
+	if !strings.Contains(r.Error, "build") {
+		t.Errorf("expected error to name the failed step, got %q", r.Error)
+	}
+	want := map[string]run.NodeState{
+		"spec":   run.NodeCompleted,
+		"build":  run.NodeFailed,
+		"lint":   run.NodeCompleted,
+		"review": run.NodeSkipped,
+	}
+	got := nodeStates(r)
+	for name, state := range want {
+		if got[name] != state {
+			t.Errorf("node %s: expected %s, got %s", name, state, got[name])
+		}
+	}
+}
+
+func TestResumeGraphSkipsCompletedNodes(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		mu.Lock()
+		ran = append(ran, strings.SplitN(strings.TrimPrefix(prompt, "SKILL:"), "\n", 2)[0])
+		mu.Unlock()
+		return `{"success": true}`, nil
+	})
+	exec, store := newGraphTestExecutor(rt)
+
+	id := store.Add(&run.Run{
+		State:    run.StatePaused,
+		Workflow: "graph",
+		Worktree: t.TempDir(),
+		Nodes: []run.NodeStatus{
+			{Name: "spec", Skill: "spec", State: run.NodeCompleted},
+			{Name: "build", Skill: "build", State: run.NodeRunning},
+			{Name: "lint", Skill: "test", State: run.NodeCompleted},
+			{Name: "review", Skill: "review", State: run.NodePending},
+		},
+	})
+	if err := exec.Resume(id, "add feature"); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	r := waitTerminal(t, store, id)
+	if r.State != run.StateCompleted {
+		t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(ran, ",") != "build,review" {
+		t.Errorf("expected only build and review to run, got %v", ran)
+	}
+}
+
+func newLoopTestExecutor(rt runtime.Runtime) (*Executor, *run.Store) {
+	exec, store := newGraphTestExecutor(rt)
+	exec.cfg.Workflows["fix-loop"] = config.WorkflowConfig{
+		Steps: []config.StepConfig{
+			{Skill: "spec"},
+			{Skill: "build", Needs: []string{"spec"}},
+			{Skill: "review", Needs: []string{"build"}},
+			{Name: "fix", Needs: []string{"review"}, When: "review.success == false", Loop: "build", MaxLoops: 2},
+			{Name: "docs", Skill: "test", Needs: []string{"review"}, When: "review.success"},
+		},
+	}
+	return exec, store
+}
+
+func TestExecuteGraphLoopUntilReviewPasses(t *testing.T) {
+	var mu sync.Mutex
+	reviews := 0
+	var buildPrompts []string
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasPrefix(prompt, "SKILL:build"):
+			buildPrompts = append(buildPrompts, prompt)
+		case strings.HasPrefix(prompt, "SKILL:review"):
+			reviews++
+			if reviews == 1 {
+				return `{"success": false, "issues": ["missing nil check"]}`, nil
+			}
+			return `{"success": true}`, nil
+		}
+		return "ok", nil
+	})
+	exec, store := newLoopTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "fix-loop", "add feature")
+	r := waitTerminal(t, store, id)
+
+	if r.State != run.StateCompleted {
+		t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+	}
+	got := nodeStates(r)
+	if got["fix"] != run.NodeSkipped || got["docs"] != run.NodeCompleted {
+		t.Errorf("expected fix skipped and docs completed, got %v", got)
+	}
+	for _, n := range r.Nodes {
+		if n.Name == "fix" && n.Loops != 1 {
+			t.Errorf("expected 1 loop, got %d", n.Loops)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(buildPrompts) != 2 {
+		t.Fatalf("expected build to run twice, got %d", len(buildPrompts))
+	}
+	if !strings.Contains(buildPrompts[1], "missing nil check") {
+		t.Errorf("second build should receive review findings, got:\n%s", buildPrompts[1])
+	}
+}
+
+func TestExecuteGraphLoopLimit(t *testing.T) {
+	var mu sync.Mutex
+	builds := 0
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		if strings.HasPrefix(prompt, "SKILL:build") {
+			mu.Lock()
+			builds++
+			mu.Unlock()
+		}
+		if strings.HasPrefix(prompt, "SKILL:review") {
+			return `{"success": false}`, nil
+		}
+		return "ok", nil
+	})
+	exec, store := newLoopTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "fix-loop", "add feature")
+	r := waitTerminal(t, store, id)
+
+	if r.State != run.StateReviewing {
+		t.Fatalf("expected StateReviewing, got %s (error: %s)", r.State, r.Error)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if builds != 3 {
+		t.Errorf("expected build to run 3 times (1 + max_loops 2), got %d", builds)
+	}
+	got := nodeStates(r)
+	if got["fix"] != run.NodeCompleted || got["docs"] != run.NodeSkipped {
+		t.Errorf("expected fix completed and docs skipped, got %v", got)
+	}
+}
+
+func newGateTestExecutor(rt runtime.Runtime) (*Executor, *run.Store) {
+	exec, store := newGraphTestExecutor(rt)
+	exec.cfg.Workflows["gated"] = config.WorkflowConfig{Skills: []string{"spec", "approve", "build"}}
+	exec.cfg.Workflows["gated-graph"] = config.WorkflowConfig{
+		Steps: []config.StepConfig{
+			{Skill: "spec"},
+			{Skill: "approve", Needs: []string{"spec"}},
+			{Skill: "build", Needs: []string{"approve"}},
+			{Name: "lint", Skill: "test", Needs: []string{"spec"}},
+		},
+	}
+	return exec, store
+}
+
+func waitState(t *testing.T, store *run.Store, id string, state run.State) run.Run {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r, _ := store.Get(id)
+		if r.State == state {
+			return r
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	r, _ := store.Get(id)
+	t.Fatalf("expected state %s, got %s", state, r.State)
+	return r
+}
+
+// skillCounter returns a runtime that records how often each skill ran.
+func skillCounter() (*executorMockRuntime, func(skill string) int) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, s := range []string{"spec", "build", "test"} {
+			if strings.HasPrefix(prompt, "SKILL:"+s) {
+				counts[s]++
+			}
+		}
+		return "ok", nil
+	})
+	return rt, func(skill string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[skill]
+	}
+}
+
+func TestExecuteApproveGateContinues(t *testing.T) {
+	rt, ran := skillCounter()
+	exec, store := newGateTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "gated", "add feature")
+	r := waitState(t, store, id, run.StateAwaitingApproval)
+
+	if r.CurrentSkill != "approve" {
+		t.Errorf("expected current skill approve, got %q", r.CurrentSkill)
+	}
+	if ran("build") != 0 {
+		t.Fatal("build should not start before approval")
+	}
+
+	if err := exec.ApproveGate(id, "add feature"); err != nil {
+		t.Fatalf("ApproveGate: %v", err)
+	}
+	r = waitTerminal(t, store, id)
+	if r.State != run.StateCompleted {
+		t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+	}
+	if ran("spec") != 1 || ran("build") != 1 {
+		t.Errorf("expected spec and build once, got %d and %d", ran("spec"), ran("build"))
+	}
+}
+
+func TestExecuteApproveGateReject(t *testing.T) {
+	rt, ran := skillCounter()
+	exec, store := newGateTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "gated", "add feature")
+	waitState(t, store, id, run.StateAwaitingApproval)
+
+	if err := exec.RejectGate(id); err != nil {
+		t.Fatalf("RejectGate: %v", err)
+	}
+	r := waitTerminal(t, store, id)
+	if r.State != run.StateRejected {
+		t.Fatalf("expected StateRejected, got %s", r.State)
+	}
+	if ran("build") != 0 {
+		t.Error("build should not run after rejection")
+	}
+}
+
+func TestApproveGateWithoutWorkerResumes(t *testing.T) {
+	rt, ran := skillCounter()
+	exec, store := newGateTestExecutor(rt)
+
+	// A gated run rehydrated after a restart has no executor worker.
+	id := store.Add(&run.Run{
+		State:        run.StateAwaitingApproval,
+		Workflow:     "gated",
+		CurrentSkill: "approve",
+		SkillIndex:   2,
+		SkillTotal:   3,
+		Worktree:     t.TempDir(),
+	})
+	if err := exec.ApproveGate(id, "add feature"); err != nil {
+		t.Fatalf("ApproveGate: %v", err)
+	}
+	r := waitTerminal(t, store, id)
+	if r.State != run.StateCompleted {
+		t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+	}
+	if ran("spec") != 0 || ran("build") != 1 {
+		t.Errorf("expected only build to run, got spec=%d build=%d", ran("spec"), ran("build"))
+	}
+}
+
+func TestApproveGateWithoutWorkerKeepsOutputs(t *testing.T) {
+	var mu sync.Mutex
+	var prompts []string
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		prompts = append(prompts, prompt)
+		return "ok", nil
+	})
+	exec, store := newGateTestExecutor(rt)
+	exec.cfg.Workflows["gated-when"] = config.WorkflowConfig{
+		Steps: []config.StepConfig{
+			{Skill: "spec"},
+			{Skill: "approve", Needs: []string{"spec"}},
+			{Skill: "build", Needs: []string{"approve"}, When: "spec.ready"},
+		},
+	}
+
+	// Both runs were parked at their gate by a process that has exited.
+	graphID := store.Add(&run.Run{
+		State:    run.StateAwaitingApproval,
+		Workflow: "gated-when",
+		Worktree: t.TempDir(),
+		Nodes: []run.NodeStatus{
+			{Name: "spec", Skill: "spec", State: run.NodeCompleted, Output: `{"ready": true, "plan": "graph plan"}`},
+			{Name: "approve", Skill: "approve", Needs: []string{"spec"}, State: run.NodeRunning},
+			{Name: "build", Skill: "build", Needs: []string{"approve"}, State: run.NodePending},
+		},
+	})
+	linearID := store.Add(&run.Run{
+		State:        run.StateAwaitingApproval,
+		Workflow:     "gated",
+		CurrentSkill: "approve",
+		SkillIndex:   2,
+		SkillTotal:   3,
+		Worktree:     t.TempDir(),
+		LastOutput:   "linear plan",
+	})
+
+	for _, id := range []string{graphID, linearID} {
+		if err := exec.ApproveGate(id, "add feature"); err != nil {
+			t.Fatalf("ApproveGate: %v", err)
+		}
+		r := waitTerminal(t, store, id)
+		if r.State != run.StateCompleted {
+			t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+		}
+	}
+	r, _ := store.Get(graphID)
+	if got := nodeStates(r); got["build"] != run.NodeCompleted {
+		t.Errorf("expected build to run on the saved spec output, got %s", got["build"])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	joined := strings.Join(prompts, "\n---\n")
+	for _, want := range []string{"graph plan", "linear plan"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected a build prompt with %q, got:\n%s", want, joined)
+		}
+	}
+}
+
+func TestApproveGateRequiresApprovalState(t *testing.T) {
+	rt, _ := skillCounter()
+	exec, store := newGateTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateCompleted, Workflow: "gated"})
+	if err := exec.ApproveGate(id, ""); err == nil {
+		t.Error("expected error approving a completed run")
+	}
+	if err := exec.RejectGate(id); err == nil {
+		t.Error("expected error rejecting a completed run at a gate")
+	}
+}
+
+func TestExecuteGraphApproveGate(t *testing.T) {
+	rt, ran := skillCounter()
+	exec, store := newGateTestExecutor(rt)
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "gated-graph", "add feature")
+	waitState(t, store, id, run.StateAwaitingApproval)
+
+	// Steps that do not need the gate keep running while it waits.
+	deadline := time.Now().Add(5 * time.Second)
+	for ran("test") == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if ran("test") != 1 || ran("build") != 0 {
+		t.Fatalf("expected lint to run and build to wait, got test=%d build=%d", ran("test"), ran("build"))
+	}
+
+	done := exec.Done(id)
+	if err := exec.RejectGate(id); err != nil {
+		t.Fatalf("RejectGate: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the worker to exit after rejection")
+	}
+	r, _ := store.Get(id)
+	if r.State != run.StateRejected {
+		t.Fatalf("expected StateRejected, got %s (error: %s)", r.State, r.Error)
+	}
+	if got := nodeStates(r); got["build"] != run.NodeSkipped {
+		t.Errorf("expected build skipped, got %s", got["build"])
+	}
+}
+
+func specOutputsSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse(map[string]interface{}{
+		"type":       "object",
+		"required":   []interface{}{"path"},
+		"properties": map[string]interface{}{"path": map[string]interface{}{"type": "string"}},
+	})
+	if err != nil {
+		t.Fatalf("schema.Parse: %v", err)
+	}
+	return s
+}
+
+func TestExecuteRecordsTypedOutputs(t *testing.T) {
+	var mu sync.Mutex
+	var buildPrompt string
+	rt := scriptedRuntime(func(prompt string) (string, error) {
+		if strings.HasPrefix(prompt, "SKILL:spec") {
+			return "Wrote the spec.\n```json\n{\"path\": \"specs/typed.md\"}\n```", nil
+		}
+		mu.Lock()
+		buildPrompt = prompt
+		mu.Unlock()
+		return "ok", nil
+	})
+	exec, store := newGateTestExecutor(rt)
+	exec.registry.skills["spec"].Outputs = specOutputsSchema(t)
+	exec.cfg.Workflows["typed"] = config.WorkflowConfig{Skills: []string{"spec", "build"}}
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "typed", "add feature")
+	r := waitTerminal(t, store, id)
+
+	if r.State != run.StateCompleted {
+		t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+	}
+	spec, ok := r.Outputs["spec"].(map[string]interface{})
+	if !ok || spec["path"] != "specs/typed.md" {
+		t.Errorf("expected typed spec output, got %v", r.Outputs)
+	}
+	if r.SpecFile != "specs/typed.md" {
+		t.Errorf("expected spec file from outputs, got %q", r.SpecFile)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(buildPrompt, "spec.path: specs/typed.md") {
+		t.Errorf("build prompt should expose spec outputs, got:\n%s", buildPrompt)
+	}
+}
+
+func TestExecuteFailsOnOutputsMismatch(t *testing.T) {
+	rt, ran := skillCounter()
+	exec, store := newGateTestExecutor(rt)
+	exec.registry.skills["spec"].Outputs = specOutputsSchema(t)
+	exec.cfg.Workflows["typed"] = config.WorkflowConfig{Skills: []string{"spec", "build"}}
+
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: t.TempDir()})
+	exec.Execute(id, "typed", "add feature")
+	r := waitTerminal(t, store, id)
+
+	if r.State != run.StateFailed {
+		t.Fatalf("expected StateFailed, got %s", r.State)
+	}
+	if !strings.Contains(r.Error, "outputs") {
+		t.Errorf("expected outputs error, got %q", r.Error)
+	}
+	if ran("build") != 0 {
+		t.Error("build should not run after a contract violation")
+	}
+}
+
+func TestExecuteWithReplayRuntime(t *testing.T) {
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"spec.yaml": `
+events:
+  - type: write
+    path: specs/replay.md
+    content: "# Spec"
+  - type: result
+    text: Wrote specs/replay.md
+    input_tokens: 100
+    output_tokens: 20
+    cost: 0.02
+`,
+		"build.yaml": `
+events:
+  - type: text
+    text: Implementing the spec
+  - type: write
+    path: feature.go
+    content: "package feature"
+  - type: result
+    text: built
+    input_tokens: 200
+    output_tokens: 80
+    cost: 0.05
+`,
+		"default.jsonl": `{"type":"result","result":"{\"success\": true}","usage":{"input_tokens":10,"output_tokens":5},"total_cost_usd":0.01}` + "\n",
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rt, err := runtime.NewReplayRuntime(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec, store := newTestExecutor(rt)
+
+	worktree := t.TempDir()
+	id := store.Add(&run.Run{State: run.StateQueued, Worktree: worktree})
+	exec.Execute(id, "plan-build", "add feature")
+	r := waitTerminal(t, store, id)
+
+	if r.State != run.StateCompleted {
+		t.Fatalf("expected StateCompleted, got %s (error: %s)", r.State, r.Error)
+	}
+	if r.SpecFile != "specs/replay.md" {
+		t.Errorf("expected spec file from the spec script, got %q", r.SpecFile)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "feature.go")); err != nil {
+		t.Errorf("expected build script to write feature.go: %v", err)
+	}
+	// spec + build + test + review, the last two from default.jsonl.
+	if want := 0.02 + 0.05 + 0.01 + 0.01; r.Cost < want-1e-9 || r.Cost > want+1e-9 {
+		t.Errorf("expected cost %.2f, got %f", want, r.Cost)
+	}
+}
+
+// sessionRuntime returns a mock runtime that reports a new session ID from
+// each launch and records the options every launch was given.
+func sessionRuntime() (*executorMockRuntime, func() []runtime.RunOptions) {
+	var mu sync.Mutex
+	var launches []runtime.RunOptions
+
+	rt := &executorMockRuntime{
+		startFn: func(_ context.Context, _ string, opts runtime.RunOptions) (*runtime.Process, error) {
+			mu.Lock()
+			launches = append(launches, opts)
+			n := len(launches)
+			mu.Unlock()
+
+			pr, pw := io.Pipe()
+			doneCh := make(chan error, 1)
+			go func() {
+				pw.Write([]byte(fmt.Sprintf(`{"type":"system","subtype":"init","session_id":"sess-%d"}`, n) + "\n"))
+				pw.Write([]byte(`{"type":"result","result":"ok","usage":{"input_tokens":10,"output_tokens":5},"total_cost_usd":0.001}` + "\n"))
+				pw.Close()
+				doneCh <- nil
+			}()
+			return &runtime.Process{
+				PID:    12345,
+				Stdout: pr,
+				Stderr: io.NopCloser(strings.NewReader("")),
+				Done:   doneCh,
+			}, nil
+		},
+	}
+	return rt, func() []runtime.RunOptions {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]runtime.RunOptions(nil), launches...)
+	}
+}
+
+func waitForState(t *testing.T, store *run.Store, runID string, state run.State) run.Run {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if r, _ := store.Get(runID); r.State == state {
+			return r
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	r, _ := store.Get(runID)
+	t.Fatalf("run %s is %s, want %s (error: %s)", runID, r.State, state, r.Error)
+	return r
+}
+
+func TestExecutorResumesSessionAcrossSkills(t *testing.T) {
+	rt, launches := sessionRuntime()
+	exec, store := newTestExecutor(rt)
+	exec.cfg.Workflows["resumed"] = config.WorkflowConfig{Skills: []string{"spec", "build"}, Session: config.SessionResume}
+
+	runID := store.Add(&run.Run{State: run.StateQueued, Workflow: "resumed"})
+	exec.Execute(runID, "resumed", "add a flag")
+	r := waitForState(t, store, runID, run.StateCompleted)
+
+	got := launches()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 launches, got %d", len(got))
+	}
+	if got[0].Resume != "" {
+		t.Errorf("first skill should start a fresh session, got --resume %q", got[0].Resume)
+	}
+	if got[1].Resume != "sess-1" {
+		t.Errorf("second skill should resume sess-1, got %q", got[1].Resume)
+	}
+	if r.SessionID != "sess-2" {
+		t.Errorf("expected the latest session ID on the run, got %q", r.SessionID)
+	}
+
+	// A follow-up carries on the conversation instead of summarising it.
+	if err := exec.FollowUp(runID, "also update the docs"); err != nil {
+		t.Fatalf("follow-up: %v", err)
+	}
+	waitForState(t, store, runID, run.StateCompleted)
+	got = launches()
+	if len(got) != 3 || got[2].Resume != "sess-2" {
+		t.Errorf("expected the follow-up to resume sess-2, got %+v", got[len(got)-1])
+	}
+}
+
+func TestExecutorFreshSessionsByDefault(t *testing.T) {
+	rt, launches := sessionRuntime()
+	exec, store := newTestExecutor(rt)
+
+	runID := store.Add(&run.Run{State: run.StateQueued, Workflow: "build"})
+	exec.Execute(runID, "build", "add a flag")
+	waitForState(t, store, runID, run.StateCompleted)
+
+	for i, opts := range launches() {
+		if opts.Resume != "" || opts.Continue {
+			t.Errorf("launch %d should start a fresh session, got %+v", i, opts)
+		}
+	}
+}
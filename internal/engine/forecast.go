@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+// PlannedSteps returns the skills a new run of workflow will execute, each
+// with the model it resolves to, for cost forecasts. It returns nil for
+// workflows whose skills are only known at run time, such as auto and
+// quick-fix.
+func (e *Executor) PlannedSteps(workflow string) []cost.Step {
+	if workflow == "auto" {
+		return nil
+	}
+	skills, err := ResolveWorkflow(e.cfg, workflow)
+	if err != nil {
+		return nil
+	}
+	return e.steps(workflow, skills)
+}
+
+// RemainingSteps returns the skills r has not finished yet, including the
+// one running now. A graph workflow's remaining steps are its pending and
+// running nodes.
+func (e *Executor) RemainingSteps(r run.Run) []cost.Step {
+	if r.IsTerminal() {
+		return nil
+	}
+	if len(r.Nodes) > 0 {
+		var skills []string
+		for _, n := range r.Nodes {
+			if n.State == run.NodePending || n.State == run.NodeRunning {
+				skills = append(skills, n.Skill)
+			}
+		}
+		return e.steps(r.Workflow, skills)
+	}
+
+	skills, err := e.resolveSkills(r.Workflow)
+	if err != nil || len(skills) != r.SkillTotal {
+		// Follow-ups and quick fixes run a single skill outside the
+		// workflow's list.
+		if r.CurrentSkill == "" {
+			return nil
+		}
+		return e.steps(r.Workflow, []string{r.CurrentSkill})
+	}
+	start := r.SkillIndex - 1
+	if start < 0 {
+		start = 0
+	}
+	return e.steps(r.Workflow, skills[start:])
+}
+
+func (e *Executor) steps(workflow string, skills []string) []cost.Step {
+	steps := make([]cost.Step, 0, len(skills))
+	for _, name := range skills {
+		if name == config.ApproveGate {
+			continue
+		}
+		step := cost.Step{Skill: name}
+		if _, opts, ok := e.registry.SkillForWorkflow(workflow, name); ok {
+			step.Model = opts.Model
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
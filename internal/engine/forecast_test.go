@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+func TestPlannedSteps(t *testing.T) {
+	exec, _ := newTestExecutor(&executorMockRuntime{})
+	exec.registry.skills["build"].Model = "sonnet"
+	exec.cfg.Workflows["gated"] = config.WorkflowConfig{Skills: []string{"spec", config.ApproveGate, "build"}}
+
+	got := exec.PlannedSteps("gated")
+	want := []cost.Step{{Skill: "spec"}, {Skill: "build", Model: "sonnet"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlannedSteps(gated) = %+v, want %+v (gate skipped)", got, want)
+	}
+	if got := exec.PlannedSteps("auto"); got != nil {
+		t.Errorf("PlannedSteps(auto) = %+v, want nil", got)
+	}
+	if got := exec.PlannedSteps("missing"); got != nil {
+		t.Errorf("PlannedSteps(missing) = %+v, want nil", got)
+	}
+}
+
+func TestRemainingSteps(t *testing.T) {
+	exec, _ := newTestExecutor(&executorMockRuntime{})
+
+	skills := func(steps []cost.Step) []string {
+		var names []string
+		for _, s := range steps {
+			names = append(names, s.Skill)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name string
+		r    run.Run
+		want []string
+	}{
+		{
+			name: "linear workflow from the running skill",
+			r:    run.Run{Workflow: "plan-build", State: run.StateRunning, SkillIndex: 2, SkillTotal: 4, CurrentSkill: "build"},
+			want: []string{"build", "test", "review"},
+		},
+		{
+			name: "queued run",
+			r:    run.Run{Workflow: "build", State: run.StateRunning, SkillTotal: 2},
+			want: []string{"build", "test"},
+		},
+		{
+			name: "follow-up runs one skill",
+			r:    run.Run{Workflow: "plan-build", State: run.StateRunning, SkillIndex: 1, SkillTotal: 1, CurrentSkill: "build"},
+			want: []string{"build"},
+		},
+		{
+			name: "graph workflow pending and running nodes",
+			r: run.Run{Workflow: "graph", State: run.StateRunning, Nodes: []run.NodeStatus{
+				{Name: "spec", Skill: "spec", State: run.NodeCompleted},
+				{Name: "build", Skill: "build", State: run.NodeRunning},
+				{Name: "check", Skill: "test", State: run.NodePending},
+				{Name: "docs", Skill: "document", State: run.NodeSkipped},
+			}},
+			want: []string{"build", "test"},
+		},
+		{
+			name: "finished run",
+			r:    run.Run{Workflow: "build", State: run.StateCompleted, SkillIndex: 2, SkillTotal: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skills(exec.RemainingSteps(tt.r)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RemainingSteps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
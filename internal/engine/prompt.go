@@ -1,40 +1,74 @@
 package engine
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
 	"strings"
+	"text/template"
 )
 
 type PromptContext struct {
-	WorkDir        string            // Worktree path
-	Branch         string            // Git branch name
-	PreviousOutput string            // Summary from previous skill (empty for first skill)
-	UserPrompt     string            // The user's original task description
-	SafetyPatterns []string          // Blocked command patterns for safety preamble
-	WorkflowNames  []string          // Available workflow names (injected for route skill)
-	SpecFile       string            // Path to the generated spec file (set after spec skill)
-	ModifiedFiles  []string          // Files changed by the previous skill (from git diff --name-only)
-	Repos          map[string]string // Multi-repo: relative path → worktree path (nil for single-repo)
+	WorkDir        string                 // Worktree path
+	Branch         string                 // Git branch name
+	PreviousOutput string                 // Summary from previous skill (empty for first skill)
+	UserPrompt     string                 // The user's original task description
+	SafetyPatterns []string               // Blocked command patterns for safety preamble
+	WorkflowNames  []string               // Available workflow names (injected for route skill)
+	SpecFile       string                 // Path to the generated spec file (set after spec skill)
+	ModifiedFiles  []string               // Files changed by the previous skill (from git diff --name-only)
+	Repos          map[string]string      // Multi-repo: relative path → worktree path (nil for single-repo)
+	Outputs        map[string]interface{} // Typed outputs of earlier skills, keyed by skill or step name
 }
 
-// skillTaskOverrides maps skill names to fixed task descriptions.
-// These skills have a well-defined job regardless of the user's original prompt.
-// Without this, the raw user prompt (e.g. "review the project and implement
-// refactoring opportunities") leaks into utility skills and causes them to go
-// off-script — exploring, implementing, or duplicating work done by earlier skills.
-var skillTaskOverrides = map[string]string{
-	"test":     "Run the project's full validation suite (lint, typecheck, tests). If any checks fail, diagnose and fix the issues, then re-run to confirm. Produce the JSON report.",
-	"commit":   "Review all uncommitted changes in this worktree and create atomic commits using conventional commit format. If there are no changes to commit, do nothing.",
-	"review":   "Review the implemented changes against the spec to verify correctness and completeness. Classify any issues found by severity. Produce the structured review report.",
-	"document": "Generate documentation for the changes made on this branch.",
+// promptData is the run context plus the resolved task.
+type promptData struct {
+	PromptContext
+	Task string
 }
 
 // BuildPrompt assembles the final prompt for a claude -p invocation by
 // combining the skill's markdown body with run context.
+//
+// A skill whose frontmatter sets template: true has its body and task
+// rendered with the run context. Its body places that context itself, so the
+// Context and Task sections are not appended. If rendering fails, the raw
+// body is used with the usual sections and the failure is logged. Other
+// skills are used verbatim, so literal braces such as ${{ secrets.X }} are
+// left alone.
 func BuildPrompt(skill *Skill, pctx PromptContext) string {
 	var b strings.Builder
 
-	b.WriteString(skill.Content)
+	// Utility skills declare a fixed task in their frontmatter so the user's
+	// raw prompt doesn't cause them to go off-script (e.g. test skill doing
+	// implementation).
+	data := promptData{PromptContext: pctx, Task: pctx.UserPrompt}
+	if skill.Task != "" {
+		data.Task = skill.Task
+		if skill.Template {
+			task, err := renderTemplate(skill.Name+" task", skill.Task, data)
+			if err != nil {
+				log.Printf("warning: render task of skill %s: %v", skill.Name, err)
+			} else {
+				data.Task = task
+			}
+		}
+	}
+
+	templated := false
+	if skill.Template {
+		body, err := renderTemplate(skill.Name, skill.Content, data)
+		if err != nil {
+			log.Printf("warning: render skill %s: %v", skill.Name, err)
+		} else {
+			b.WriteString(body)
+			templated = true
+		}
+	}
+	if !templated {
+		b.WriteString(skill.Content)
+	}
 
 	if len(pctx.SafetyPatterns) > 0 {
 		b.WriteString("\n\n---\n\n## Safety Constraints\n\n")
@@ -45,6 +79,24 @@ func BuildPrompt(skill *Skill, pctx PromptContext) string {
 		b.WriteString("\nIf a task requires any of these operations, STOP and report that the operation is blocked by safety policy. Do not attempt workarounds.")
 	}
 
+	if !templated {
+		writeContext(&b, data)
+	}
+
+	if skill.Outputs != nil {
+		b.WriteString("\n\n## Output\n\n")
+		b.WriteString("End your response with a JSON object that matches this schema. Later skills read it instead of your prose.\n\n```json\n")
+		b.WriteString(skill.Outputs.String())
+		b.WriteString("\n```")
+	}
+
+	return b.String()
+}
+
+// writeContext appends the default Context and Task sections used by skills
+// whose body is not a template.
+func writeContext(b *strings.Builder, data promptData) {
+	pctx := data.PromptContext
 	b.WriteString("\n\n---\n\n## Context\n")
 	if pctx.WorkDir != "" {
 		b.WriteString("\n- Working directory: ")
@@ -81,15 +133,100 @@ func BuildPrompt(skill *Skill, pctx PromptContext) string {
 		b.WriteString(strings.Join(pctx.ModifiedFiles, ", "))
 	}
 
-	// Use a fixed task for utility skills so the user's raw prompt doesn't
-	// cause them to go off-script (e.g. test skill doing implementation).
-	task := pctx.UserPrompt
-	if override, ok := skillTaskOverrides[skill.Name]; ok {
-		task = override
+	if len(pctx.Outputs) > 0 {
+		b.WriteString("\n- Outputs of earlier skills:")
+		for _, line := range outputVariables(pctx.Outputs) {
+			b.WriteString("\n  - ")
+			b.WriteString(line)
+		}
 	}
 
 	b.WriteString("\n\n## Task\n\n")
-	b.WriteString(task)
+	b.WriteString(data.Task)
+}
 
-	return b.String()
+// templateFuncs are available to skill templates in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// parseTemplate compiles skill text as a template. ParseSkill calls it so
+// syntax errors surface when skills load rather than mid-run. Missing map
+// keys render as their zero value, so the outputs of a skill that has not
+// run are empty text.
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+func renderTemplate(name, text string, data promptData) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, templateData(data)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// templateData is the value skill templates are rendered with. Outputs are
+// flattened to text per field: a missing key of a concrete map type renders
+// as "" under missingkey=zero, whereas a missing interface value would print
+// "<no value>".
+func templateData(data promptData) map[string]interface{} {
+	outputs := make(map[string]map[string]string, len(data.Outputs))
+	for name, v := range data.Outputs {
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		out := make(map[string]string, len(fields))
+		for field, fv := range fields {
+			out[field] = formatOutput(fv)
+		}
+		outputs[name] = out
+	}
+	return map[string]interface{}{
+		"WorkDir":        data.WorkDir,
+		"Branch":         data.Branch,
+		"PreviousOutput": data.PreviousOutput,
+		"UserPrompt":     data.UserPrompt,
+		"WorkflowNames":  data.WorkflowNames,
+		"SpecFile":       data.SpecFile,
+		"ModifiedFiles":  data.ModifiedFiles,
+		"Repos":          data.Repos,
+		"Outputs":        outputs,
+		"Task":           data.Task,
+	}
+}
+
+// outputVariables flattens typed outputs into "skill.field: value" lines,
+// sorted so prompts are stable across runs.
+func outputVariables(outputs map[string]interface{}) []string {
+	var lines []string
+	for name, v := range outputs {
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			lines = append(lines, fmt.Sprintf("%s: %s", name, formatOutput(v)))
+			continue
+		}
+		for field, fv := range fields {
+			lines = append(lines, fmt.Sprintf("%s.%s: %s", name, field, formatOutput(fv)))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func formatOutput(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
 }
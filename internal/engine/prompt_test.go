@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -162,13 +163,14 @@ func TestBuildPromptPreservesSkillContent(t *testing.T) {
 }
 
 func TestBuildPromptSkillTaskOverride(t *testing.T) {
-	// Utility skills (test, commit, review, document) should get a fixed task,
-	// not the user's raw prompt which can cause them to go off-script.
+	// Utility skills (test, commit, review, document) declare a fixed task in
+	// their frontmatter, not the user's raw prompt which can cause them to go
+	// off-script.
 	for _, skillName := range []string{"test", "commit", "review", "document"} {
 		t.Run(skillName, func(t *testing.T) {
-			skill := &Skill{
-				Name:    skillName,
-				Content: "# " + skillName + " skill",
+			skill := builtinSkill(t, skillName)
+			if skill.Task == "" {
+				t.Fatalf("skill %q should declare a task in its frontmatter", skillName)
 			}
 			pctx := PromptContext{
 				WorkDir:    "/tmp/worktree",
@@ -181,15 +183,23 @@ func TestBuildPromptSkillTaskOverride(t *testing.T) {
 			if strings.Contains(result, "comprehensive review and implement refactoring") {
 				t.Errorf("skill %q should not receive the raw user prompt as its task", skillName)
 			}
-
-			override := skillTaskOverrides[skillName]
-			if !strings.Contains(result, override) {
+			if !strings.Contains(result, skill.Task) {
 				t.Errorf("skill %q missing its fixed task override in prompt", skillName)
 			}
 		})
 	}
 }
 
+// builtinSkill parses one of the SKILL.md files embedded in the binary.
+func builtinSkill(t *testing.T, name string) *Skill {
+	t.Helper()
+	skill, err := ParseSkillFile(filepath.Join("..", "..", "skills", name, "SKILL.md"), PriorityBuiltIn)
+	if err != nil {
+		t.Fatalf("parse built-in %s skill: %v", name, err)
+	}
+	return skill
+}
+
 func TestBuildPromptNoOverrideForBuild(t *testing.T) {
 	// Non-utility skills (build, spec, route, decompose) should receive the
 	// user's original prompt — they need it to understand what to implement.
@@ -226,9 +236,9 @@ func TestBuildPromptWorkflowNames(t *testing.T) {
 }
 
 func TestReviewTaskOverrideDoesNotContainFix(t *testing.T) {
-	override, ok := skillTaskOverrides["review"]
-	if !ok {
-		t.Fatal("expected review entry in skillTaskOverrides")
+	override := builtinSkill(t, "review").Task
+	if override == "" {
+		t.Fatal("expected review skill to declare a task")
 	}
 	lower := strings.ToLower(override)
 	if strings.Contains(lower, "fix") {
@@ -304,3 +314,112 @@ func TestBuildPrompt_OmitsEmptyHandoffFields(t *testing.T) {
 		t.Error("prompt should not contain 'Files modified by previous step:' when ModifiedFiles is empty")
 	}
 }
+
+func TestBuildPrompt_IncludesOutputs(t *testing.T) {
+	skill := &Skill{
+		Name:    "build",
+		Content: "# Build skill",
+	}
+	pctx := PromptContext{
+		UserPrompt: "Implement the feature",
+		Outputs: map[string]interface{}{
+			"spec": map[string]interface{}{"path": "specs/feat.md", "tasks": 3.0},
+			"lint": true,
+		},
+	}
+
+	result := BuildPrompt(skill, pctx)
+
+	for _, want := range []string{"- lint: true", "- spec.path: specs/feat.md", "- spec.tasks: 3"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestBuildPrompt_IncludesOutputsSchema(t *testing.T) {
+	skill, err := ParseSkill([]byte("---\nname: spec\noutputs:\n  type: object\n  properties:\n    path:\n      type: string\n---\nWrite a spec.\n"), "spec/SKILL.md", PriorityBuiltIn)
+	if err != nil {
+		t.Fatalf("ParseSkill: %v", err)
+	}
+
+	result := BuildPrompt(skill, PromptContext{UserPrompt: "Spec it"})
+
+	if !strings.Contains(result, "## Output") || !strings.Contains(result, `"path"`) {
+		t.Errorf("prompt missing outputs schema, got:\n%s", result)
+	}
+	if plain := BuildPrompt(&Skill{Name: "build"}, PromptContext{}); strings.Contains(plain, "## Output") {
+		t.Error("skills without outputs should not get an output section")
+	}
+}
+
+func TestBuildPromptTemplate(t *testing.T) {
+	skill := &Skill{
+		Name:     "custom",
+		Template: true,
+		Content: "Work on {{ .Branch }} using {{ .Outputs.spec.path }}.\n" +
+			"Changed: {{ join .ModifiedFiles \", \" }}\n" +
+			"Missing: [{{ .Outputs.plan.path }}]\n\n{{ .Task }}",
+		Task: "Check {{ .SpecFile }} only.",
+	}
+	pctx := PromptContext{
+		Branch:        "agtop/042",
+		UserPrompt:    "ignored",
+		SpecFile:      "specs/feat.md",
+		ModifiedFiles: []string{"a.go", "b.go"},
+		Outputs:       map[string]interface{}{"spec": map[string]interface{}{"path": "specs/feat.md"}},
+	}
+
+	result := BuildPrompt(skill, pctx)
+
+	for _, want := range []string{
+		"Work on agtop/042 using specs/feat.md.",
+		"Changed: a.go, b.go",
+		"Missing: []",
+		"Check specs/feat.md only.",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("prompt missing %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "## Context") || strings.Contains(result, "## Task") {
+		t.Error("templated skills place context themselves; default sections should be omitted")
+	}
+}
+
+func TestBuildPromptTemplateKeepsLiteralNoValue(t *testing.T) {
+	skill := &Skill{Name: "custom", Template: true, Content: "Never print <no value> on {{ .Branch }}."}
+	result := BuildPrompt(skill, PromptContext{Branch: "main"})
+	if !strings.Contains(result, "Never print <no value> on main.") {
+		t.Errorf("literal text should survive rendering, got:\n%s", result)
+	}
+}
+
+func TestBuildPromptTemplateKeepsSafety(t *testing.T) {
+	skill := &Skill{Name: "custom", Template: true, Content: "Branch {{ .Branch }}"}
+	result := BuildPrompt(skill, PromptContext{SafetyPatterns: []string{"rm -rf /"}})
+	if !strings.Contains(result, "## Safety Constraints") {
+		t.Error("templated skills must still get the safety preamble")
+	}
+}
+
+func TestBuildPromptTemplateErrorFallsBack(t *testing.T) {
+	// Ranging over a string fails at execution time.
+	skill := &Skill{Name: "custom", Template: true, Content: "{{ range .Branch }}x{{ end }}"}
+	result := BuildPrompt(skill, PromptContext{Branch: "main", UserPrompt: "do it"})
+	if !strings.Contains(result, "{{ range .Branch }}") || !strings.Contains(result, "## Task\n\ndo it") {
+		t.Errorf("expected raw body with default sections, got:\n%s", result)
+	}
+}
+
+func TestBuildPromptLiteralBraces(t *testing.T) {
+	// Without template: true, braces are plain text.
+	skill := &Skill{Name: "ci", Content: "Use `${{ secrets.TOKEN }}` in the workflow."}
+	result := BuildPrompt(skill, PromptContext{Branch: "main", UserPrompt: "add CI"})
+	if !strings.Contains(result, "Use `${{ secrets.TOKEN }}` in the workflow.") {
+		t.Errorf("literal braces should be kept verbatim, got:\n%s", result)
+	}
+	if !strings.Contains(result, "## Task\n\nadd CI") {
+		t.Errorf("expected default sections, got:\n%s", result)
+	}
+}
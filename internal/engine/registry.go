@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/cost"
 	"github.com/justinpbarnett/agtop/internal/runtime"
 )
 
@@ -216,17 +217,80 @@ func (r *Registry) Names() []string {
 // SkillForRun returns the skill and fully-resolved RunOptions for a given
 // skill name. Model resolution order: skill config → skill frontmatter →
 // runtime default. WorkDir is NOT set — the caller provides it.
-// The runtime config used depends on config.Runtime.Default.
+// The runtime config used depends on config.Runtime.Default unless the
+// skill selects its own runtime.
 func (r *Registry) SkillForRun(name string) (*Skill, runtime.RunOptions, bool) {
+	return r.SkillForWorkflow("", name)
+}
+
+// PricingWarnings returns a warning for each skill with a max_cost whose
+// model has no [pricing] rates, as its cost cannot be metered. Skills whose
+// runtime picks the model are not checked.
+func (r *Registry) PricingWarnings() []string {
+	pricing := make(cost.Pricing, len(r.cfg.Pricing))
+	for model, rates := range r.cfg.Pricing {
+		pricing[model] = cost.Rates(rates)
+	}
+	names := make([]string, 0, len(r.cfg.Skills))
+	for name, sc := range r.cfg.Skills {
+		if sc.MaxCost > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		_, opts, ok := r.SkillForRun(name)
+		if !ok || opts.Model == "" {
+			continue
+		}
+		if _, ok := pricing.Lookup(opts.Model); !ok {
+			warnings = append(warnings, fmt.Sprintf("skills.%s.max_cost: no [pricing] entry matches model %q, so it is not enforced", name, opts.Model))
+		}
+	}
+	return warnings
+}
+
+// SkillForWorkflow is SkillForRun for a skill launched by the named
+// workflow. Runtime resolution order: skill config → workflow config →
+// config.Runtime.Default. RunOptions.Runtime is set only when the skill
+// runs on something other than the default runtime.
+func (r *Registry) SkillForWorkflow(workflow, name string) (*Skill, runtime.RunOptions, bool) {
 	skill, ok := r.skills[name]
 	if !ok {
 		return nil, runtime.RunOptions{}, false
 	}
 
-	if r.cfg.Runtime.Default == "opencode" {
-		return r.skillForOpenCode(skill)
+	rtName := r.cfg.Runtime.Default
+	if wf, ok := r.cfg.Workflows[workflow]; ok && wf.Runtime != "" {
+		rtName = wf.Runtime
+	}
+	if sc, ok := r.cfg.Skills[name]; ok && sc.Runtime != "" {
+		rtName = sc.Runtime
+	}
+
+	var opts runtime.RunOptions
+	switch rtName {
+	case "opencode":
+		skill, opts, ok = r.skillForOpenCode(skill)
+	case "codex":
+		skill, opts, ok = r.skillForCodex(skill)
+	case "aider":
+		skill, opts, ok = r.skillForAider(skill)
+	default:
+		if c, custom := r.cfg.Runtime.Custom[rtName]; custom {
+			skill, opts, ok = skillForCustom(c, skill)
+		} else {
+			skill, opts, ok = r.skillForClaude(skill)
+		}
+	}
+	opts.Skill = skill.Name
+	opts.Sandbox = r.sandbox()
+	if rtName != r.cfg.Runtime.Default {
+		opts.Runtime = rtName
 	}
-	return r.skillForClaude(skill)
+	return skill, opts, ok
 }
 
 func (r *Registry) skillForClaude(skill *Skill) (*Skill, runtime.RunOptions, bool) {
@@ -261,3 +325,46 @@ func (r *Registry) skillForOpenCode(skill *Skill) (*Skill, runtime.RunOptions, b
 	}
 	return skill, opts, true
 }
+
+// skillForCodex ignores Claude shorthand models set by skills, which Codex
+// cannot serve, in favor of the configured Codex model.
+func (r *Registry) skillForCodex(skill *Skill) (*Skill, runtime.RunOptions, bool) {
+	model := r.cfg.Runtime.Codex.Model
+	if _, claude := claudeToOpenCodeModel[skill.Model]; skill.Model != "" && !claude {
+		model = skill.Model
+	}
+	return skill, runtime.RunOptions{Model: model}, true
+}
+
+func (r *Registry) skillForAider(skill *Skill) (*Skill, runtime.RunOptions, bool) {
+	model := r.cfg.Runtime.Aider.Model
+	if skill.Model != "" {
+		model = skill.Model
+	}
+	return skill, runtime.RunOptions{Model: model}, true
+}
+
+// skillForCustom fills {model} for a [runtime.custom.<name>] command.
+func skillForCustom(c config.CustomRuntimeConfig, skill *Skill) (*Skill, runtime.RunOptions, bool) {
+	model := c.Model
+	if skill.Model != "" {
+		model = skill.Model
+	}
+	return skill, runtime.RunOptions{Model: model}, true
+}
+
+// sandbox returns the configured [safety.sandbox], or nil when agents run
+// unconfined.
+func (r *Registry) sandbox() *runtime.Sandbox {
+	sc := r.cfg.Safety.Sandbox
+	if sc.Type == "" {
+		return nil
+	}
+	return &runtime.Sandbox{
+		Type:    sc.Type,
+		Network: sc.Network == nil || *sc.Network,
+		Image:   sc.Image,
+		Expose:  sc.Expose,
+		Env:     sc.Env,
+	}
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/justinpbarnett/agtop/internal/config"
@@ -491,6 +492,117 @@ Custom content.
 	}
 }
 
+func TestRegistrySkillForRunCodexAndAider(t *testing.T) {
+	tmp := t.TempDir()
+	skillsDir := filepath.Join(tmp, ".agtop", "skills")
+
+	writeSkillFile(t, skillsDir, "build", `---
+name: build
+description: Build skill
+---
+
+Build content.
+`)
+
+	cfg := testConfig()
+	cfg.Runtime.Default = "codex"
+	cfg.Runtime.Codex.Model = "gpt-5-codex"
+	cfg.Skills["review"] = config.SkillConfig{Runtime: "aider"}
+
+	reg := NewRegistry(cfg)
+	_ = reg.Load(tmp, nil)
+
+	// The build skill's "sonnet" override is a Claude model, which Codex
+	// cannot run, so the Codex model is used.
+	_, opts, ok := reg.SkillForRun("build")
+	if !ok {
+		t.Fatal("SkillForRun('build') returned false")
+	}
+	if opts.Model != "gpt-5-codex" {
+		t.Errorf("codex opts.Model = %q, want %q", opts.Model, "gpt-5-codex")
+	}
+	if opts.Runtime != "" {
+		t.Errorf("codex opts.Runtime = %q, want empty for the default runtime", opts.Runtime)
+	}
+
+	writeSkillFile(t, skillsDir, "review", `---
+name: review
+description: Review skill
+model: o3
+---
+
+Review content.
+`)
+	reg = NewRegistry(cfg)
+	_ = reg.Load(tmp, nil)
+
+	_, opts, ok = reg.SkillForRun("review")
+	if !ok {
+		t.Fatal("SkillForRun('review') returned false")
+	}
+	if opts.Runtime != "aider" {
+		t.Errorf("opts.Runtime = %q, want %q", opts.Runtime, "aider")
+	}
+	if opts.Model != "o3" {
+		t.Errorf("aider opts.Model = %q, want %q", opts.Model, "o3")
+	}
+}
+
+func TestRegistryPricingWarnings(t *testing.T) {
+	tmp := t.TempDir()
+	skillsDir := filepath.Join(tmp, ".agtop", "skills")
+	for _, name := range []string{"build", "review", "test"} {
+		writeSkillFile(t, skillsDir, name, "---\nname: "+name+"\n---\n\nContent.\n")
+	}
+
+	cfg := testConfig()
+	cfg.Runtime.Default = "codex"
+	cfg.Runtime.Codex.Model = "in-house-model"
+	cfg.Runtime.Claude.Model = "sonnet"
+	cfg.Skills = map[string]config.SkillConfig{
+		"build":  {MaxCost: 1},
+		"review": {MaxCost: 1, Runtime: "claude"},
+		"test":   {MaxTurns: 5},
+	}
+
+	reg := NewRegistry(cfg)
+	_ = reg.Load(tmp, nil)
+
+	warnings := reg.PricingWarnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `skills.build.max_cost`) || !strings.Contains(warnings[0], `"in-house-model"`) {
+		t.Errorf("expected one warning for build's unpriced model, got %q", warnings)
+	}
+}
+
+func TestRegistrySkillForRunSandbox(t *testing.T) {
+	tmp := t.TempDir()
+	writeSkillFile(t, filepath.Join(tmp, ".agtop", "skills"), "build", `---
+name: build
+description: Build skill
+---
+
+Build content.
+`)
+
+	cfg := testConfig()
+	reg := NewRegistry(cfg)
+	_ = reg.Load(tmp, nil)
+	if _, opts, _ := reg.SkillForRun("build"); opts.Sandbox != nil {
+		t.Errorf("expected no sandbox by default, got %+v", opts.Sandbox)
+	}
+
+	cfg.Safety.Sandbox.Type = "bwrap"
+	off := false
+	cfg.Safety.Sandbox.Network = &off
+	_, opts, _ := reg.SkillForRun("build")
+	if opts.Sandbox == nil || opts.Sandbox.Type != "bwrap" || opts.Sandbox.Network {
+		t.Fatalf("expected bwrap sandbox without network, got %+v", opts.Sandbox)
+	}
+	if len(opts.Sandbox.Expose) == 0 || opts.Sandbox.Expose[0] != ".claude" {
+		t.Errorf("expected default exposed paths, got %v", opts.Sandbox.Expose)
+	}
+}
+
 func TestRegistrySkillForRunOpenCode(t *testing.T) {
 	tmp := t.TempDir()
 	skillsDir := filepath.Join(tmp, ".agtop", "skills")
@@ -743,3 +855,43 @@ Custom content.
 		t.Errorf("opts.Agent = %q, want %q", opts.Agent, "build")
 	}
 }
+
+func TestRegistrySkillForWorkflowRuntime(t *testing.T) {
+	tmp := t.TempDir()
+	skillsDir := filepath.Join(tmp, ".agtop", "skills")
+	for _, name := range []string{"build", "review", "spec"} {
+		writeSkillFile(t, skillsDir, name, "---\nname: "+name+"\ndescription: "+name+"\n---\n\nContent.\n")
+	}
+
+	cfg := testConfig()
+	cfg.Runtime.OpenCode.Agent = "code"
+	cfg.Skills["review"] = config.SkillConfig{Model: "opus", Runtime: "opencode"}
+	cfg.Skills["spec"] = config.SkillConfig{Model: "opus", Runtime: "claude"}
+	cfg.Workflows["mixed"] = config.WorkflowConfig{Skills: []string{"spec", "build", "review"}, Runtime: "opencode"}
+
+	reg := NewRegistry(cfg)
+	_ = reg.Load(tmp, nil)
+
+	tests := []struct {
+		workflow, skill string
+		wantRuntime     string
+		wantModel       string
+	}{
+		{"build", "build", "", "sonnet"},
+		{"build", "review", "opencode", "anthropic/claude-opus-4-6"},
+		{"mixed", "build", "opencode", "anthropic/claude-sonnet-4-6"},
+		{"mixed", "spec", "", "opus"},
+	}
+	for _, tt := range tests {
+		_, opts, ok := reg.SkillForWorkflow(tt.workflow, tt.skill)
+		if !ok {
+			t.Fatalf("SkillForWorkflow(%q, %q) returned false", tt.workflow, tt.skill)
+		}
+		if opts.Runtime != tt.wantRuntime {
+			t.Errorf("%s/%s: opts.Runtime = %q, want %q", tt.workflow, tt.skill, opts.Runtime, tt.wantRuntime)
+		}
+		if opts.Model != tt.wantModel {
+			t.Errorf("%s/%s: opts.Model = %q, want %q", tt.workflow, tt.skill, opts.Model, tt.wantModel)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/justinpbarnett/agtop/internal/schema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -27,18 +28,24 @@ type Skill struct {
 	Timeout      int
 	Parallel     bool
 	AllowedTools []string
-	Content      string // Full markdown body (everything after frontmatter)
-	Source       string // Filesystem path where this skill was loaded from
-	Priority     int    // Precedence level (0 = highest, 5 = lowest)
+	Task         string         // Fixed task used instead of the user's prompt
+	Template     bool           // Content and Task are Go templates rendered with the run context
+	Outputs      *schema.Schema // JSON schema the skill's result must match (nil = free text)
+	Content      string         // Full markdown body (everything after frontmatter)
+	Source       string         // Filesystem path where this skill was loaded from
+	Priority     int            // Precedence level (0 = highest, 5 = lowest)
 }
 
 type skillFrontmatter struct {
-	Name          string   `yaml:"name"`
-	Description   string   `yaml:"description"`
-	Model         string   `yaml:"model"`
-	Timeout       int      `yaml:"timeout"`
-	ParallelGroup string   `yaml:"parallel-group"`
-	AllowedTools  []string `yaml:"allowed-tools"`
+	Name          string      `yaml:"name"`
+	Description   string      `yaml:"description"`
+	Model         string      `yaml:"model"`
+	Timeout       int         `yaml:"timeout"`
+	ParallelGroup string      `yaml:"parallel-group"`
+	AllowedTools  []string    `yaml:"allowed-tools"`
+	Task          string      `yaml:"task"`
+	Template      bool        `yaml:"template"`
+	Outputs       interface{} `yaml:"outputs"`
 }
 
 // ParseSkill parses a SKILL.md file's content into a Skill struct.
@@ -90,6 +97,15 @@ func ParseSkill(data []byte, source string, priority int) (*Skill, error) {
 		skill.Model = fm.Model
 		skill.Timeout = fm.Timeout
 		skill.AllowedTools = fm.AllowedTools
+		skill.Task = strings.TrimSpace(fm.Task)
+		skill.Template = fm.Template
+		if fm.Outputs != nil {
+			outputs, err := schema.Parse(fm.Outputs)
+			if err != nil {
+				return nil, fmt.Errorf("parse outputs in %s: %w", source, err)
+			}
+			skill.Outputs = outputs
+		}
 		skill.Content = strings.TrimSpace(body)
 	} else {
 		skill.Content = strings.TrimSpace(trimmed)
@@ -99,6 +115,15 @@ func ParseSkill(data []byte, source string, priority int) (*Skill, error) {
 		skill.Name = SkillNameFromPath(source)
 	}
 
+	if skill.Template {
+		if _, err := parseTemplate(skill.Name, skill.Content); err != nil {
+			return nil, fmt.Errorf("parse template in %s: %w", source, err)
+		}
+		if _, err := parseTemplate(skill.Name+" task", skill.Task); err != nil {
+			return nil, fmt.Errorf("parse task template in %s: %w", source, err)
+		}
+	}
+
 	return skill, nil
 }
 
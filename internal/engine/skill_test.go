@@ -220,3 +220,66 @@ func searchString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestParseSkillWithOutputs(t *testing.T) {
+	data := []byte(`---
+name: spec
+outputs:
+  type: object
+  required: [path]
+  properties:
+    path:
+      type: string
+---
+Write a spec.
+`)
+	skill, err := ParseSkill(data, "skills/spec/SKILL.md", PriorityBuiltIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skill.Outputs == nil {
+		t.Fatal("expected outputs schema")
+	}
+	if err := skill.Outputs.Validate(map[string]interface{}{"path": "specs/a.md"}); err != nil {
+		t.Errorf("valid output rejected: %v", err)
+	}
+	if err := skill.Outputs.Validate(map[string]interface{}{}); err == nil {
+		t.Error("expected missing path to be rejected")
+	}
+}
+
+func TestParseSkillWithInvalidOutputs(t *testing.T) {
+	data := []byte("---\nname: spec\noutputs:\n  type: widget\n---\nbody\n")
+	if _, err := ParseSkill(data, "skills/spec/SKILL.md", PriorityBuiltIn); err == nil {
+		t.Fatal("expected error for invalid outputs schema")
+	}
+}
+
+func TestParseSkillWithTask(t *testing.T) {
+	data := []byte("---\nname: lint\ntask: >\n  Run the linters on {{ .Branch }}.\n---\nbody\n")
+	skill, err := ParseSkill(data, "skills/lint/SKILL.md", PriorityBuiltIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skill.Task != "Run the linters on {{ .Branch }}." {
+		t.Errorf("Task = %q", skill.Task)
+	}
+}
+
+func TestParseSkillWithInvalidTemplate(t *testing.T) {
+	data := []byte("---\nname: custom\ntemplate: true\n---\nWork on {{ .Branch }\n")
+	if _, err := ParseSkill(data, "skills/custom/SKILL.md", PriorityBuiltIn); err == nil {
+		t.Fatal("expected error for malformed template")
+	}
+}
+
+func TestParseSkillWithLiteralBraces(t *testing.T) {
+	data := []byte("---\nname: ci\n---\nSet `token: ${{ secrets.TOKEN }}` and `{{ .Values.image }}`.\n")
+	skill, err := ParseSkill(data, "skills/ci/SKILL.md", PriorityBuiltIn)
+	if err != nil {
+		t.Fatalf("a skill that is not a template should load: %v", err)
+	}
+	if skill.Template {
+		t.Error("Template should default to false")
+	}
+}
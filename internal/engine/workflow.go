@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/justinpbarnett/agtop/internal/config"
 )
@@ -12,16 +13,85 @@ type Workflow struct {
 }
 
 // ResolveWorkflow returns the ordered list of skill names for a workflow.
+// Entries like "workflow:plan-build" are expanded inline, and graph workflows
+// are flattened into topological order.
 // Returns an error if the workflow name is not found in config.
 func ResolveWorkflow(cfg *config.Config, workflowName string) ([]string, error) {
 	wf, ok := cfg.Workflows[workflowName]
 	if !ok {
 		return nil, fmt.Errorf("unknown workflow: %q", workflowName)
 	}
-	if len(wf.Skills) == 0 {
+	if len(wf.Steps) > 0 {
+		g, err := ResolveGraph(cfg, workflowName)
+		if err != nil {
+			return nil, err
+		}
+		return g.Skills(), nil
+	}
+	skills, err := expandWorkflow(cfg, workflowName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(skills) == 0 {
 		return nil, fmt.Errorf("workflow %q has no skills", workflowName)
 	}
-	return wf.Skills, nil
+	return skills, nil
+}
+
+// expandWorkflow flattens a workflow's skills, expanding included workflows.
+// stack holds the workflows being expanded and guards against cycles that
+// slipped past config validation.
+func expandWorkflow(cfg *config.Config, workflowName string, stack []string) ([]string, error) {
+	for _, name := range stack {
+		if name == workflowName {
+			return nil, fmt.Errorf("workflow %q includes itself: %s", workflowName, strings.Join(append(stack, workflowName), " -> "))
+		}
+	}
+	wf, ok := cfg.Workflows[workflowName]
+	if !ok {
+		return nil, fmt.Errorf("unknown workflow: %q", workflowName)
+	}
+	if len(wf.Steps) > 0 && len(stack) > 0 {
+		return nil, fmt.Errorf("workflow %q cannot include graph workflow %q", stack[len(stack)-1], workflowName)
+	}
+
+	stack = append(stack, workflowName)
+	var skills []string
+	for _, entry := range wf.Skills {
+		ref, ok := config.WorkflowRef(entry)
+		if !ok {
+			skills = append(skills, entry)
+			continue
+		}
+		included, err := expandWorkflow(cfg, ref, stack)
+		if err != nil {
+			return nil, err
+		}
+		skills = append(skills, included...)
+	}
+	return skills, nil
+}
+
+// IsGraphWorkflow reports whether a workflow is declared with steps.
+func IsGraphWorkflow(cfg *config.Config, workflowName string) bool {
+	wf, ok := cfg.Workflows[workflowName]
+	return ok && len(wf.Steps) > 0
+}
+
+// ResolveGraph returns the step graph for a workflow declared with steps.
+func ResolveGraph(cfg *config.Config, workflowName string) (*Graph, error) {
+	wf, ok := cfg.Workflows[workflowName]
+	if !ok {
+		return nil, fmt.Errorf("unknown workflow: %q", workflowName)
+	}
+	if len(wf.Steps) == 0 {
+		return nil, fmt.Errorf("workflow %q has no steps", workflowName)
+	}
+	g, err := NewGraph(wf.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("workflow %q: %w", workflowName, err)
+	}
+	return g, nil
 }
 
 // ValidateWorkflow checks that every skill in the workflow is available
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	gitpkg "github.com/justinpbarnett/agtop/internal/git"
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+// CreateWorktree creates the worktree of a new run, or one worktree per repo
+// when [[repos]] is configured, and records the path and branch on the run.
+// If creation fails, the run is marked failed.
+func CreateWorktree(wt *gitpkg.WorktreeManager, store *run.Store, runID string, repos []config.RepoConfig) error {
+	err := createWorktree(wt, store, runID, repos)
+	if err != nil {
+		err = fmt.Errorf("worktree create: %w", err)
+		store.Update(runID, func(r *run.Run) {
+			r.State = run.StateFailed
+			r.Error = err.Error()
+		})
+	}
+	return err
+}
+
+func createWorktree(wt *gitpkg.WorktreeManager, store *run.Store, runID string, repos []config.RepoConfig) error {
+	if len(repos) > 0 {
+		result, err := wt.CreateMulti(runID, repos)
+		if err != nil {
+			return err
+		}
+		store.Update(runID, func(r *run.Run) {
+			r.Worktree = result.RootPath
+			r.Branch = result.Branch
+			r.SubWorktrees = make([]run.SubWorktreeInfo, len(result.SubWorktrees))
+			for i, sw := range result.SubWorktrees {
+				r.SubWorktrees[i] = run.SubWorktreeInfo{
+					Name:     sw.Name,
+					Path:     sw.Path,
+					RepoRoot: sw.RepoRoot,
+				}
+			}
+		})
+		return nil
+	}
+
+	wtPath, branch, err := wt.Create(runID)
+	if err != nil {
+		return err
+	}
+	store.Update(runID, func(r *run.Run) {
+		r.Worktree = wtPath
+		r.Branch = branch
+	})
+	return nil
+}
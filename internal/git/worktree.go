@@ -90,6 +90,36 @@ func DiscoverRepos(projectRoot string) ([]string, error) {
 	return repos, nil
 }
 
+// ProjectRepos returns the git repos of a project: the configured repos,
+// resolved relative to projectRoot, or the ones DiscoverRepos finds.
+func ProjectRepos(projectRoot string, configured []string) []string {
+	if len(configured) == 0 {
+		repos, _ := DiscoverRepos(projectRoot)
+		return repos
+	}
+	repos := make([]string, 0, len(configured))
+	for _, r := range configured {
+		if filepath.IsAbs(r) {
+			repos = append(repos, r)
+		} else {
+			repos = append(repos, filepath.Join(projectRoot, r))
+		}
+	}
+	return repos
+}
+
+// NewProjectWorktreeManager creates the WorktreeManager for a project's
+// repos, as returned by ProjectRepos. With no repos it manages projectRoot.
+func NewProjectWorktreeManager(projectRoot string, repos []string, worktreePath string) *WorktreeManager {
+	switch len(repos) {
+	case 0:
+		return NewWorktreeManagerAt(projectRoot, worktreePath)
+	case 1:
+		return NewWorktreeManagerAt(repos[0], worktreePath)
+	}
+	return NewMultiRepoWorktreeManager(projectRoot, repos)
+}
+
 // NewWorktreeManager creates a WorktreeManager for a single git repo.
 func NewWorktreeManager(repoRoot string) *WorktreeManager {
 	return NewWorktreeManagerAt(repoRoot, "")
@@ -696,3 +696,23 @@ func TestWorktreeExists(t *testing.T) {
 		t.Error("Exists returned false for existing worktree")
 	}
 }
+
+func TestProjectRepos(t *testing.T) {
+	root := t.TempDir()
+	got := ProjectRepos(root, []string{"api", "/abs/web"})
+	want := []string{filepath.Join(root, "api"), "/abs/web"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("configured repos = %v, want %v", got, want)
+	}
+
+	repo := initTestRepo(t)
+	if got := ProjectRepos(repo, nil); len(got) != 1 || got[0] != repo {
+		t.Errorf("discovered repos = %v, want [%s]", got, repo)
+	}
+	if wm := NewProjectWorktreeManager(repo, []string{repo}, ""); wm.IsMultiRepo() || wm.RepoRoot() != repo {
+		t.Errorf("expected a single-repo manager for %s", repo)
+	}
+	if wm := NewProjectWorktreeManager(root, []string{repo, root}, ""); !wm.IsMultiRepo() {
+		t.Error("expected a multi-repo manager for two repos")
+	}
+}
@@ -0,0 +1,125 @@
+package process
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamStderr marks event records of lines a process wrote to stderr.
+const StreamStderr = "stderr"
+
+// EventRecord is one line of a run's event log: a normalized stream event
+// with the time it was received and the skill that produced it.
+type EventRecord struct {
+	Time      time.Time       `json:"time"`
+	Skill     string          `json:"skill,omitempty"`
+	TaskID    string          `json:"task_id,omitempty"` // parallel sub-task run that emitted the event
+	Stream    string          `json:"stream,omitempty"`  // StreamStderr for stderr lines; empty for stream events
+	Type      StreamEventType `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	Tool      string          `json:"tool,omitempty"`
+	ToolInput string          `json:"tool_input,omitempty"`
+	ToolID    string          `json:"tool_id,omitempty"`
+	ParentID  string          `json:"parent_id,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+	Elapsed   time.Duration   `json:"elapsed,omitempty"`
+	Usage     *UsageData      `json:"usage,omitempty"`
+}
+
+// NewEventRecord records event as received at at while skill ran.
+func NewEventRecord(event StreamEvent, at time.Time, skill string) EventRecord {
+	return EventRecord{
+		Time:      at,
+		Skill:     skill,
+		Type:      event.Type,
+		Text:      event.Text,
+		Tool:      event.ToolName,
+		ToolInput: event.ToolInput,
+		ToolID:    event.ToolID,
+		ParentID:  event.ParentID,
+		IsError:   event.IsError,
+		Elapsed:   event.Elapsed,
+		Usage:     event.Usage,
+	}
+}
+
+// Event returns the stream event the record holds.
+func (rec EventRecord) Event() StreamEvent {
+	return StreamEvent{
+		Type:      rec.Type,
+		Text:      rec.Text,
+		ToolName:  rec.Tool,
+		ToolInput: rec.ToolInput,
+		ToolID:    rec.ToolID,
+		ParentID:  rec.ParentID,
+		IsError:   rec.IsError,
+		Elapsed:   rec.Elapsed,
+		Usage:     rec.Usage,
+	}
+}
+
+// EventLogPath returns the path of a run's event log in sessionsDir. A
+// parallel sub-task ("<run>:<task>") shares the log of its parent run.
+func EventLogPath(sessionsDir, runID string) string {
+	if i := strings.IndexByte(runID, ':'); i >= 0 {
+		runID = runID[:i]
+	}
+	return filepath.Join(sessionsDir, runID+".events.jsonl")
+}
+
+// EventLog appends records to an event log file, one JSON object per line.
+// It is safe for concurrent use.
+type EventLog struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// OpenEventLog opens the event log at path for appending, creating it if
+// needed.
+func OpenEventLog(path string) (*EventLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &EventLog{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Append writes rec as the next line of the log.
+func (l *EventLog) Append(rec EventRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(rec)
+}
+
+func (l *EventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// ReadEventLog reads every record of the event log at path. Lines that do
+// not parse, such as one cut short by a crash, are skipped.
+func ReadEventLog(path string) ([]EventRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var recs []EventRecord
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for sc.Scan() {
+		var rec EventRecord
+		if json.Unmarshal(sc.Bytes(), &rec) == nil && rec.Type != "" {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, sc.Err()
+}
@@ -0,0 +1,77 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEventLogRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "001.events.jsonl")
+	el, err := OpenEventLog(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	at := time.Date(2026, 3, 1, 14, 2, 3, 0, time.UTC)
+	events := []StreamEvent{
+		{Type: EventToolUse, ToolName: "Read", ToolInput: `{"file_path":"main.go"}`, ToolID: "toolu_1"},
+		{Type: EventToolResult, Text: "package main", ToolID: "toolu_1", ParentID: "toolu_0", IsError: true, Elapsed: 1500 * time.Millisecond},
+		{Type: EventResult, Text: "done", Usage: &UsageData{InputTokens: 100, OutputTokens: 50, TotalTokens: 150, CacheReadTokens: 800, CostUSD: 0.02}},
+	}
+	for _, ev := range events {
+		if err := el.Append(NewEventRecord(ev, at, "build")); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	el.Close()
+
+	recs, err := ReadEventLog(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(recs) != len(events) {
+		t.Fatalf("expected %d records, got %d", len(events), len(recs))
+	}
+	for i, rec := range recs {
+		if !rec.Time.Equal(at) || rec.Skill != "build" {
+			t.Errorf("record %d: expected time %v and skill build, got %v and %q", i, at, rec.Time, rec.Skill)
+		}
+		got, want := rec.Event(), events[i]
+		if got.Type != want.Type || got.Text != want.Text || got.ToolName != want.ToolName ||
+			got.ToolInput != want.ToolInput || got.ToolID != want.ToolID || got.ParentID != want.ParentID ||
+			got.IsError != want.IsError || got.Elapsed != want.Elapsed {
+			t.Errorf("record %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+	if u := recs[2].Usage; u == nil || *u != *events[2].Usage {
+		t.Errorf("expected usage %+v, got %+v", events[2].Usage, u)
+	}
+}
+
+func TestReadEventLogSkipsTruncatedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "001.events.jsonl")
+	data := `{"time":"2026-03-01T14:02:03Z","type":"text","text":"hello"}` + "\n" +
+		`{"time":"2026-03-01T14:02:04Z","type":"tool_u`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := ReadEventLog(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Text != "hello" {
+		t.Errorf("expected only the complete record, got %+v", recs)
+	}
+}
+
+func TestEventLogPath(t *testing.T) {
+	if got := EventLogPath("/s", "001"); got != filepath.Join("/s", "001.events.jsonl") {
+		t.Errorf("unexpected path %q", got)
+	}
+	if got := EventLogPath("/s", "001:task-2"); got != filepath.Join("/s", "001.events.jsonl") {
+		t.Errorf("expected sub-tasks to share the parent's log, got %q", got)
+	}
+}
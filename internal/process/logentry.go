@@ -19,6 +19,7 @@ type LogEntry struct {
 	Summary   string
 	Detail    string
 	Complete  bool
+	Depth     int // nesting level; 1 for events of a Task sub-agent
 }
 
 // NewLogEntry creates a LogEntry with an auto-generated summary based on event type.
@@ -47,6 +48,8 @@ func NewLogEntry(ts, skill string, eventType StreamEventType, detail string) *Lo
 		e.Summary = "ERROR: " + truncateLine(firstLine(detail), 60)
 	case EventUser:
 		e.Summary = "User: " + truncateLine(firstLine(detail), 70)
+	case EventThinking:
+		e.Summary = "Thinking: " + truncateLine(firstLine(detail), 70)
 	default:
 		e.Summary = truncateLine(firstLine(detail), 80)
 	}
@@ -320,6 +320,29 @@ func TestLineToEntryNoSkill(t *testing.T) {
 	}
 }
 
+func TestLineToEntryThinking(t *testing.T) {
+	e := lineToEntry("[14:32:05 build] Thinking: The failing test is flaky")
+	if e.Type != EventThinking {
+		t.Errorf("expected EventThinking, got %v", e.Type)
+	}
+	if e.Summary != "Thinking: The failing test is flaky" {
+		t.Errorf("unexpected summary: %q", e.Summary)
+	}
+}
+
+func TestLineToEntrySubAgent(t *testing.T) {
+	e := lineToEntry("[14:32:05 build] ↳ Tool: Grep")
+	if e.Type != EventToolUse {
+		t.Errorf("expected EventToolUse, got %v", e.Type)
+	}
+	if e.Depth != 1 {
+		t.Errorf("expected depth 1, got %d", e.Depth)
+	}
+	if e.Summary != "Tool: Grep" {
+		t.Errorf("unexpected summary: %q", e.Summary)
+	}
+}
+
 func TestLineToEntryRateLimit(t *testing.T) {
 	e := lineToEntry("[14:32:01 build] RATE LIMITED: 429 Too Many Requests")
 	if e != nil {
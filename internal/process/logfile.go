@@ -129,8 +129,12 @@ func (lf *LogFiles) Close() error {
 	return firstErr
 }
 
-// RemoveFiles deletes both log files from disk.
-func RemoveLogFiles(stdoutPath, stderrPath string) {
-	os.Remove(stdoutPath)
-	os.Remove(stderrPath)
+// RemoveLogFiles deletes a run's log files from disk. Empty paths are
+// skipped.
+func RemoveLogFiles(paths ...string) {
+	for _, p := range paths {
+		if p != "" {
+			os.Remove(p)
+		}
+	}
 }
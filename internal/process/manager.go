@@ -2,8 +2,10 @@ package process
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -43,29 +45,45 @@ var ErrDisconnected = fmt.Errorf("disconnected: TUI shutting down")
 type SkillResult struct {
 	ResultText string // Final result text from the stream-json "result" event
 	Err        error  // Non-nil if the process exited with error
+	Partial    bool   // The skill was stopped at its cost or turn limit
 }
 
 type ManagedProcess struct {
-	proc   *runtime.Process // nil for reconnected processes
-	cancel context.CancelFunc
-	runID  string
-	pid    int // always set — used for signal-based control of reconnected processes
+	proc        *runtime.Process // nil for reconnected processes
+	cancel      context.CancelFunc
+	runID       string
+	pid         int             // always set — used for signal-based control of reconnected processes
+	container   string          // docker sandbox container, controlled through docker instead of pid
+	rt          runtime.Runtime // runtime that started proc
+	runtimeName string          // selects the stream parser for proc's output
+	pending     int             // messages sent to proc's stdin not yet answered; guarded by Manager.mu
+	sendMu      sync.Mutex      // serializes writes to proc's stdin
+	skip        int             // leading stdout events already in the event log (reconnected processes)
+	skipStderr  int             // leading stderr lines already in the event log (reconnected processes)
+	model       string          // model proc was started with, for pricing its usage
+	meter       skillMeter      // progress of a skill process against its limit
 }
 
 type Manager struct {
 	store         *run.Store
 	rt            runtime.Runtime
 	runtimeName   string
+	runtimes      map[string]runtime.Runtime
 	sessionsDir   string
 	cfg           *config.LimitsConfig
 	tracker       *cost.Tracker
 	limiter       *cost.LimitChecker
+	pricing       cost.Pricing
+	skillLimits   map[string]cost.SkillLimit
+	ledger        *cost.Ledger
 	safety        *safety.PatternMatcher
 	mu            sync.Mutex
 	disconnecting bool
 	processes     map[string]*ManagedProcess
 	buffers       map[string]*RingBuffer
 	entryBuffers  map[string]*EntryBuffer
+	timelines     map[string]*Timeline
+	eventLogs     map[string]*EventLog // by path; sub-tasks share their parent's
 	logFiles      map[string]*LogFiles
 	program       *tea.Program
 }
@@ -75,6 +93,7 @@ func NewManager(store *run.Store, rt runtime.Runtime, runtimeName string, sessio
 		store:        store,
 		rt:           rt,
 		runtimeName:  runtimeName,
+		runtimes:     map[string]runtime.Runtime{runtimeName: rt},
 		sessionsDir:  sessionsDir,
 		cfg:          cfg,
 		tracker:      tracker,
@@ -83,13 +102,161 @@ func NewManager(store *run.Store, rt runtime.Runtime, runtimeName string, sessio
 		processes:    make(map[string]*ManagedProcess),
 		buffers:      make(map[string]*RingBuffer),
 		entryBuffers: make(map[string]*EntryBuffer),
+		timelines:    make(map[string]*Timeline),
+		eventLogs:    make(map[string]*EventLog),
 		logFiles:     make(map[string]*LogFiles),
 	}
 }
 
-func (m *Manager) newParser(r io.Reader, bufSize int) EventStream {
-	if m.runtimeName == "opencode" {
+// AddRuntime makes another runtime available to skills and workflows that
+// select it with RunOptions.Runtime. The runtime passed to NewManager stays
+// the default.
+func (m *Manager) AddRuntime(name string, rt runtime.Runtime) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runtimes[name] = rt
+}
+
+// SetPricing sets the model rates used to price usage the runtime reports
+// no cost for.
+func (m *Manager) SetPricing(pricing map[string]config.PricingConfig) {
+	p := make(cost.Pricing, len(pricing))
+	for model, rates := range pricing {
+		p[model] = cost.Rates(rates)
+	}
+	m.mu.Lock()
+	m.pricing = p
+	m.mu.Unlock()
+}
+
+// SetLedger sets the ledger that usage costs are recorded in and that the
+// limiter's budget is checked against.
+func (m *Manager) SetLedger(l *cost.Ledger) {
+	m.mu.Lock()
+	m.ledger = l
+	m.mu.Unlock()
+}
+
+// Ledger returns the spend ledger, or nil if none is set.
+func (m *Manager) Ledger() *cost.Ledger {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ledger
+}
+
+// CheckBudget returns an error once spend has neared a budget cap. New runs
+// are refused while it does.
+func (m *Manager) CheckBudget() error {
+	spend, ok := m.budgetSpend()
+	if !ok {
+		return nil
+	}
+	if refused, reason := m.limiter.Budget.CheckNew(spend); refused {
+		return errors.New(reason)
+	}
+	return nil
+}
+
+// budgetReached returns an error once spend has reached a budget cap.
+func (m *Manager) budgetReached() error {
+	spend, ok := m.budgetSpend()
+	if !ok {
+		return nil
+	}
+	if exceeded, reason := m.limiter.Budget.Check(spend); exceeded {
+		return errors.New(reason)
+	}
+	return nil
+}
+
+// budgetSpend returns the ledger's spend, reporting false when no budget
+// applies or the ledger cannot be read.
+func (m *Manager) budgetSpend() (cost.Spend, bool) {
+	ledger := m.Ledger()
+	if ledger == nil || m.limiter == nil || !m.limiter.Budget.Enabled() {
+		return cost.Spend{}, false
+	}
+	spend, err := ledger.Spend(time.Now())
+	if err != nil {
+		log.Printf("warning: read spend ledger: %v", err)
+		return cost.Spend{}, false
+	}
+	return spend, true
+}
+
+// runtimeFor returns the runtime registered under name, or the default
+// runtime when name is empty.
+func (m *Manager) runtimeFor(name string) (runtime.Runtime, string, error) {
+	if name == "" {
+		return m.rt, m.runtimeName, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rt, ok := m.runtimes[name]
+	if !ok {
+		return nil, "", fmt.Errorf("runtime %q is not available", name)
+	}
+	return rt, name, nil
+}
+
+// parserRuntime returns the runtime whose output format a run's logs use.
+func (m *Manager) parserRuntime(runID string) string {
+	if r, ok := m.store.Get(runID); ok && r.Runtime != "" {
+		return r.Runtime
+	}
+	return m.runtimeName
+}
+
+// logSegments returns the skills' parts of a run's stdout log. Runs saved
+// before segments were recorded get a single part parsed with the run's
+// runtime.
+func (m *Manager) logSegments(runID string) []run.LogSegment {
+	r, ok := m.store.Get(runID)
+	if ok && len(r.LogSegments) > 0 {
+		return append([]run.LogSegment(nil), r.LogSegments...)
+	}
+	return []run.LogSegment{{Skill: r.CurrentSkill, Runtime: m.parserRuntime(runID)}}
+}
+
+// replaySegment parses the part of a stdout log that seg's skill wrote and
+// calls fn with each event. end is the offset where the next part starts.
+func (m *Manager) replaySegment(data []byte, seg run.LogSegment, end int64, fn func(StreamEvent)) {
+	start := seg.Offset
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+	if start > end {
+		return
+	}
+	parser := newParser(m.protocol(seg.Runtime), bytes.NewReader(data[start:end]), 256)
+	go parser.Parse(context.Background())
+	for event := range parser.Events() {
+		fn(event)
+	}
+}
+
+// protocol returns the output protocol of the named runtime. Built-in
+// runtimes are named after their protocol; custom runtimes report theirs.
+func (m *Manager) protocol(runtimeName string) string {
+	m.mu.Lock()
+	rt := m.runtimes[runtimeName]
+	m.mu.Unlock()
+	if pr, ok := rt.(runtime.ProtocolRuntime); ok {
+		return pr.Protocol()
+	}
+	return runtimeName
+}
+
+func newParser(protocol string, r io.Reader, bufSize int) EventStream {
+	switch protocol {
+	case runtime.ProtocolOpenCode:
 		return NewOpenCodeStreamParser(r, bufSize)
+	case runtime.ProtocolCodex:
+		return NewCodexStreamParser(r, bufSize)
+	case runtime.ProtocolAider:
+		return NewAiderStreamParser(r, bufSize)
+	case runtime.ProtocolText:
+		return NewTextStreamParser(r, bufSize)
 	}
 	return NewStreamParser(r, bufSize)
 }
@@ -111,20 +278,29 @@ type processResources struct {
 // launchProcess creates log files, starts the subprocess, and wires up output
 // readers. On failure all allocated resources are cleaned up before returning.
 func (m *Manager) launchProcess(runID string, prompt string, opts runtime.RunOptions) (*processResources, error) {
+	rt, rtName, err := m.runtimeFor(opts.Runtime)
+	if err != nil {
+		return nil, err
+	}
+
 	var lf *LogFiles
+	// Earlier skills of the run have written to the same log files; this
+	// skill's output starts where theirs ends.
+	var stdoutOffset, stderrOffset int64
 	if m.sessionsDir != "" {
-		var err error
 		lf, err = CreateLogFiles(m.sessionsDir, runID)
 		if err != nil {
 			log.Printf("warning: create log files for %s: %v (falling back to pipes)", runID, err)
 		} else {
 			opts.StdoutFile = lf.StdoutWriter()
 			opts.StderrFile = lf.StderrWriter()
+			stdoutOffset, _ = lf.StdoutWriter().Seek(0, io.SeekEnd)
+			stderrOffset, _ = lf.StderrWriter().Seek(0, io.SeekEnd)
 		}
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	proc, err := m.rt.Start(ctx, prompt, opts)
+	proc, err := rt.Start(ctx, prompt, opts)
 	if err != nil {
 		cancel()
 		if lf != nil {
@@ -133,6 +309,13 @@ func (m *Manager) launchProcess(runID string, prompt string, opts runtime.RunOpt
 		return nil, fmt.Errorf("start process: %w", err)
 	}
 
+	if lf != nil {
+		seg := run.LogSegment{Offset: stdoutOffset, Skill: opts.Skill, Runtime: rtName, StartedAt: time.Now()}
+		m.store.Update(runID, func(r *run.Run) {
+			r.LogSegments = append(r.LogSegments, seg)
+		})
+	}
+
 	var stdoutReader, stderrReader io.Reader
 	if lf != nil {
 		stdoutR, err := os.Open(lf.StdoutPath())
@@ -148,6 +331,8 @@ func (m *Manager) launchProcess(runID string, prompt string, opts runtime.RunOpt
 			lf.Close()
 			return nil, fmt.Errorf("open stderr log for reading: %w", err)
 		}
+		_, _ = stdoutR.Seek(stdoutOffset, io.SeekStart)
+		_, _ = stderrR.Seek(stderrOffset, io.SeekStart)
 		stdoutReader = NewFollowReader(ctx, stdoutR)
 		stderrReader = NewFollowReader(ctx, stderrR)
 	} else {
@@ -155,8 +340,13 @@ func (m *Manager) launchProcess(runID string, prompt string, opts runtime.RunOpt
 		stderrReader = proc.Stderr
 	}
 
+	mp := &ManagedProcess{proc: proc, cancel: cancel, runID: runID, pid: proc.PID, container: proc.Container, rt: rt, runtimeName: rtName, model: opts.Model}
+	if proc.Stdin != nil {
+		// The prompt is the first message awaiting an answer.
+		mp.pending = 1
+	}
 	return &processResources{
-		mp:           &ManagedProcess{proc: proc, cancel: cancel, runID: runID, pid: proc.PID},
+		mp:           mp,
 		stdoutReader: stdoutReader,
 		stderrReader: stderrReader,
 		lf:           lf,
@@ -182,11 +372,13 @@ func (m *Manager) Start(runID string, prompt string, opts runtime.RunOptions) er
 
 	buf := NewRingBuffer(10000)
 	eb := NewEntryBuffer(5000)
+	tl := NewTimeline()
 
 	m.mu.Lock()
 	m.processes[runID] = res.mp
 	m.buffers[runID] = buf
 	m.entryBuffers[runID] = eb
+	m.timelines[runID] = tl
 	if res.lf != nil {
 		m.logFiles[runID] = res.lf
 	}
@@ -195,10 +387,12 @@ func (m *Manager) Start(runID string, prompt string, opts runtime.RunOptions) er
 	m.store.Update(runID, func(r *run.Run) {
 		r.State = run.StateRunning
 		r.PID = res.mp.pid
+		r.Container = res.mp.container
+		r.Runtime = res.mp.runtimeName
 		r.StartedAt = time.Now()
 	})
 
-	go m.consumeEvents(runID, res.mp, buf, eb, res.stdoutReader, res.stderrReader, res.mp.proc.Done)
+	go m.consumeEvents(runID, res.mp, buf, eb, tl, res.stdoutReader, res.stderrReader, res.mp.proc.Done)
 
 	return nil
 }
@@ -212,14 +406,14 @@ func (m *Manager) Stop(runID string) error {
 	}
 
 	if mp.proc != nil {
-		if err := m.rt.Stop(mp.proc); err != nil {
+		if err := mp.rt.Stop(mp.proc); err != nil {
 			return err
 		}
 	} else if mp.pid > 0 {
-		_ = syscall.Kill(mp.pid, syscall.SIGTERM)
+		_ = runtime.Signal(mp.pid, mp.container, syscall.SIGTERM)
 		go func() {
 			time.Sleep(5 * time.Second)
-			_ = syscall.Kill(mp.pid, syscall.SIGKILL)
+			_ = runtime.Signal(mp.pid, mp.container, syscall.SIGKILL)
 		}()
 	}
 	mp.cancel()
@@ -235,11 +429,11 @@ func (m *Manager) Pause(runID string) error {
 	}
 
 	if mp.proc != nil {
-		if err := m.rt.Pause(mp.proc); err != nil {
+		if err := mp.rt.Pause(mp.proc); err != nil {
 			return err
 		}
 	} else if mp.pid > 0 {
-		_ = syscall.Kill(mp.pid, syscall.SIGSTOP)
+		_ = runtime.Signal(mp.pid, mp.container, syscall.SIGSTOP)
 	}
 	m.store.Update(runID, func(r *run.Run) {
 		r.State = run.StatePaused
@@ -256,11 +450,11 @@ func (m *Manager) Resume(runID string) error {
 	}
 
 	if mp.proc != nil {
-		if err := m.rt.Resume(mp.proc); err != nil {
+		if err := mp.rt.Resume(mp.proc); err != nil {
 			return err
 		}
 	} else if mp.pid > 0 {
-		_ = syscall.Kill(mp.pid, syscall.SIGCONT)
+		_ = runtime.Signal(mp.pid, mp.container, syscall.SIGCONT)
 	}
 	m.store.Update(runID, func(r *run.Run) {
 		r.State = run.StateRunning
@@ -276,15 +470,29 @@ func (m *Manager) Kill(runID string) error {
 		return fmt.Errorf("no active process for run %s", runID)
 	}
 
-	if mp.proc != nil && mp.proc.Cmd != nil && mp.proc.Cmd.Process != nil {
-		_ = mp.proc.Cmd.Process.Signal(syscall.SIGKILL)
-	} else if mp.pid > 0 {
-		_ = syscall.Kill(mp.pid, syscall.SIGKILL)
+	if mp.pid > 0 {
+		_ = runtime.Signal(mp.pid, mp.container, syscall.SIGKILL)
+	} else if mp.proc != nil {
+		_ = mp.rt.Stop(mp.proc)
 	}
 	mp.cancel()
 	return nil
 }
 
+// KillAll kills every agent process the manager started, including
+// paused ones. `agtop run` calls it before exiting so no agent outlives it.
+func (m *Manager) KillAll() {
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.processes))
+	for id := range m.processes {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+	for _, id := range ids {
+		_ = m.Kill(id)
+	}
+}
+
 func (m *Manager) Buffer(runID string) *RingBuffer {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -297,6 +505,14 @@ func (m *Manager) EntryBuffer(runID string) *EntryBuffer {
 	return m.entryBuffers[runID]
 }
 
+// Timeline returns the tool-call timeline of a run, or nil for runs whose
+// processes were not started or reconnected by this manager.
+func (m *Manager) Timeline(runID string) *Timeline {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.timelines[runID]
+}
+
 // InjectBuffer creates a ring buffer pre-populated with log lines.
 // Used to restore log history for rehydrated runs that have no log files
 // (backward compatibility with old sessions).
@@ -320,10 +536,125 @@ func (m *Manager) RemoveBuffer(runID string) {
 	defer m.mu.Unlock()
 	delete(m.buffers, runID)
 	delete(m.entryBuffers, runID)
+	delete(m.timelines, runID)
 	if lf, ok := m.logFiles[runID]; ok {
 		lf.Close()
 		delete(m.logFiles, runID)
 	}
+	if m.sessionsDir != "" && !strings.Contains(runID, ":") {
+		path := EventLogPath(m.sessionsDir, runID)
+		if el := m.eventLogs[path]; el != nil {
+			el.Close()
+		}
+		delete(m.eventLogs, path)
+	}
+}
+
+// EventLogPath returns the path of a run's event log, or an empty string
+// when the manager keeps no logs on disk.
+func (m *Manager) EventLogPath(runID string) string {
+	if m.sessionsDir == "" {
+		return ""
+	}
+	return EventLogPath(m.sessionsDir, runID)
+}
+
+// eventLog returns the event log runID's events are appended to, opening it
+// on first use. It returns nil when the manager keeps no logs on disk or the
+// log cannot be opened.
+func (m *Manager) eventLog(runID string) *EventLog {
+	if m.sessionsDir == "" {
+		return nil
+	}
+	path := EventLogPath(m.sessionsDir, runID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.eventLogs[path]; ok {
+		return el
+	}
+	el, err := OpenEventLog(path)
+	if err != nil {
+		log.Printf("warning: open event log for %s: %v", runID, err)
+	}
+	// A failed open is remembered as nil so it is not retried per event.
+	m.eventLogs[path] = el
+	return el
+}
+
+// recordEvent appends rec to el, marking the events of parallel sub-tasks
+// with their task run ID.
+func (m *Manager) recordEvent(el *EventLog, runID string, rec EventRecord) {
+	if el == nil {
+		return
+	}
+	if strings.Contains(runID, ":") {
+		rec.TaskID = runID
+	}
+	if err := el.Append(rec); err != nil {
+		log.Printf("warning: write event log for %s: %v", runID, err)
+	}
+}
+
+// RestoreEventLog rebuilds a run's log buffers from its event log, so the
+// entries of a restored run keep their types, details and nesting. It
+// reports false when the run has no event log.
+func (m *Manager) RestoreEventLog(runID string) bool {
+	rl := m.loadEventLog(runID)
+	if rl == nil {
+		return false
+	}
+	m.mu.Lock()
+	m.buffers[runID] = rl.buf
+	m.entryBuffers[runID] = rl.eb
+	m.mu.Unlock()
+	return true
+}
+
+// restoredLog holds a run's log buffers rebuilt from its event log.
+type restoredLog struct {
+	buf          *RingBuffer
+	eb           *EntryBuffer
+	stdoutEvents int // records of events read from the run's stdout log
+	stderrLines  int // records of lines read from the run's stderr log
+}
+
+// loadEventLog renders a run's event log into new buffers, or returns nil
+// when the run has none. Events of parallel sub-tasks are left out, as they
+// are from the run's live log.
+func (m *Manager) loadEventLog(runID string) *restoredLog {
+	if m.sessionsDir == "" {
+		return nil
+	}
+	recs, err := ReadEventLog(EventLogPath(m.sessionsDir, runID))
+	if err != nil || len(recs) == 0 {
+		return nil
+	}
+
+	rl := &restoredLog{buf: NewRingBuffer(10000), eb: NewEntryBuffer(5000)}
+	for _, rec := range recs {
+		if rec.TaskID != "" {
+			continue
+		}
+		ts := rec.Time.Format("15:04:05")
+		if rec.Stream == StreamStderr {
+			rl.stderrLines++
+			rl.buf.Append(logLine(ts, rec.Skill, "", rec.Text))
+			rl.eb.Append(NewLogEntry(ts, rec.Skill, EventRaw, rec.Text))
+			continue
+		}
+		// Interjected messages are written by agtop, not read from stdout.
+		if rec.Type != EventUser {
+			rl.stdoutEvents++
+		}
+		line, entry := m.renderEvent(rec.Event(), ts, rec.Skill)
+		if line != "" {
+			rl.buf.Append(line)
+			if entry != nil {
+				rl.eb.Append(entry)
+			}
+		}
+	}
+	return rl
 }
 
 func (m *Manager) ActiveCount() int {
@@ -361,6 +692,7 @@ func (m *Manager) Reconnect(runID string, pid int, stdoutPath string, stderrPath
 			r.State = run.StateFailed
 			r.Error = fmt.Sprintf("reconnect failed: open stdout log: %v", err)
 			r.PID = 0
+			r.Container = ""
 		})
 		return
 	}
@@ -373,27 +705,66 @@ func (m *Manager) Reconnect(runID string, pid int, stdoutPath string, stderrPath
 			r.State = run.StateFailed
 			r.Error = fmt.Sprintf("reconnect failed: open stderr log: %v", err)
 			r.PID = 0
+			r.Container = ""
 		})
 		return
 	}
 
-	stdoutReader := NewFollowReader(ctx, stdoutF)
-	stderrReader := NewFollowReader(ctx, stderrF)
-
 	buf := NewRingBuffer(10000)
 	eb := NewEntryBuffer(5000)
+	tl := NewTimeline()
+	// Only the running skill's part of stdout is followed, with its own
+	// runtime's parser; earlier skills may have used other runtimes. Output
+	// already in the event log is restored from it instead, with its
+	// original skills.
+	segs := m.logSegments(runID)
+	last := segs[len(segs)-1]
+	var earlier []byte
+	if last.Offset > 0 {
+		if data, err := os.ReadFile(stdoutPath); err == nil && int64(len(data)) >= last.Offset {
+			earlier = data[:last.Offset]
+		}
+	}
+	skip := 0
+	rl := m.loadEventLog(runID)
+	if rl == nil {
+		rl = &restoredLog{}
+		m.replayStdout(earlier, segs[:len(segs)-1], buf, eb)
+	} else {
+		buf, eb = rl.buf, rl.eb
+		skip = rl.stdoutEvents
+		for i, seg := range segs[:len(segs)-1] {
+			m.replaySegment(earlier, seg, segs[i+1].Offset, func(StreamEvent) { skip-- })
+		}
+		if skip < 0 {
+			skip = 0
+		}
+	}
+	_, _ = stdoutF.Seek(last.Offset, io.SeekStart)
 
+	stdoutReader := NewFollowReader(ctx, stdoutF)
+	stderrReader := NewFollowReader(ctx, stderrF)
+
+	var container string
+	if r, ok := m.store.Get(runID); ok {
+		container = r.Container
+	}
 	mp := &ManagedProcess{
-		proc:   nil, // reconnected — no exec.Cmd
-		cancel: cancel,
-		runID:  runID,
-		pid:    pid,
+		proc:        nil, // reconnected — no exec.Cmd
+		cancel:      cancel,
+		runID:       runID,
+		pid:         pid,
+		container:   container,
+		runtimeName: last.Runtime,
+		skip:        skip,
+		skipStderr:  rl.stderrLines,
 	}
 
 	m.mu.Lock()
 	m.processes[runID] = mp
 	m.buffers[runID] = buf
 	m.entryBuffers[runID] = eb
+	m.timelines[runID] = tl
 	m.mu.Unlock()
 
 	// Monitor PID: when the process exits, cancel the FollowReaders
@@ -413,7 +784,7 @@ func (m *Manager) Reconnect(runID string, pid int, stdoutPath string, stderrPath
 		}
 	}()
 
-	go m.consumeEvents(runID, mp, buf, eb, stdoutReader, stderrReader, doneCh)
+	go m.consumeEvents(runID, mp, buf, eb, tl, stdoutReader, stderrReader, doneCh)
 }
 
 // ReplayLogFile reads an entire log file into buffers without following.
@@ -425,28 +796,7 @@ func (m *Manager) ReplayLogFile(runID string, stdoutPath string, stderrPath stri
 	// Replay stdout (stream-json events)
 	if stdoutPath != "" {
 		if data, err := os.ReadFile(stdoutPath); err == nil {
-			parser := m.newParser(strings.NewReader(string(data)), 256)
-			go parser.Parse(context.Background())
-
-			skillName := func() string {
-				r, ok := m.store.Get(runID)
-				if !ok {
-					return ""
-				}
-				return r.CurrentSkill
-			}
-
-			for event := range parser.Events() {
-				ts := time.Now().Format("15:04:05")
-				skill := skillName()
-				logLine, entry := m.formatEvent(event, ts, skill, nil, runID, buf)
-				if logLine != "" {
-					buf.Append(logLine)
-					if entry != nil {
-						eb.Append(entry)
-					}
-				}
-			}
+			m.replayStdout(data, m.logSegments(runID), buf, eb)
 		}
 	}
 
@@ -470,6 +820,29 @@ func (m *Manager) ReplayLogFile(runID string, stdoutPath string, stderrPath stri
 	m.mu.Unlock()
 }
 
+// replayStdout renders the events of segs, the consecutive parts of a
+// run's stdout log, into buf and eb. Each part is parsed with the runtime
+// that wrote it. Replayed events have no side effects: their usage is
+// already on the run and in the spend ledger.
+func (m *Manager) replayStdout(data []byte, segs []run.LogSegment, buf *RingBuffer, eb *EntryBuffer) {
+	for i, seg := range segs {
+		end := int64(len(data))
+		if i+1 < len(segs) {
+			end = segs[i+1].Offset
+		}
+		m.replaySegment(data, seg, end, func(event StreamEvent) {
+			ts := time.Now().Format("15:04:05")
+			logLine, entry := m.renderEvent(event, ts, seg.Skill)
+			if logLine != "" {
+				buf.Append(logLine)
+				if entry != nil {
+					eb.Append(entry)
+				}
+			}
+		})
+	}
+}
+
 // SetDisconnecting marks the manager as shutting down. When set,
 // consumeSkillEvents and consumeEvents will not zero PIDs or update
 // run state, preserving the run for reconnection on next startup.
@@ -497,6 +870,11 @@ func (m *Manager) DisconnectAll() {
 	for _, lf := range m.logFiles {
 		lf.Close()
 	}
+	for _, el := range m.eventLogs {
+		if el != nil {
+			el.Close()
+		}
+	}
 }
 
 // StartSkill launches a skill subprocess and returns a channel that receives
@@ -525,9 +903,15 @@ func (m *Manager) StartSkill(runID string, prompt string, opts runtime.RunOption
 	if eb == nil {
 		eb = NewEntryBuffer(5000)
 	}
+	tl := m.timelines[runID]
+	if tl == nil {
+		tl = NewTimeline()
+	}
+	res.mp.meter.limit = m.skillLimits[opts.Skill]
 	m.processes[runID] = res.mp
 	m.buffers[runID] = buf
 	m.entryBuffers[runID] = eb
+	m.timelines[runID] = tl
 	if res.lf != nil {
 		m.logFiles[runID] = res.lf
 	}
@@ -535,29 +919,39 @@ func (m *Manager) StartSkill(runID string, prompt string, opts runtime.RunOption
 
 	m.store.Update(runID, func(r *run.Run) {
 		r.PID = res.mp.pid
+		r.Container = res.mp.container
+		r.Runtime = res.mp.runtimeName
 	})
 
 	resultCh := make(chan SkillResult, 1)
-	go m.consumeSkillEvents(runID, res.mp, buf, eb, res.stdoutReader, res.stderrReader, res.mp.proc.Done, resultCh)
+	go m.consumeSkillEvents(runID, res.mp, buf, eb, tl, res.stdoutReader, res.stderrReader, res.mp.proc.Done, resultCh)
 
 	return resultCh, nil
 }
 
 // scanStderr reads lines from stderr and appends them to the ring buffer and
-// entry buffer, then notifies the TUI. Intended to run in a goroutine.
-func (m *Manager) scanStderr(runID string, stderr io.Reader, buf *RingBuffer, eb *EntryBuffer, skillName func() string) {
+// entry buffer, then notifies the TUI. The first skip lines are already in
+// the buffers and are dropped. Intended to run in a goroutine.
+func (m *Manager) scanStderr(runID string, stderr io.Reader, buf *RingBuffer, eb *EntryBuffer, skillName func() string, skip int) {
+	el := m.eventLog(runID)
 	scanner := bufio.NewScanner(stderr)
 	for scanner.Scan() {
 		line := scanner.Text()
-		ts := time.Now().Format("15:04:05")
+		if skip > 0 {
+			skip--
+			continue
+		}
+		now := time.Now()
+		ts := now.Format("15:04:05")
 		skill := skillName()
+		m.recordEvent(el, runID, EventRecord{Time: now, Skill: skill, Stream: StreamStderr, Type: EventRaw, Text: line})
 		buf.Append(logLine(ts, skill, "", line))
 		eb.Append(NewLogEntry(ts, skill, EventRaw, line))
 		m.sendLogLine(runID)
 	}
 }
 
-func (m *Manager) consumeSkillEvents(runID string, mp *ManagedProcess, buf *RingBuffer, eb *EntryBuffer, stdout io.Reader, stderr io.Reader, done <-chan error, resultCh chan<- SkillResult) {
+func (m *Manager) consumeSkillEvents(runID string, mp *ManagedProcess, buf *RingBuffer, eb *EntryBuffer, tl *Timeline, stdout io.Reader, stderr io.Reader, done <-chan error, resultCh chan<- SkillResult) {
 	defer close(resultCh)
 
 	var resultText string
@@ -580,16 +974,27 @@ func (m *Manager) consumeSkillEvents(runID string, mp *ManagedProcess, buf *Ring
 		return r.CurrentSkill
 	}
 
-	parser := m.newParser(stdout, 256)
+	parser := newParser(m.protocol(mp.runtimeName), stdout, 256)
 	go parser.Parse(context.Background())
-	go m.scanStderr(runID, stderr, buf, eb, skillName)
+	go m.scanStderr(runID, stderr, buf, eb, skillName, mp.skipStderr)
+	tl.StartSkill(skillName(), time.Now())
+	el := m.eventLog(runID)
 
 	for event := range parser.Events() {
-		ts := time.Now().Format("15:04:05")
+		now := time.Now()
+		ts := now.Format("15:04:05")
 		skill := skillName()
+		if mp.skip > 0 {
+			// Already logged and restored before the reconnect.
+			mp.skip--
+			continue
+		}
+		tl.Record(event, skill, now)
+		m.recordEvent(el, runID, NewEventRecord(event, now, skill))
 
 		if event.Type == EventResult {
 			resultText = event.Text
+			m.answered(mp)
 		}
 
 		line, entry := m.formatEvent(event, ts, skill, buf, runID, buf)
@@ -600,9 +1005,12 @@ func (m *Manager) consumeSkillEvents(runID string, mp *ManagedProcess, buf *Ring
 			}
 			m.sendLogLine(runID)
 		}
+		m.meterSkill(runID, mp, event, ts, skill, buf)
 	}
 
 	<-exitDone
+	tl.EndSkill(time.Now())
+	m.closeInput(mp)
 
 	// If the TUI is shutting down, preserve PID and process entry so the
 	// session file saves the live state for reconnection on restart.
@@ -611,18 +1019,27 @@ func (m *Manager) consumeSkillEvents(runID string, mp *ManagedProcess, buf *Ring
 		return
 	}
 
+	// A skill stopped at its limit ends without failing the run, which
+	// carries on with what the skill got done.
+	partial := mp.meter.stopped
+	if partial {
+		m.recordPartial(runID, mp, time.Now().Format("15:04:05"), skillName(), buf)
+		exitErr = nil
+	}
+
 	m.store.Update(runID, func(r *run.Run) {
 		r.PID = 0
+		r.Container = ""
 	})
 
 	m.mu.Lock()
 	delete(m.processes, runID)
 	m.mu.Unlock()
 
-	resultCh <- SkillResult{ResultText: resultText, Err: exitErr}
+	resultCh <- SkillResult{ResultText: resultText, Err: exitErr, Partial: partial}
 }
 
-func (m *Manager) consumeEvents(runID string, mp *ManagedProcess, buf *RingBuffer, eb *EntryBuffer, stdout io.Reader, stderr io.Reader, done <-chan error) {
+func (m *Manager) consumeEvents(runID string, mp *ManagedProcess, buf *RingBuffer, eb *EntryBuffer, tl *Timeline, stdout io.Reader, stderr io.Reader, done <-chan error) {
 	// When the process exits, cancel the FollowReader context so the
 	// stream parser drains and the event loop below unblocks.
 	var exitErr error
@@ -641,13 +1058,27 @@ func (m *Manager) consumeEvents(runID string, mp *ManagedProcess, buf *RingBuffe
 		return r.CurrentSkill
 	}
 
-	parser := m.newParser(stdout, 256)
+	parser := newParser(m.protocol(mp.runtimeName), stdout, 256)
 	go parser.Parse(context.Background())
-	go m.scanStderr(runID, stderr, buf, eb, skillName)
+	go m.scanStderr(runID, stderr, buf, eb, skillName, mp.skipStderr)
+	tl.StartSkill(skillName(), time.Now())
+	el := m.eventLog(runID)
 
 	for event := range parser.Events() {
-		ts := time.Now().Format("15:04:05")
+		now := time.Now()
+		ts := now.Format("15:04:05")
 		skill := skillName()
+		if mp.skip > 0 {
+			// Already logged and restored before the reconnect.
+			mp.skip--
+			continue
+		}
+		tl.Record(event, skill, now)
+		m.recordEvent(el, runID, NewEventRecord(event, now, skill))
+
+		if event.Type == EventResult {
+			m.answered(mp)
+		}
 
 		line, entry := m.formatEvent(event, ts, skill, buf, runID, buf)
 		if line != "" {
@@ -660,6 +1091,8 @@ func (m *Manager) consumeEvents(runID string, mp *ManagedProcess, buf *RingBuffe
 	}
 
 	<-exitDone
+	tl.EndSkill(time.Now())
+	m.closeInput(mp)
 
 	// If the TUI is shutting down, preserve PID and process entry so the
 	// session file saves the live state for reconnection on restart.
@@ -670,6 +1103,7 @@ func (m *Manager) consumeEvents(runID string, mp *ManagedProcess, buf *RingBuffe
 	// Update run state based on exit
 	m.store.Update(runID, func(r *run.Run) {
 		r.PID = 0
+		r.Container = ""
 		r.CompletedAt = time.Now()
 		if exitErr == nil {
 			r.State = run.StateCompleted
@@ -685,6 +1119,84 @@ func (m *Manager) consumeEvents(runID string, mp *ManagedProcess, buf *RingBuffe
 	m.mu.Unlock()
 }
 
+// Interject sends a user message into the conversation of a run's live
+// process and logs it. The process's runtime must accept messages while
+// running (see runtime.MessageRuntime), and the conversation must not have
+// ended yet.
+func (m *Manager) Interject(runID string, text string) error {
+	m.mu.Lock()
+	mp, ok := m.processes[runID]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("run %s has no active process", runID)
+	}
+	sender, canSend := mp.rt.(runtime.MessageRuntime)
+	if !canSend || mp.proc == nil || mp.proc.Stdin == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("runtime %s does not accept messages while running", mp.runtimeName)
+	}
+	if mp.pending == 0 {
+		m.mu.Unlock()
+		return fmt.Errorf("run %s has already finished its conversation", runID)
+	}
+	// Count the message before writing it, so the answer to an earlier
+	// one cannot close stdin underneath it.
+	mp.pending++
+	buf := m.buffers[runID]
+	eb := m.entryBuffers[runID]
+	m.mu.Unlock()
+
+	mp.sendMu.Lock()
+	err := sender.SendMessage(mp.proc, text)
+	mp.sendMu.Unlock()
+	if err != nil {
+		m.answered(mp)
+		return fmt.Errorf("send message: %w", err)
+	}
+
+	now := time.Now()
+	ts := now.Format("15:04:05")
+	var skill string
+	if r, ok := m.store.Get(runID); ok {
+		skill = r.CurrentSkill
+	}
+	m.recordEvent(m.eventLog(runID), runID, NewEventRecord(StreamEvent{Type: EventUser, Text: text}, now, skill))
+	if buf != nil {
+		buf.Append(logLine(ts, skill, "User: ", text))
+	}
+	if eb != nil {
+		eb.Append(NewLogEntry(ts, skill, EventUser, text))
+	}
+	m.sendLogLine(runID)
+	return nil
+}
+
+// answered records that mp's process has answered a message. Once every
+// message has been answered its stdin is closed, which ends the
+// conversation and lets the process exit.
+func (m *Manager) answered(mp *ManagedProcess) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mp.pending == 0 {
+		return
+	}
+	mp.pending--
+	if mp.pending == 0 {
+		mp.proc.Stdin.Close()
+	}
+}
+
+// closeInput closes mp's stdin if it is still open, once its process has
+// exited.
+func (m *Manager) closeInput(mp *ManagedProcess) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mp.pending > 0 {
+		mp.pending = 0
+		mp.proc.Stdin.Close()
+	}
+}
+
 // logLine formats a timestamped log line with an optional skill prefix and message prefix.
 // When skill is non-empty it produces "[ts skill] prefix text"; otherwise "[ts] prefix text".
 func logLine(ts, skill, prefix, text string) string {
@@ -694,77 +1206,121 @@ func logLine(ts, skill, prefix, text string) string {
 	return fmt.Sprintf("[%s] %s%s", ts, prefix, text)
 }
 
-// formatEvent converts a StreamEvent into a formatted log line and a LogEntry.
-// The safetyBuf is used for tool safety checks (pass the ring buffer).
+// subAgentMarker prefixes the log lines of events emitted by a Task
+// sub-agent, so rehydrated entries keep their nesting.
+const subAgentMarker = "↳ "
+
+// formatEvent converts a StreamEvent into a formatted log line and a LogEntry,
+// recording its effects on the run: token usage, the model and session ID
+// of a system/init event, and tool safety warnings (pass the ring buffer as
+// safetyBuf, or nil to skip the checks).
 func (m *Manager) formatEvent(event StreamEvent, ts string, skill string, safetyBuf *RingBuffer, runID string, buf *RingBuffer) (string, *LogEntry) {
 	switch event.Type {
-	case EventText:
-		return logLine(ts, skill, "", event.Text), NewLogEntry(ts, skill, EventText, event.Text)
 	case EventToolUse:
 		if safetyBuf != nil {
 			m.checkToolSafety(event.ToolName, event.ToolInput, ts, skill, safetyBuf, runID)
 		}
-		return logLine(ts, skill, "Tool: ", event.ToolName), &LogEntry{
+	case EventResult:
+		if event.Usage != nil {
+			m.recordUsage(runID, skill, event.Usage, ts, buf)
+		}
+	case EventRaw:
+		if model, session, ok := parseSystemInit(event.Text); ok {
+			m.store.Update(runID, func(r *run.Run) {
+				if model != "" {
+					r.Model = model
+				}
+				if session != "" {
+					r.SessionID = session
+				}
+			})
+		}
+	}
+	return m.renderEvent(event, ts, skill)
+}
+
+// renderEvent formats a StreamEvent as a log line and a LogEntry without
+// side effects. Events of a Task sub-agent are nested one level under its
+// tool call.
+func (m *Manager) renderEvent(event StreamEvent, ts string, skill string) (string, *LogEntry) {
+	var marker string
+	var depth int
+	if event.ParentID != "" {
+		marker, depth = subAgentMarker, 1
+	}
+	line := func(prefix, text string) string {
+		return logLine(ts, skill, marker+prefix, text)
+	}
+	nest := func(e *LogEntry) *LogEntry {
+		if e != nil {
+			e.Depth = depth
+		}
+		return e
+	}
+
+	switch event.Type {
+	case EventText:
+		return line("", event.Text), nest(NewLogEntry(ts, skill, EventText, event.Text))
+	case EventThinking:
+		return line("Thinking: ", event.Text), nest(NewLogEntry(ts, skill, EventThinking, event.Text))
+	case EventToolUse:
+		return line("Tool: ", event.ToolName), nest(&LogEntry{
 			Timestamp: ts,
 			Skill:     skill,
 			Type:      EventToolUse,
 			Summary:   ToolUseSummary(event.ToolName, event.ToolInput),
 			Detail:    FormatJSON(event.ToolInput),
 			Complete:  true,
-		}
+		})
 	case EventToolResult:
-		return logLine(ts, skill, "Result: ", event.Text), NewLogEntry(ts, skill, EventToolResult, event.Text)
+		text := event.Text
+		if text == "" && event.IsError {
+			text = "(failed)"
+		}
+		if text == "" {
+			return "", nil
+		}
+		return line("Result: ", text), nest(NewLogEntry(ts, skill, EventToolResult, text))
 	case EventResult:
 		if event.Usage == nil {
 			return "", nil
 		}
-		m.recordUsage(runID, skill, event.Usage, ts, buf)
 		summary := fmt.Sprintf("Completed — %d tokens, $%.4f", event.Usage.TotalTokens, event.Usage.CostUSD)
-		return logLine(ts, skill, "", summary), NewLogEntry(ts, skill, EventResult, summary)
+		if event.Usage.CacheReadTokens+event.Usage.CacheCreationTokens > 0 {
+			summary += fmt.Sprintf(", cache %.0f%% hit", event.Usage.CacheHitRate()*100)
+		}
+		return line("", summary), NewLogEntry(ts, skill, EventResult, summary)
 	case EventUser:
-		return logLine(ts, skill, "User: ", event.Text), NewLogEntry(ts, skill, EventUser, event.Text)
+		return line("User: ", event.Text), nest(NewLogEntry(ts, skill, EventUser, event.Text))
 	case EventError:
 		if m.limiter != nil && m.limiter.IsRateLimit(event.Text) {
-			return logLine(ts, skill, "RATE LIMITED: ", event.Text), nil
+			return line("RATE LIMITED: ", event.Text), nil
 		}
-		return logLine(ts, skill, "ERROR: ", event.Text), NewLogEntry(ts, skill, EventError, event.Text)
+		return line("ERROR: ", event.Text), nest(NewLogEntry(ts, skill, EventError, event.Text))
 	case EventRaw:
-		if model := extractSystemInitModel(event.Text); model != "" {
-			m.store.Update(runID, func(r *run.Run) {
-				r.Model = model
-			})
-		}
-		return logLine(ts, skill, "", event.Text), InterpretRawEvent(ts, skill, event.Text)
+		return line("", event.Text), nest(InterpretRawEvent(ts, skill, event.Text))
 	}
 	return "", nil
 }
 
 // recordUsage updates run token/cost fields, records to the tracker, and checks thresholds.
 func (m *Manager) recordUsage(runID string, skill string, usage *UsageData, ts string, buf *RingBuffer) {
+	m.recordSkillCost(runID, m.skillCost(runID, skill, usage), ts, buf)
+}
+
+func (m *Manager) recordSkillCost(runID string, sc cost.SkillCost, ts string, buf *RingBuffer) {
 	m.store.Update(runID, func(r *run.Run) {
-		r.TokensIn += usage.InputTokens
-		r.TokensOut += usage.OutputTokens
-		r.Tokens += usage.TotalTokens
-		r.Cost += usage.CostUSD
-		r.SkillCosts = append(r.SkillCosts, cost.SkillCost{
-			SkillName:    skill,
-			InputTokens:  usage.InputTokens,
-			OutputTokens: usage.OutputTokens,
-			TotalTokens:  usage.TotalTokens,
-			CostUSD:      usage.CostUSD,
-			CompletedAt:  time.Now(),
-		})
+		r.TokensIn += sc.InputTokens
+		r.TokensOut += sc.OutputTokens
+		r.CacheRead += sc.CacheReadTokens
+		r.CacheWrite += sc.CacheWriteTokens
+		r.Tokens += sc.TotalTokens
+		r.Cost += sc.CostUSD
+		r.SkillCosts = append(r.SkillCosts, sc)
 	})
 
 	if m.tracker != nil {
-		m.tracker.Record(runID, cost.SkillCost{
-			SkillName:    skill,
-			InputTokens:  usage.InputTokens,
-			OutputTokens: usage.OutputTokens,
-			TotalTokens:  usage.TotalTokens,
-			CostUSD:      usage.CostUSD,
-			CompletedAt:  time.Now(),
-		})
+		m.tracker.Record(runID, sc)
 	}
 
 	if m.limiter != nil {
@@ -779,6 +1335,88 @@ func (m *Manager) recordUsage(runID string, skill string, usage *UsageData, ts s
 			}
 		}
 	}
+
+	if ledger := m.Ledger(); ledger != nil {
+		// Sub-tasks spend on behalf of their parent run.
+		parent, _, _ := strings.Cut(runID, ":")
+		if err := ledger.Record(parent, sc.CostUSD, sc.CompletedAt); err != nil {
+			log.Printf("warning: record spend for %s: %v", runID, err)
+		}
+		m.enforceBudget(ts)
+	}
+}
+
+// enforceBudget pauses every running process once spend has reached a
+// budget cap.
+func (m *Manager) enforceBudget(ts string) {
+	err := m.budgetReached()
+	if err == nil {
+		return
+	}
+
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.processes))
+	for id := range m.processes {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		if r, ok := m.store.Get(id); ok && r.State == run.StatePaused {
+			continue
+		}
+		if m.Pause(id) != nil {
+			continue
+		}
+		if buf := m.Buffer(id); buf != nil {
+			buf.Append(fmt.Sprintf("[%s] WARNING: %s, pausing run", ts, err))
+			m.sendLogLine(id)
+		}
+		m.sendCostThreshold(id, err.Error())
+	}
+}
+
+// skillCost builds the cost entry of a skill's usage. The cost the runtime
+// reports is kept when there is one; otherwise the usage is priced with the
+// rates of the process's model, or of the run's when it has none. The
+// table's price is recorded either way, so runtimes can be compared.
+func (m *Manager) skillCost(runID, skill string, usage *UsageData) cost.SkillCost {
+	sc := cost.SkillCost{
+		SkillName:        skill,
+		InputTokens:      usage.InputTokens,
+		OutputTokens:     usage.OutputTokens,
+		TotalTokens:      usage.TotalTokens,
+		CacheReadTokens:  usage.CacheReadTokens,
+		CacheWriteTokens: usage.CacheCreationTokens,
+		CostUSD:          usage.CostUSD,
+		CompletedAt:      time.Now(),
+	}
+
+	m.mu.Lock()
+	var model string
+	if mp, ok := m.processes[runID]; ok {
+		model = mp.model
+	}
+	pricing := m.pricing
+	m.mu.Unlock()
+	if model == "" {
+		if r, ok := m.store.Get(runID); ok {
+			model = r.Model
+		}
+	}
+	sc.Model = model
+	if rates, ok := pricing.Lookup(model); ok {
+		sc.PricedUSD = rates.Cost(usage.InputTokens, usage.OutputTokens, usage.CacheReadTokens, usage.CacheCreationTokens)
+	}
+
+	switch {
+	case usage.CostUSD > 0:
+		sc.CostSource = cost.CostSourceRuntime
+	case sc.PricedUSD > 0:
+		sc.CostUSD = sc.PricedUSD
+		sc.CostSource = cost.CostSourcePricing
+	}
+	return sc
 }
 
 // lineToEntry converts a formatted log line back into a LogEntry.
@@ -794,6 +1432,18 @@ func lineToEntry(line string) *LogEntry {
 	skill := parts[2]
 	msg := parts[3]
 
+	if strings.HasPrefix(msg, subAgentMarker) {
+		entry := messageToEntry(ts, skill, strings.TrimPrefix(msg, subAgentMarker))
+		if entry != nil {
+			entry.Depth = 1
+		}
+		return entry
+	}
+	return messageToEntry(ts, skill, msg)
+}
+
+// messageToEntry converts the message part of a formatted log line.
+func messageToEntry(ts, skill, msg string) *LogEntry {
 	switch {
 	case strings.HasPrefix(msg, "Tool: "):
 		return &LogEntry{
@@ -816,6 +1466,9 @@ func lineToEntry(line string) *LogEntry {
 	case strings.HasPrefix(msg, "User: "):
 		detail := strings.TrimPrefix(msg, "User: ")
 		return NewLogEntry(ts, skill, EventUser, detail)
+	case strings.HasPrefix(msg, "Thinking: "):
+		detail := strings.TrimPrefix(msg, "Thinking: ")
+		return NewLogEntry(ts, skill, EventThinking, detail)
 	case strings.HasPrefix(msg, "Completed"):
 		return NewLogEntry(ts, skill, EventResult, msg)
 	default:
@@ -847,22 +1500,30 @@ func (m *Manager) sendCostThreshold(runID string, reason string) {
 // extractSystemInitModel returns the model name from a system/init JSON event,
 // or empty string if the text is not a system/init event.
 func extractSystemInitModel(text string) string {
+	model, _, _ := parseSystemInit(text)
+	return model
+}
+
+// parseSystemInit returns the model and session ID of a system/init JSON
+// event. ok is false if the text is not a system/init event.
+func parseSystemInit(text string) (model, sessionID string, ok bool) {
 	text = strings.TrimSpace(text)
 	if text == "" || text[0] != '{' {
-		return ""
+		return "", "", false
 	}
 	var msg struct {
-		Type    string `json:"type"`
-		Subtype string `json:"subtype"`
-		Model   string `json:"model"`
+		Type      string `json:"type"`
+		Subtype   string `json:"subtype"`
+		Model     string `json:"model"`
+		SessionID string `json:"session_id"`
 	}
 	if json.Unmarshal([]byte(text), &msg) != nil {
-		return ""
+		return "", "", false
 	}
 	if msg.Type == "system" && msg.Subtype == "init" {
-		return msg.Model
+		return msg.Model, msg.SessionID, true
 	}
-	return ""
+	return "", "", false
 }
 
 func (m *Manager) checkToolSafety(toolName string, toolInput string, ts string, skill string, buf *RingBuffer, runID string) {
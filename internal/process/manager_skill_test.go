@@ -2,8 +2,11 @@ package process
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -253,3 +256,454 @@ func TestStartSkillWithLogFilesCompletes(t *testing.T) {
 	// Clean up log files
 	os.RemoveAll(sessionsDir)
 }
+
+func TestStartSkillUsesSelectedRuntime(t *testing.T) {
+	defaultRT := &mockRuntime{
+		startFn: func(_ context.Context, _ string, _ runtime.RunOptions) (*runtime.Process, error) {
+			t.Error("default runtime should not start a skill that selects opencode")
+			return nil, io.EOF
+		},
+	}
+	mgr, store := testManager(defaultRT)
+
+	doneCh := make(chan error, 1)
+	input := `{"type":"text","timestamp":1700000000,"sessionID":"ses_abc","part":{"type":"text","text":"from opencode"}}` + "\n"
+	started := false
+	mgr.AddRuntime("opencode", &mockRuntime{
+		startFn: func(_ context.Context, _ string, _ runtime.RunOptions) (*runtime.Process, error) {
+			started = true
+			return &runtime.Process{
+				PID:    12345,
+				Stdout: io.NopCloser(strings.NewReader(input)),
+				Stderr: io.NopCloser(strings.NewReader("")),
+				Done:   doneCh,
+			}, nil
+		},
+	})
+
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "review"})
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Runtime: "opencode"})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+	doneCh <- nil
+
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+	if !started {
+		t.Error("expected the opencode runtime to start the skill")
+	}
+	r, _ := store.Get(runID)
+	if r.Runtime != "opencode" {
+		t.Errorf("expected run runtime opencode, got %q", r.Runtime)
+	}
+	if tail := strings.Join(mgr.Buffer(runID).Tail(10), "\n"); !strings.Contains(tail, "from opencode") {
+		t.Errorf("expected OpenCode output to be parsed, got %q", tail)
+	}
+}
+
+func TestStartSkillUnknownRuntime(t *testing.T) {
+	mgr, store := testManager(&mockRuntime{})
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "review"})
+
+	_, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Runtime: "opencode"})
+	if err == nil || !strings.Contains(err.Error(), `runtime "opencode" is not available`) {
+		t.Fatalf("expected unavailable runtime error, got %v", err)
+	}
+}
+
+// protocolRuntime is a mock runtime that reports its output protocol, the
+// way a [runtime.custom] command does.
+type protocolRuntime struct {
+	*mockRuntime
+	protocol string
+}
+
+func (p protocolRuntime) Protocol() string { return p.protocol }
+
+func TestStartSkillParsesByProtocol(t *testing.T) {
+	doneCh := make(chan error, 1)
+	wrapper := protocolRuntime{
+		protocol: runtime.ProtocolText,
+		mockRuntime: &mockRuntime{
+			startFn: func(_ context.Context, _ string, _ runtime.RunOptions) (*runtime.Process, error) {
+				return &runtime.Process{
+					PID:    12345,
+					Stdout: io.NopCloser(strings.NewReader("Looking around\nAll done\n")),
+					Stderr: io.NopCloser(strings.NewReader("")),
+					Done:   doneCh,
+				}, nil
+			},
+		},
+	}
+	mgr, store := testManager(&mockRuntime{})
+	mgr.AddRuntime("wrapper", wrapper)
+
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build"})
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Runtime: "wrapper"})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+	doneCh <- nil
+
+	select {
+	case res := <-ch:
+		if res.ResultText != "Looking around\nAll done" {
+			t.Errorf("expected plain text result, got %q", res.ResultText)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+}
+
+func TestStartSkillRecordsContainer(t *testing.T) {
+	doneCh := make(chan error, 1)
+	rt := &mockRuntime{
+		startFn: func(_ context.Context, _ string, _ runtime.RunOptions) (*runtime.Process, error) {
+			return &runtime.Process{
+				PID:       12345,
+				Container: "agtop-test",
+				Stdout:    io.NopCloser(strings.NewReader("")),
+				Stderr:    io.NopCloser(strings.NewReader("")),
+				Done:      doneCh,
+			}, nil
+		},
+	}
+	mgr, store := testManager(rt)
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build"})
+
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+	// The container is persisted so a reconnected run can still be paused.
+	if r, _ := store.Get(runID); r.Container != "agtop-test" {
+		t.Errorf("expected container agtop-test on the run, got %q", r.Container)
+	}
+
+	doneCh <- nil
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+	if r, _ := store.Get(runID); r.Container != "" {
+		t.Errorf("expected container cleared after exit, got %q", r.Container)
+	}
+}
+
+func TestStartSkillWritesEventLog(t *testing.T) {
+	scripts := t.TempDir()
+	script := `
+events:
+  - type: text
+    text: Reading the spec
+  - type: tool_use
+    name: Read
+    input: {file_path: main.go}
+  - type: result
+    text: done
+    input_tokens: 100
+    output_tokens: 50
+    cost: 0.01
+`
+	if err := os.WriteFile(filepath.Join(scripts, "default.yaml"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rt, err := runtime.NewReplayRuntime(scripts, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsDir := t.TempDir()
+	store := run.NewStore()
+	newMgr := func() *Manager {
+		return NewManager(store, rt, "claude", sessionsDir, &config.LimitsConfig{MaxConcurrentRuns: 5}, cost.NewTracker(), &cost.LimitChecker{}, nil)
+	}
+	mgr := newMgr()
+	runID := store.Add(&run.Run{State: run.StateRunning})
+
+	for _, skill := range []string{"build", "test"} {
+		store.Update(runID, func(r *run.Run) { r.CurrentSkill = skill })
+		ch, err := mgr.StartSkill(runID, "prompt", runtime.RunOptions{Skill: skill})
+		if err != nil {
+			t.Fatalf("start %s: %v", skill, err)
+		}
+		select {
+		case res := <-ch:
+			if res.Err != nil {
+				t.Fatalf("%s: %v", skill, res.Err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", skill)
+		}
+	}
+
+	// Each skill reads only its own output from the shared stdout log.
+	r, _ := store.Get(runID)
+	if len(r.SkillCosts) != 2 {
+		t.Errorf("expected 2 skill costs, got %+v", r.SkillCosts)
+	}
+
+	recs, err := ReadEventLog(mgr.EventLogPath(runID))
+	if err != nil {
+		t.Fatalf("read event log: %v", err)
+	}
+	var skills []string
+	for _, rec := range recs {
+		skills = append(skills, rec.Skill+":"+string(rec.Type))
+	}
+	want := "build:text build:tool_use build:result test:text test:tool_use test:result"
+	if got := strings.Join(skills, " "); got != want {
+		t.Errorf("expected records %q, got %q", want, got)
+	}
+
+	// A new manager rebuilds the same entries, details included.
+	restored := newMgr()
+	if !restored.RestoreEventLog(runID) {
+		t.Fatal("expected the event log to be restored")
+	}
+	orig, got := mgr.EntryBuffer(runID).Entries(), restored.EntryBuffer(runID).Entries()
+	if len(got) != len(orig) {
+		t.Fatalf("expected %d entries, got %d", len(orig), len(got))
+	}
+	for i := range orig {
+		if got[i].Skill != orig[i].Skill || got[i].Type != orig[i].Type || got[i].Summary != orig[i].Summary ||
+			got[i].Detail != orig[i].Detail || got[i].Depth != orig[i].Depth {
+			t.Errorf("entry %d: expected %+v, got %+v", i, orig[i], got[i])
+		}
+	}
+	if !strings.Contains(got[1].Detail, "main.go") {
+		t.Errorf("expected the tool input in the restored detail, got %q", got[1].Detail)
+	}
+	mgr.DisconnectAll()
+}
+
+// toolTurn is one model response that calls a tool, and the tool's answer.
+func toolTurn(n int) []string {
+	id := fmt.Sprintf("toolu_%d", n)
+	return []string{
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"` + id + `","name":"Bash","input":{"command":"ls"}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"` + id + `","content":"ok"}]}}`,
+	}
+}
+
+func TestStartSkillTurnLimitAsksToWrapUp(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 20)
+	doneCh := make(chan error, 1)
+	stdin := &recordedStdin{}
+	rt := &messageRuntime{mockRuntime: makeMockRuntime(eventsCh, doneCh), stdin: stdin}
+	mgr, store := testManager(rt)
+	mgr.SetSkillLimits(map[string]config.SkillConfig{"build": {MaxTurns: 2}})
+
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build"})
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Skill: "build"})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+
+	for n := 1; n <= 2; n++ {
+		for _, line := range toolTurn(n) {
+			eventsCh <- StreamEvent{Type: EventRaw, Text: line}
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := stdin.String(); got != WindDownPrompt+"\n" {
+		t.Fatalf("expected the wrap-up request on stdin, got %q", got)
+	}
+
+	// The agent wraps up within the grace allowance.
+	eventsCh <- StreamEvent{Type: EventResult, Usage: &UsageData{InputTokens: 10, OutputTokens: 5}}
+	eventsCh <- StreamEvent{Type: EventResult, Usage: &UsageData{InputTokens: 10, OutputTokens: 5}}
+	close(eventsCh)
+	doneCh <- nil
+
+	select {
+	case res := <-ch:
+		if res.Err != nil || res.Partial {
+			t.Errorf("expected a complete result, got %+v", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+	r, _ := store.Get(runID)
+	for _, sc := range r.SkillCosts {
+		if sc.Partial {
+			t.Errorf("expected no partial skill cost, got %+v", sc)
+		}
+	}
+}
+
+func TestStartSkillWindDownCountsCostOnce(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 20)
+	doneCh := make(chan error, 1)
+	stdin := &recordedStdin{}
+	rt := &messageRuntime{mockRuntime: makeMockRuntime(eventsCh, doneCh), stdin: stdin}
+	mgr, store := testManager(rt)
+	mgr.SetSkillLimits(map[string]config.SkillConfig{"build": {MaxTurns: 2}})
+
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build"})
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Skill: "build"})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+
+	for n := 1; n <= 3; n++ {
+		for _, line := range toolTurn(n) {
+			eventsCh <- StreamEvent{Type: EventRaw, Text: line}
+		}
+	}
+	// The prompt is answered, then the wrap-up request. Each result carries
+	// the session's totals so far.
+	eventsCh <- StreamEvent{Type: EventResult, Usage: &UsageData{InputTokens: 100, OutputTokens: 50, CostUSD: 0.3}}
+	eventsCh <- StreamEvent{Type: EventText, Text: "Committed; the tests are left."}
+	eventsCh <- StreamEvent{Type: EventResult, Usage: &UsageData{InputTokens: 160, OutputTokens: 70, CostUSD: 0.45}}
+	close(eventsCh)
+	doneCh <- nil
+
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+	if got := stdin.String(); got != WindDownPrompt+"\n" {
+		t.Fatalf("expected the wrap-up request on stdin, got %q", got)
+	}
+
+	r, _ := store.Get(runID)
+	if diff := r.Cost - 0.45; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected the run to cost the last total 0.45, got %f", r.Cost)
+	}
+	if r.TokensIn != 160 || r.TokensOut != 70 {
+		t.Errorf("expected 160/70 tokens, got %d/%d", r.TokensIn, r.TokensOut)
+	}
+	var sum float64
+	for _, sc := range r.SkillCosts {
+		sum += sc.CostUSD
+	}
+	if diff := sum - 0.45; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected skill costs to add up to 0.45, got %f (%+v)", sum, r.SkillCosts)
+	}
+}
+
+func TestStartSkillTurnLimitStopsSkill(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 20)
+	doneCh := make(chan error, 1)
+	rt := makeMockRuntime(eventsCh, doneCh)
+	stopped := make(chan struct{})
+	rt.stopFn = func(*runtime.Process) error {
+		close(stopped)
+		doneCh <- errors.New("signal: terminated")
+		return nil
+	}
+	mgr, store := testManager(rt)
+	mgr.SetPricing(map[string]config.PricingConfig{"sonnet": {Input: 3, Output: 15}})
+	mgr.SetSkillLimits(map[string]config.SkillConfig{"build": {MaxTurns: 1}})
+
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build"})
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Skill: "build", Model: "sonnet"})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+
+	// The runtime cannot take the wrap-up request, so the skill is stopped.
+	eventsCh <- StreamEvent{Type: EventRaw, Text: `{"type":"assistant","message":{"id":"msg_1","usage":{"input_tokens":1000,"output_tokens":200},"content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{}}]}}`}
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the skill to be stopped")
+	}
+	close(eventsCh)
+
+	select {
+	case res := <-ch:
+		if res.Err != nil || !res.Partial {
+			t.Errorf("expected a partial result without error, got %+v", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+
+	r, _ := store.Get(runID)
+	if len(r.SkillCosts) != 1 || !r.SkillCosts[0].Partial {
+		t.Fatalf("expected one partial skill cost, got %+v", r.SkillCosts)
+	}
+	if sc := r.SkillCosts[0]; sc.TotalTokens != 1200 || sc.CostUSD <= 0 {
+		t.Errorf("expected the metered usage to be charged, got %+v", sc)
+	}
+	if r.State != run.StateRunning {
+		t.Errorf("expected the run to keep running, got %s", r.State)
+	}
+	if lines := strings.Join(mgr.Buffer(runID).Lines(), "\n"); !strings.Contains(lines, "skill turn limit reached") {
+		t.Errorf("expected a warning in the log, got %q", lines)
+	}
+}
+
+// usageTurn is a model response that reports its usage and calls a tool.
+func usageTurn(n int) string {
+	return fmt.Sprintf(`{"type":"assistant","message":{"id":"msg_%d","usage":{"input_tokens":100000,"output_tokens":20000},"content":[{"type":"tool_use","id":"toolu_%d","name":"Bash","input":{}}]}}`, n, n)
+}
+
+func TestStartSkillCostLimitPricesRunModel(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 20)
+	doneCh := make(chan error, 1)
+	rt := makeMockRuntime(eventsCh, doneCh)
+	stopped := make(chan struct{})
+	rt.stopFn = func(*runtime.Process) error {
+		close(stopped)
+		doneCh <- errors.New("signal: terminated")
+		return nil
+	}
+	mgr, store := testManager(rt)
+	mgr.SetPricing(map[string]config.PricingConfig{"sonnet": {Input: 3, Output: 15}})
+	mgr.SetSkillLimits(map[string]config.SkillConfig{"build": {MaxCost: 1}})
+
+	// No model is configured for the skill; the run's is the one the agent
+	// reported when it started.
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build", Model: "claude-sonnet-4-5"})
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Skill: "build"})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+	for n := 1; n <= 2; n++ {
+		eventsCh <- StreamEvent{Type: EventRaw, Text: usageTurn(n)}
+	}
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the skill to be stopped at its cost limit")
+	}
+	close(eventsCh)
+	<-ch
+
+	if lines := strings.Join(mgr.Buffer(runID).Lines(), "\n"); !strings.Contains(lines, "skill cost limit reached") {
+		t.Errorf("expected a cost limit warning in the log, got %q", lines)
+	}
+}
+
+func TestStartSkillCostLimitWarnsWithoutPricing(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 20)
+	doneCh := make(chan error, 1)
+	mgr, store := testManager(makeMockRuntime(eventsCh, doneCh))
+	mgr.SetSkillLimits(map[string]config.SkillConfig{"build": {MaxCost: 1}})
+
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build"})
+	ch, err := mgr.StartSkill(runID, "test prompt", runtime.RunOptions{Skill: "build", Model: "in-house-model"})
+	if err != nil {
+		t.Fatalf("start skill: %v", err)
+	}
+	for n := 1; n <= 2; n++ {
+		eventsCh <- StreamEvent{Type: EventRaw, Text: usageTurn(n)}
+	}
+	close(eventsCh)
+	doneCh <- nil
+	<-ch
+
+	lines := strings.Join(mgr.Buffer(runID).Lines(), "\n")
+	if n := strings.Count(lines, `no pricing for model "in-house-model"`); n != 1 {
+		t.Errorf("expected one pricing warning, got %d in %q", n, lines)
+	}
+}
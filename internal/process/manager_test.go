@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -424,6 +428,282 @@ func TestManagerEventConsumption(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 }
 
+func TestManagerPricesUsageWithoutRuntimeCost(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 10)
+	doneCh := make(chan error, 1)
+	rt := makeMockRuntime(eventsCh, doneCh)
+	mgr, store := testManager(rt)
+	mgr.SetPricing(map[string]config.PricingConfig{"sonnet": {Input: 3, Output: 15}})
+
+	runID := store.Add(&run.Run{State: run.StateQueued, CurrentSkill: "build"})
+	if err := mgr.Start(runID, "test", runtime.RunOptions{Model: "sonnet"}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	// Results report session totals, so the second adds 100k/10k tokens.
+	eventsCh <- StreamEvent{Type: EventResult, Usage: &UsageData{InputTokens: 100000, OutputTokens: 10000}}
+	eventsCh <- StreamEvent{Type: EventResult, Usage: &UsageData{InputTokens: 200000, OutputTokens: 20000, CostUSD: 0.5}}
+	time.Sleep(100 * time.Millisecond)
+
+	r, _ := store.Get(runID)
+	if len(r.SkillCosts) != 2 {
+		t.Fatalf("expected 2 skill costs, got %+v", r.SkillCosts)
+	}
+	priced, reported := r.SkillCosts[0], r.SkillCosts[1]
+	if priced.CostSource != cost.CostSourcePricing || math.Abs(priced.CostUSD-0.45) > 1e-9 {
+		t.Errorf("expected $0.45 from the pricing table, got %+v", priced)
+	}
+	if reported.CostSource != cost.CostSourceRuntime || reported.CostUSD != 0.5 || math.Abs(reported.PricedUSD-0.45) > 1e-9 {
+		t.Errorf("expected the runtime's $0.50 kept with the table's $0.45 alongside, got %+v", reported)
+	}
+	if math.Abs(r.Cost-0.95) > 1e-9 {
+		t.Errorf("expected run cost 0.95, got %f", r.Cost)
+	}
+	if _, tc := mgr.Tracker().RunTotal(runID); math.Abs(tc-0.95) > 1e-9 {
+		t.Errorf("expected tracker cost 0.95, got %f", tc)
+	}
+
+	close(eventsCh)
+	doneCh <- nil
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestManagerBudgetPausesActiveRuns(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 10)
+	doneCh := make(chan error, 1)
+	rt := makeMockRuntime(eventsCh, doneCh)
+	// The other run's process writes nothing.
+	otherDone := make(chan error, 1)
+	startFn := rt.startFn
+	rt.startFn = func(ctx context.Context, prompt string, opts runtime.RunOptions) (*runtime.Process, error) {
+		if opts.Skill == "other" {
+			return newMockProcess(nil, otherDone), nil
+		}
+		return startFn(ctx, prompt, opts)
+	}
+	mgr, store := testManager(rt)
+	mgr.limiter.Budget = cost.Budget{Daily: 1}
+	mgr.SetLedger(cost.NewLedger(t.TempDir()))
+
+	other := store.Add(&run.Run{State: run.StateQueued, CurrentSkill: "build"})
+	if err := mgr.Start(other, "test", runtime.RunOptions{Skill: "other"}); err != nil {
+		t.Fatalf("start other: %v", err)
+	}
+
+	runID := store.Add(&run.Run{State: run.StateQueued, CurrentSkill: "build"})
+	if err := mgr.Start(runID, "test", runtime.RunOptions{}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := mgr.CheckBudget(); err != nil {
+		t.Fatalf("expected budget left before spending, got %v", err)
+	}
+
+	eventsCh <- StreamEvent{Type: EventResult, Usage: &UsageData{InputTokens: 100, OutputTokens: 50, TotalTokens: 150, CostUSD: 1.25}}
+	time.Sleep(100 * time.Millisecond)
+
+	for _, id := range []string{runID, other} {
+		if r, _ := store.Get(id); r.State != run.StatePaused {
+			t.Errorf("expected run %s paused by the budget, got %s", id, r.State)
+		}
+	}
+	if err := mgr.CheckBudget(); err == nil || !strings.Contains(err.Error(), "daily budget reached") {
+		t.Errorf("expected new runs to be refused, got %v", err)
+	}
+
+	close(eventsCh)
+	doneCh <- nil
+	otherDone <- nil
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestManagerCheckBudgetRefusesNearCap(t *testing.T) {
+	mgr, _ := testManager(&mockRuntime{})
+	mgr.limiter.Budget = cost.Budget{Daily: 1}
+	ledger := cost.NewLedger(t.TempDir())
+	mgr.SetLedger(ledger)
+	if err := ledger.Record("earlier", 0.9, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.CheckBudget(); err == nil || !strings.Contains(err.Error(), "daily budget nearly reached") {
+		t.Errorf("expected new runs to be refused near the cap, got %v", err)
+	}
+	if err := mgr.budgetReached(); err != nil {
+		t.Errorf("expected active runs to keep going under the cap, got %v", err)
+	}
+}
+
+func TestManagerSubAgentAndCacheEvents(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 10)
+	doneCh := make(chan error, 1)
+	rt := makeMockRuntime(eventsCh, doneCh)
+	mgr, store := testManager(rt)
+
+	runID := store.Add(&run.Run{State: run.StateQueued, CurrentSkill: "build"})
+
+	if err := mgr.Start(runID, "test", runtime.RunOptions{}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	// The mock runtime writes the text of raw events to stdout unchanged.
+	eventsCh <- StreamEvent{Type: EventRaw, Text: `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Task","input":{"description":"Explore"}}]}}`}
+	eventsCh <- StreamEvent{Type: EventRaw, Text: `{"type":"assistant","parent_tool_use_id":"toolu_1","message":{"content":[{"type":"thinking","thinking":"Look for the loader"}]}}`}
+	eventsCh <- StreamEvent{Type: EventRaw, Text: `{"type":"result","result":"done","usage":{"input_tokens":100,"output_tokens":50,"cache_creation_input_tokens":100,"cache_read_input_tokens":800}}`}
+	time.Sleep(100 * time.Millisecond)
+
+	entries := mgr.EntryBuffer(runID).Entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Depth != 0 || entries[1].Depth != 1 {
+		t.Errorf("expected the thinking entry nested under the Task call, got depths %d and %d", entries[0].Depth, entries[1].Depth)
+	}
+	if entries[1].Summary != "Thinking: Look for the loader" {
+		t.Errorf("unexpected thinking summary: %q", entries[1].Summary)
+	}
+	if !strings.HasSuffix(entries[2].Summary, "cache 80% hit") {
+		t.Errorf("expected cache hit rate in result summary, got %q", entries[2].Summary)
+	}
+	if lines := mgr.Buffer(runID).Lines(); !strings.Contains(lines[1], "] ↳ Thinking: ") {
+		t.Errorf("expected nested log line, got %q", lines[1])
+	}
+
+	r, _ := store.Get(runID)
+	if r.CacheRead != 800 || r.CacheWrite != 100 {
+		t.Errorf("expected cache tokens 800/100, got %d/%d", r.CacheRead, r.CacheWrite)
+	}
+	if len(r.SkillCosts) != 1 || r.SkillCosts[0].CacheReadTokens != 800 {
+		t.Errorf("expected cache tokens in skill costs, got %+v", r.SkillCosts)
+	}
+
+	close(eventsCh)
+	doneCh <- nil
+	time.Sleep(100 * time.Millisecond)
+}
+
+// messageRuntime is a mock runtime whose processes accept messages on a
+// recorded stdin.
+type messageRuntime struct {
+	*mockRuntime
+	stdin *recordedStdin
+}
+
+func (m *messageRuntime) Start(ctx context.Context, prompt string, opts runtime.RunOptions) (*runtime.Process, error) {
+	proc, err := m.mockRuntime.Start(ctx, prompt, opts)
+	if err == nil {
+		proc.Stdin = m.stdin
+	}
+	return proc, err
+}
+
+func (m *messageRuntime) SendMessage(proc *runtime.Process, text string) error {
+	_, err := proc.Stdin.Write([]byte(text + "\n"))
+	return err
+}
+
+type recordedStdin struct {
+	mu     sync.Mutex
+	data   strings.Builder
+	closed bool
+}
+
+func (s *recordedStdin) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return s.data.Write(p)
+}
+
+func (s *recordedStdin) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordedStdin) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.String()
+}
+
+func (s *recordedStdin) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func TestManagerInterject(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 10)
+	doneCh := make(chan error, 1)
+	stdin := &recordedStdin{}
+	rt := &messageRuntime{mockRuntime: makeMockRuntime(eventsCh, doneCh), stdin: stdin}
+	mgr, store := testManager(rt)
+
+	runID := store.Add(&run.Run{State: run.StateQueued, CurrentSkill: "build"})
+	if err := mgr.Start(runID, "test", runtime.RunOptions{}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	if err := mgr.Interject(runID, "use the v2 API"); err != nil {
+		t.Fatalf("interject: %v", err)
+	}
+	if got := stdin.data.String(); got != "use the v2 API\n" {
+		t.Errorf("stdin = %q", got)
+	}
+	entries := mgr.EntryBuffer(runID).Entries()
+	if len(entries) != 1 || entries[0].Type != EventUser || entries[0].Detail != "use the v2 API" {
+		t.Fatalf("expected a user entry, got %+v", entries)
+	}
+	if lines := mgr.Buffer(runID).Lines(); !strings.HasSuffix(lines[0], "] User: use the v2 API") {
+		t.Errorf("unexpected log line %q", lines[0])
+	}
+
+	// The answer to the prompt leaves the interjection outstanding.
+	eventsCh <- StreamEvent{Type: EventRaw, Text: `{"type":"result","result":"done","usage":{"input_tokens":10,"output_tokens":5}}`}
+	time.Sleep(100 * time.Millisecond)
+	if stdin.isClosed() {
+		t.Fatal("stdin closed before the interjection was answered")
+	}
+
+	eventsCh <- StreamEvent{Type: EventRaw, Text: `{"type":"result","result":"done","usage":{"input_tokens":10,"output_tokens":5}}`}
+	time.Sleep(100 * time.Millisecond)
+	if !stdin.isClosed() {
+		t.Error("expected stdin to close once every message was answered")
+	}
+	if err := mgr.Interject(runID, "too late"); err == nil {
+		t.Error("expected an error interjecting into a finished conversation")
+	}
+
+	close(eventsCh)
+	doneCh <- nil
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestManagerInterjectUnsupported(t *testing.T) {
+	eventsCh := make(chan StreamEvent, 10)
+	doneCh := make(chan error, 1)
+	mgr, store := testManager(makeMockRuntime(eventsCh, doneCh))
+
+	if err := mgr.Interject("nonexistent", "hi"); err == nil {
+		t.Error("expected error interjecting into a run without a process")
+	}
+
+	runID := store.Add(&run.Run{State: run.StateQueued})
+	if err := mgr.Start(runID, "test", runtime.RunOptions{}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := mgr.Interject(runID, "hi"); err == nil {
+		t.Error("expected error for a runtime that does not accept messages")
+	}
+
+	close(eventsCh)
+	doneCh <- nil
+	time.Sleep(100 * time.Millisecond)
+}
+
 func TestManagerStopNonExistent(t *testing.T) {
 	rt := &mockRuntime{}
 	mgr, _ := testManager(rt)
@@ -600,10 +880,10 @@ func TestManagerSystemInitUpdatesModel(t *testing.T) {
 		t.Fatalf("start: %v", err)
 	}
 
-	// Send system/init raw event containing the model name
+	// Send system/init raw event containing the model name and session ID
 	eventsCh <- StreamEvent{
 		Type: EventRaw,
-		Text: `{"type":"system","subtype":"init","model":"claude-opus-4-6","permissionMode":"acceptEdits","tools":["Bash"],"claude_code_version":"2.1.50"}`,
+		Text: `{"type":"system","subtype":"init","session_id":"9f1c2a","model":"claude-opus-4-6","permissionMode":"acceptEdits","tools":["Bash"],"claude_code_version":"2.1.50"}`,
 	}
 	time.Sleep(100 * time.Millisecond)
 
@@ -614,6 +894,9 @@ func TestManagerSystemInitUpdatesModel(t *testing.T) {
 	if r.Model != "claude-opus-4-6" {
 		t.Errorf("expected model %q, got %q", "claude-opus-4-6", r.Model)
 	}
+	if r.SessionID != "9f1c2a" {
+		t.Errorf("expected session ID %q, got %q", "9f1c2a", r.SessionID)
+	}
 
 	close(eventsCh)
 	doneCh <- nil
@@ -663,3 +946,67 @@ func TestManagerDisconnectPreservesRunState(t *testing.T) {
 		t.Error("PID should NOT be zeroed when disconnecting")
 	}
 }
+
+func TestManagerReplayLogFileParsesEachSkillWithItsRuntime(t *testing.T) {
+	mgr, store := testManager(&mockRuntime{})
+
+	claude := `{"type":"assistant","message":{"content":[{"type":"text","text":"Planning it."}]}}` + "\n"
+	codex := `{"type":"item.completed","item":{"id":"item_0","type":"agent_message","text":"Built it."}}` + "\n"
+	dir := t.TempDir()
+	stdoutPath := filepath.Join(dir, "run.stdout")
+	if err := os.WriteFile(stdoutPath, []byte(claude+codex), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runID := store.Add(&run.Run{
+		State:        run.StateCompleted,
+		CurrentSkill: "build",
+		Runtime:      "codex",
+		LogSegments: []run.LogSegment{
+			{Offset: 0, Skill: "plan", Runtime: "claude"},
+			{Offset: int64(len(claude)), Skill: "build", Runtime: "codex"},
+		},
+	})
+
+	mgr.ReplayLogFile(runID, stdoutPath, "")
+
+	var got []string
+	for _, e := range mgr.EntryBuffer(runID).Entries() {
+		got = append(got, e.Skill+": "+e.Summary)
+	}
+	want := []string{"plan: Planning it.", "build: Built it."}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+}
+
+func TestManagerReplayLogFileRecordsNoSpend(t *testing.T) {
+	mgr, store := testManager(&mockRuntime{})
+	ledger := cost.NewLedger(t.TempDir())
+	mgr.SetLedger(ledger)
+
+	result := `{"type":"result","result":"done","usage":{"input_tokens":100,"output_tokens":50},"total_cost_usd":0.25}` + "\n"
+	stdoutPath := filepath.Join(t.TempDir(), "run.stdout")
+	if err := os.WriteFile(stdoutPath, []byte(result), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The restored run already carries the cost of its logged usage.
+	runID := store.Add(&run.Run{State: run.StateCompleted, CurrentSkill: "build", Cost: 0.25, Tokens: 150})
+
+	mgr.ReplayLogFile(runID, stdoutPath, "")
+
+	if mgr.EntryBuffer(runID).Len() == 0 {
+		t.Fatal("expected the result to be replayed into the log")
+	}
+	r, _ := store.Get(runID)
+	if r.Cost != 0.25 || r.Tokens != 150 {
+		t.Errorf("replay changed the run's usage: cost %v, tokens %d", r.Cost, r.Tokens)
+	}
+	spend, err := ledger.Spend(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spend.Day != 0 {
+		t.Errorf("replay recorded $%.2f of spend, want none", spend.Day)
+	}
+}
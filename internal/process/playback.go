@@ -0,0 +1,329 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/run"
+	"github.com/justinpbarnett/agtop/internal/runtime"
+)
+
+// defaultPlaybackInterval paces events of a skill whose duration was not
+// recorded.
+const defaultPlaybackInterval = 250 * time.Millisecond
+
+// PlaybackSkill is the part of a captured stdout log written by one skill
+// launch.
+type PlaybackSkill struct {
+	Name     string
+	Protocol string // output protocol of the runtime that wrote Lines; "" for the Manager's default
+	Lines    [][]byte
+	Duration time.Duration // recorded wall time of the skill; 0 if unknown
+}
+
+// SegmentLog splits a run's captured stdout log at its recorded segments.
+// protocol maps a segment's runtime to the protocol its output is parsed
+// with, so a run that mixed runtimes plays back with the right parser for
+// each skill. A skill's duration runs until the next one started, and the
+// last one's until completed; it is 0 when not recorded.
+func SegmentLog(data []byte, segs []run.LogSegment, completed time.Time, protocol func(runtimeName string) string) []PlaybackSkill {
+	skills := make([]PlaybackSkill, 0, len(segs))
+	for i, seg := range segs {
+		end, until := int64(len(data)), completed
+		if i+1 < len(segs) {
+			end, until = segs[i+1].Offset, segs[i+1].StartedAt
+		}
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		if seg.Offset > end {
+			continue
+		}
+		s := PlaybackSkill{
+			Name:     seg.Skill,
+			Protocol: protocol(seg.Runtime),
+			Lines:    logLines(data[seg.Offset:end]),
+		}
+		if !seg.StartedAt.IsZero() && until.After(seg.StartedAt) {
+			s.Duration = until.Sub(seg.StartedAt)
+		}
+		skills = append(skills, s)
+	}
+	return skills
+}
+
+// SplitLog splits the stdout log of a run saved before segments were
+// recorded into skill launches. Every launch ends with a result event, so the
+// nth segment lines up with the nth entry of the run's SkillCosts, which
+// supplies its name and duration. Lines after the last result (an
+// interrupted skill) form a final segment named after fallback.
+func SplitLog(data []byte, costs []cost.SkillCost, fallback string) []PlaybackSkill {
+	var skills []PlaybackSkill
+	var cur [][]byte
+	flush := func() {
+		i := len(skills)
+		s := PlaybackSkill{Name: fallback, Lines: cur}
+		if i < len(costs) {
+			s.Name = costs[i].SkillName
+			if !costs[i].StartedAt.IsZero() && costs[i].CompletedAt.After(costs[i].StartedAt) {
+				s.Duration = costs[i].CompletedAt.Sub(costs[i].StartedAt)
+			}
+		}
+		skills = append(skills, s)
+		cur = nil
+	}
+
+	for _, line := range logLines(data) {
+		cur = append(cur, line)
+		var msg struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(line, &msg) == nil && msg.Type == "result" {
+			flush()
+		}
+	}
+	if len(cur) > 0 {
+		flush()
+	}
+	return skills
+}
+
+// logLines returns the non-blank lines of a log, trimmed.
+func logLines(data []byte) [][]byte {
+	var lines [][]byte
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte(nil), line...))
+	}
+	return lines
+}
+
+// Playback feeds a captured log back through a Manager so a finished run
+// can be watched unfolding again, cost included. It is the runtime of a
+// Manager dedicated to the replay: each Start plays the next skill.
+type Playback struct {
+	skills []PlaybackSkill
+	speed  float64
+
+	mu       sync.Mutex
+	next     int
+	paused   bool // carried over to the next skill
+	current  *playbackControl
+	controls map[*runtime.Process]*playbackControl
+}
+
+// NewPlayback creates a playback of skills. speed scales the recorded
+// timing; 2 plays twice as fast.
+func NewPlayback(skills []PlaybackSkill, speed float64) *Playback {
+	if speed <= 0 {
+		speed = 1
+	}
+	return &Playback{
+		skills:   skills,
+		speed:    speed,
+		controls: make(map[*runtime.Process]*playbackControl),
+	}
+}
+
+// Play launches every skill of the playback on m for runID, in order, and
+// returns once the last one has been played or playback was stopped.
+func (p *Playback) Play(m *Manager, runID string) error {
+	for i, s := range p.skills {
+		m.store.Update(runID, func(r *run.Run) {
+			r.SkillIndex = i + 1
+			r.CurrentSkill = s.Name
+		})
+		ch, err := m.StartSkill(runID, "", runtime.RunOptions{Skill: s.Name, Runtime: s.Protocol})
+		if err != nil {
+			return err
+		}
+		if res := <-ch; res.Err != nil {
+			return res.Err
+		}
+	}
+	return nil
+}
+
+// Step plays the next event of a paused playback.
+func (p *Playback) Step() {
+	p.mu.Lock()
+	ctl := p.current
+	p.mu.Unlock()
+	if ctl != nil {
+		ctl.step()
+	}
+}
+
+func (p *Playback) Start(_ context.Context, _ string, _ runtime.RunOptions) (*runtime.Process, error) {
+	p.mu.Lock()
+	if p.next >= len(p.skills) {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("playback has no more skills")
+	}
+	s := p.skills[p.next]
+	p.next++
+	ctl := newPlaybackControl()
+	ctl.paused = p.paused
+	p.current = ctl
+	p.mu.Unlock()
+
+	interval := defaultPlaybackInterval
+	if s.Duration > 0 && len(s.Lines) > 0 {
+		interval = s.Duration / time.Duration(len(s.Lines))
+	}
+	interval = time.Duration(float64(interval) / p.speed)
+
+	pr, pw := io.Pipe()
+	doneCh := make(chan error, 1)
+	proc := &runtime.Process{
+		Stdout: pr,
+		Stderr: io.NopCloser(strings.NewReader("")),
+		Done:   doneCh,
+	}
+	p.mu.Lock()
+	p.controls[proc] = ctl
+	p.mu.Unlock()
+
+	go func() {
+		var err error
+		for _, line := range s.Lines {
+			if !ctl.wait(interval) {
+				err = fmt.Errorf("playback stopped")
+				break
+			}
+			if _, err = pw.Write(append(line, '\n')); err != nil {
+				break
+			}
+		}
+		pw.Close()
+		p.mu.Lock()
+		delete(p.controls, proc)
+		p.mu.Unlock()
+		doneCh <- err
+	}()
+	return proc, nil
+}
+
+func (p *Playback) Stop(proc *runtime.Process) error {
+	if ctl := p.control(proc); ctl != nil {
+		ctl.stop()
+	}
+	return nil
+}
+
+func (p *Playback) Pause(proc *runtime.Process) error {
+	p.setPaused(true)
+	if ctl := p.control(proc); ctl != nil {
+		ctl.setPaused(true)
+	}
+	return nil
+}
+
+func (p *Playback) Resume(proc *runtime.Process) error {
+	p.setPaused(false)
+	if ctl := p.control(proc); ctl != nil {
+		ctl.setPaused(false)
+	}
+	return nil
+}
+
+func (p *Playback) setPaused(paused bool) {
+	p.mu.Lock()
+	p.paused = paused
+	p.mu.Unlock()
+}
+
+func (p *Playback) control(proc *runtime.Process) *playbackControl {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.controls[proc]
+}
+
+// playbackControl paces one skill's events and lets the TUI pause, step
+// and stop it.
+type playbackControl struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	paused  bool
+	steps   int
+	stopped bool
+}
+
+func newPlaybackControl() *playbackControl {
+	c := &playbackControl{}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// wait returns when the next event is due: after interval while playing,
+// or on a step while paused. It returns false once stopped.
+func (c *playbackControl) wait(interval time.Duration) bool {
+	deadline := time.Now().Add(interval)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		if c.stopped {
+			return false
+		}
+		if c.paused {
+			if c.steps > 0 {
+				c.steps--
+				return true
+			}
+			c.cond.Wait()
+			continue
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return true
+		}
+		t := time.AfterFunc(remaining, c.wake)
+		c.cond.Wait()
+		t.Stop()
+	}
+}
+
+// wake takes the lock before broadcasting so a waiter that has not yet
+// reached cond.Wait cannot miss it.
+func (c *playbackControl) wake() {
+	c.mu.Lock()
+	c.mu.Unlock()
+	c.cond.Broadcast()
+}
+
+func (c *playbackControl) setPaused(paused bool) {
+	c.mu.Lock()
+	c.paused = paused
+	c.steps = 0
+	c.mu.Unlock()
+	c.cond.Broadcast()
+}
+
+func (c *playbackControl) step() {
+	c.mu.Lock()
+	if c.paused {
+		c.steps++
+	}
+	c.mu.Unlock()
+	c.cond.Broadcast()
+}
+
+func (c *playbackControl) stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+	c.cond.Broadcast()
+}
@@ -0,0 +1,170 @@
+package process
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/run"
+	"github.com/justinpbarnett/agtop/internal/runtime"
+)
+
+const playbackLog = `{"type":"assistant","message":{"content":[{"type":"text","text":"Writing the spec"}]}}
+{"type":"result","result":"spec done","usage":{"input_tokens":100,"output_tokens":20},"total_cost_usd":0.02}
+
+{"type":"assistant","message":{"content":[{"type":"text","text":"Building"}]}}
+{"type":"result","result":"build done","usage":{"input_tokens":200,"output_tokens":80},"total_cost_usd":0.05}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Reviewing"}]}}
+`
+
+func TestSplitLog(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	costs := []cost.SkillCost{
+		{SkillName: "spec", StartedAt: start, CompletedAt: start.Add(10 * time.Second)},
+		{SkillName: "build"},
+	}
+
+	skills := SplitLog([]byte(playbackLog), costs, "review")
+	if len(skills) != 3 {
+		t.Fatalf("expected 3 skills, got %d", len(skills))
+	}
+	want := []struct {
+		name  string
+		lines int
+		dur   time.Duration
+	}{
+		{"spec", 2, 10 * time.Second},
+		{"build", 2, 0},
+		{"review", 1, 0},
+	}
+	for i, w := range want {
+		s := skills[i]
+		if s.Name != w.name || len(s.Lines) != w.lines || s.Duration != w.dur {
+			t.Errorf("skill %d = {%s, %d lines, %s}, want {%s, %d lines, %s}", i, s.Name, len(s.Lines), s.Duration, w.name, w.lines, w.dur)
+		}
+	}
+}
+
+func TestSegmentLogMixedRuntimes(t *testing.T) {
+	claudeLog := `{"type":"assistant","message":{"content":[{"type":"text","text":"Writing the spec"}]}}
+{"type":"result","result":"spec done","usage":{"input_tokens":100,"output_tokens":20},"total_cost_usd":0.02}
+`
+	codexLog := `{"type":"thread.started","thread_id":"t1"}
+{"type":"item.completed","item":{"id":"item_0","type":"agent_message","text":"Built it."}}
+{"type":"turn.completed","usage":{"input_tokens":300,"output_tokens":40}}
+`
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	segs := []run.LogSegment{
+		{Offset: 0, Skill: "spec", Runtime: "claude", StartedAt: start},
+		{Offset: int64(len(claudeLog)), Skill: "build", Runtime: "codex", StartedAt: start.Add(10 * time.Second)},
+	}
+	protocol := func(name string) string {
+		if name == "codex" {
+			return runtime.ProtocolCodex
+		}
+		return runtime.ProtocolStreamJSON
+	}
+
+	skills := SegmentLog([]byte(claudeLog+codexLog), segs, start.Add(40*time.Second), protocol)
+	if len(skills) != 2 {
+		t.Fatalf("expected 2 skills, got %d", len(skills))
+	}
+	want := []struct {
+		name, protocol string
+		lines          int
+		dur            time.Duration
+	}{
+		{"spec", runtime.ProtocolStreamJSON, 2, 10 * time.Second},
+		{"build", runtime.ProtocolCodex, 3, 30 * time.Second},
+	}
+	for i, w := range want {
+		s := skills[i]
+		if s.Name != w.name || s.Protocol != w.protocol || len(s.Lines) != w.lines || s.Duration != w.dur {
+			t.Errorf("skill %d = {%s, %s, %d lines, %s}, want {%s, %s, %d lines, %s}",
+				i, s.Name, s.Protocol, len(s.Lines), s.Duration, w.name, w.protocol, w.lines, w.dur)
+		}
+	}
+
+	pb := NewPlayback(skills, 1000)
+	mgr, store := testManager(pb)
+	mgr.AddRuntime(runtime.ProtocolStreamJSON, pb)
+	mgr.AddRuntime(runtime.ProtocolCodex, pb)
+	runID := store.Add(&run.Run{State: run.StateRunning})
+
+	if err := pb.Play(mgr, runID); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+
+	r, _ := store.Get(runID)
+	if len(r.SkillCosts) != 2 || r.SkillCosts[0].SkillName != "spec" || r.SkillCosts[1].SkillName != "build" {
+		t.Fatalf("expected spec and build costs, got %+v", r.SkillCosts)
+	}
+	if r.SkillCosts[1].InputTokens != 300 || r.SkillCosts[1].OutputTokens != 40 {
+		t.Errorf("expected the codex usage on build, got %+v", r.SkillCosts[1])
+	}
+	tail := strings.Join(mgr.Buffer(runID).Tail(20), "\n")
+	for _, want := range []string{"Writing the spec", "Built it."} {
+		if !strings.Contains(tail, want) {
+			t.Errorf("expected %q in log buffer:\n%s", want, tail)
+		}
+	}
+}
+
+func TestPlaybackPlaysThroughManager(t *testing.T) {
+	skills := SplitLog([]byte(playbackLog), []cost.SkillCost{{SkillName: "spec"}, {SkillName: "build"}}, "review")
+	pb := NewPlayback(skills, 1000)
+	mgr, store := testManager(pb)
+	runID := store.Add(&run.Run{State: run.StateRunning})
+
+	if err := pb.Play(mgr, runID); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+
+	r, _ := store.Get(runID)
+	if r.CurrentSkill != "review" || r.SkillIndex != 3 {
+		t.Errorf("expected to end on skill 3 (review), got %d (%s)", r.SkillIndex, r.CurrentSkill)
+	}
+	if len(r.SkillCosts) != 2 || r.SkillCosts[0].SkillName != "spec" || r.SkillCosts[1].SkillName != "build" {
+		t.Errorf("expected spec and build costs, got %+v", r.SkillCosts)
+	}
+	if r.Cost < 0.07-1e-9 || r.Cost > 0.07+1e-9 {
+		t.Errorf("expected cost 0.07, got %f", r.Cost)
+	}
+	tail := strings.Join(mgr.Buffer(runID).Tail(20), "\n")
+	for _, want := range []string{"Writing the spec", "Building", "Reviewing"} {
+		if !strings.Contains(tail, want) {
+			t.Errorf("expected %q in log buffer:\n%s", want, tail)
+		}
+	}
+}
+
+func TestPlaybackPauseAndStep(t *testing.T) {
+	skills := SplitLog([]byte(playbackLog), nil, "build")
+	pb := NewPlayback(skills[:1], 1)
+	mgr, store := testManager(pb)
+	runID := store.Add(&run.Run{State: run.StateRunning, CurrentSkill: "build"})
+
+	pb.setPaused(true)
+	ch, err := mgr.StartSkill(runID, "", runtime.RunOptions{})
+	if err != nil {
+		t.Fatalf("StartSkill: %v", err)
+	}
+
+	select {
+	case <-ch:
+		t.Fatal("paused playback should not finish")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	pb.Step()
+	pb.Step()
+	select {
+	case res := <-ch:
+		if res.ResultText != "spec done" {
+			t.Errorf("expected result after two steps, got %q", res.ResultText)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stepping did not play the remaining events")
+	}
+}
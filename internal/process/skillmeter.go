@@ -0,0 +1,148 @@
+package process
+
+import (
+	"fmt"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+// WindDownPrompt is sent to an agent whose skill has reached its cost or
+// turn limit.
+const WindDownPrompt = "You have reached the limit for this step. Stop starting new work: " +
+	"commit what you have, then finish with a short summary of what is done and what is left."
+
+// skillMeter follows one skill process against its limit. Only the event
+// loop of the process touches it.
+type skillMeter struct {
+	limit    cost.SkillLimit
+	usage    UsageData // summed from EventUsage, for runtimes that report it
+	turns    int
+	inTurn   bool // the last top-level event was a tool call
+	winding  bool // the agent was asked to wrap up
+	stopped  bool // the skill was stopped at its limit
+	resulted bool // the process reported a result with its usage
+	unpriced bool // the usage cannot be priced; warned about once
+}
+
+// SetSkillLimits sets the per-skill cost and turn limits from the [skills]
+// config. Skills without limits are not metered.
+func (m *Manager) SetSkillLimits(skills map[string]config.SkillConfig) {
+	limits := make(map[string]cost.SkillLimit)
+	for name, sc := range skills {
+		if l := (cost.SkillLimit{MaxCost: sc.MaxCost, MaxTurns: sc.MaxTurns}); l.Enabled() {
+			limits[name] = l
+		}
+	}
+	m.mu.Lock()
+	m.skillLimits = limits
+	m.mu.Unlock()
+}
+
+// meterSkill counts event against the limit of mp's skill. A turn is a model
+// response that calls tools, so parallel calls count once; calls of Task
+// sub-agents do not count. Cost is the usage reported so far priced with
+// the pricing table; a model the table has no rates for is warned about
+// once, as its cost cannot be metered. Once the limit is reached the agent is asked to wrap
+// up. The skill is stopped, and its result marked partial, when it goes on
+// past the grace allowance or its runtime cannot take the message.
+func (m *Manager) meterSkill(runID string, mp *ManagedProcess, event StreamEvent, ts, skill string, buf *RingBuffer) {
+	mt := &mp.meter
+	switch event.Type {
+	case EventUsage:
+		mt.usage.InputTokens += event.Usage.InputTokens
+		mt.usage.OutputTokens += event.Usage.OutputTokens
+		mt.usage.TotalTokens += event.Usage.TotalTokens
+		mt.usage.CacheReadTokens += event.Usage.CacheReadTokens
+		mt.usage.CacheCreationTokens += event.Usage.CacheCreationTokens
+	case EventResult:
+		mt.resulted = mt.resulted || event.Usage != nil
+	case EventToolUse:
+		if event.ParentID == "" && !mt.inTurn {
+			mt.turns++
+			mt.inTurn = true
+		}
+	case EventToolResult, EventText, EventThinking:
+		if event.ParentID == "" {
+			mt.inTurn = false
+		}
+	}
+	if !mt.limit.Enabled() || mt.stopped {
+		return
+	}
+
+	limit := mt.limit
+	if mt.winding {
+		limit = limit.WithGrace()
+	}
+	spent, model, priced := m.meteredCost(mp)
+	if !priced && limit.MaxCost > 0 && mt.usage.TotalTokens > 0 && !mt.unpriced {
+		mt.unpriced = true
+		m.warn(runID, buf, logLine(ts, skill, "WARNING: ", fmt.Sprintf("no pricing for model %q, the skill's max_cost cannot be metered", model)))
+	}
+	exceeded, reason := limit.Check(spent, mt.turns)
+	if !exceeded {
+		return
+	}
+
+	if !mt.winding {
+		mt.winding = true
+		m.warn(runID, buf, logLine(ts, skill, "WARNING: ", reason+", asking the agent to wrap up"))
+		err := m.Interject(runID, WindDownPrompt)
+		if err == nil {
+			return
+		}
+		reason = fmt.Sprintf("cannot ask the agent to wrap up (%v)", err)
+	} else {
+		reason += " after the request to wrap up"
+	}
+	mt.stopped = true
+	m.warn(runID, buf, logLine(ts, skill, "WARNING: ", reason+", stopping the skill with a partial result"))
+	// Stopping can wait for the process to exit, which needs this loop to
+	// keep draining its output.
+	go func() { _ = m.Stop(runID) }()
+}
+
+// meteredCost prices the usage metered for mp so far with the rates of its
+// model, or of the run's when it has none. It reports false when the model
+// has no rates.
+func (m *Manager) meteredCost(mp *ManagedProcess) (spent float64, model string, priced bool) {
+	model = mp.model
+	if model == "" {
+		if r, ok := m.store.Get(mp.runID); ok {
+			model = r.Model
+		}
+	}
+	m.mu.Lock()
+	rates, ok := m.pricing.Lookup(model)
+	m.mu.Unlock()
+	if !ok {
+		return 0, model, false
+	}
+	u := mp.meter.usage
+	return rates.Cost(u.InputTokens, u.OutputTokens, u.CacheReadTokens, u.CacheCreationTokens), model, true
+}
+
+// recordPartial records the cost entry of a skill stopped at its limit,
+// marked partial. A skill stopped before it reported usage is charged the
+// usage metered so far.
+func (m *Manager) recordPartial(runID string, mp *ManagedProcess, ts, skill string, buf *RingBuffer) {
+	if mp.meter.resulted {
+		m.store.Update(runID, func(r *run.Run) {
+			if n := len(r.SkillCosts); n > 0 {
+				r.SkillCosts[n-1].Partial = true
+			}
+		})
+		return
+	}
+	u := mp.meter.usage
+	sc := m.skillCost(runID, skill, &u)
+	sc.Partial = true
+	m.recordSkillCost(runID, sc, ts, buf)
+}
+
+func (m *Manager) warn(runID string, buf *RingBuffer, line string) {
+	buf.Append(line)
+	m.sendLogLine(runID)
+}
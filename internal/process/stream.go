@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"strings"
+	"time"
 )
 
 type StreamEventType string
@@ -17,7 +18,13 @@ const (
 	EventResult     StreamEventType = "result"
 	EventError      StreamEventType = "error"
 	EventUser       StreamEventType = "user"
+	EventThinking   StreamEventType = "thinking"
 	EventRaw        StreamEventType = "raw"
+
+	// EventUsage carries the usage of one model response while a skill
+	// runs. Unlike the usage of EventResult, it is not recorded as cost;
+	// it only meters the skill against its limits.
+	EventUsage StreamEventType = "usage"
 )
 
 type StreamEvent struct {
@@ -25,40 +32,139 @@ type StreamEvent struct {
 	Text      string
 	ToolName  string
 	ToolInput string
-	Usage     *UsageData
+	ToolID    string // id of a tool_use; on a tool_result, the id of the call it answers
+	ParentID  string // for events of a Task sub-agent, the id of the tool_use that started it
+	IsError   bool   // the tool_result reports a failed call
+	// Elapsed is the duration of the call answered by a tool_result, for
+	// runtimes that report a tool call only once it has finished.
+	Elapsed time.Duration
+	Usage   *UsageData
 }
 
+// UsageData is the usage reported at the end of a skill. TotalTokens counts
+// input and output tokens only; prompt-cache tokens are reported separately
+// because they are billed at different rates.
 type UsageData struct {
-	InputTokens  int
-	OutputTokens int
-	TotalTokens  int
-	CostUSD      float64
+	InputTokens         int     `json:"input_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	TotalTokens         int     `json:"total_tokens"`
+	CacheCreationTokens int     `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int     `json:"cache_read_tokens,omitempty"`
+	CostUSD             float64 `json:"cost_usd"`
+}
+
+// CacheHitRate returns the share of prompt tokens read from the cache.
+func (u *UsageData) CacheHitRate() float64 {
+	prompt := u.InputTokens + u.CacheCreationTokens + u.CacheReadTokens
+	if prompt == 0 {
+		return 0
+	}
+	return float64(u.CacheReadTokens) / float64(prompt)
 }
 
 // JSON structures for Claude Code stream-json format.
 
 type streamMessage struct {
-	Type    string         `json:"type"`
-	Message *streamContent `json:"message,omitempty"`
-	Result  string         `json:"result,omitempty"`
-	Usage   *streamUsage   `json:"usage,omitempty"`
-	CostUSD float64        `json:"total_cost_usd,omitempty"`
+	Type            string         `json:"type"`
+	Message         *streamContent `json:"message,omitempty"`
+	Result          string         `json:"result,omitempty"`
+	Usage           *streamUsage   `json:"usage,omitempty"`
+	CostUSD         float64        `json:"total_cost_usd,omitempty"`
+	ParentToolUseID string         `json:"parent_tool_use_id,omitempty"`
 }
 
 type streamContent struct {
+	ID      string         `json:"id,omitempty"`
 	Content []contentBlock `json:"content"`
+	Usage   *streamUsage   `json:"usage,omitempty"`
 }
 
 type contentBlock struct {
-	Type  string          `json:"type"`
-	Text  string          `json:"text,omitempty"`
-	Name  string          `json:"name,omitempty"`
-	Input json.RawMessage `json:"input,omitempty"`
+	Type      string          `json:"type"`
+	ID        string          `json:"id,omitempty"`
+	Text      string          `json:"text,omitempty"`
+	Thinking  string          `json:"thinking,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
 }
 
 type streamUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// usageData converts reported usage; total excludes cache tokens.
+func (u *streamUsage) usageData(costUSD float64) *UsageData {
+	return &UsageData{
+		InputTokens:         u.InputTokens,
+		OutputTokens:        u.OutputTokens,
+		TotalTokens:         u.InputTokens + u.OutputTokens,
+		CacheCreationTokens: u.CacheCreationInputTokens,
+		CacheReadTokens:     u.CacheReadInputTokens,
+		CostUSD:             costUSD,
+	}
+}
+
+// contentEvent converts an assistant content block, or returns false for
+// blocks that produce no event.
+func contentEvent(block contentBlock, parentID string) (StreamEvent, bool) {
+	switch block.Type {
+	case "text":
+		return StreamEvent{Type: EventText, Text: block.Text, ParentID: parentID}, true
+	case "thinking":
+		return StreamEvent{Type: EventThinking, Text: block.Thinking, ParentID: parentID}, true
+	case "redacted_thinking":
+		return StreamEvent{Type: EventThinking, Text: "(redacted)", ParentID: parentID}, true
+	case "tool_use":
+		return StreamEvent{
+			Type:      EventToolUse,
+			ToolName:  block.Name,
+			ToolInput: string(block.Input),
+			ToolID:    block.ID,
+			ParentID:  parentID,
+		}, true
+	case "tool_result":
+		text := block.Text
+		if text == "" {
+			text = toolResultText(block.Content)
+		}
+		return StreamEvent{
+			Type:     EventToolResult,
+			Text:     text,
+			ToolID:   block.ToolUseID,
+			ParentID: parentID,
+			IsError:  block.IsError,
+		}, true
+	}
+	return StreamEvent{}, false
+}
+
+// toolResultText extracts the text of a tool_result's content, which is
+// either a string or a list of text blocks.
+func toolResultText(content json.RawMessage) string {
+	if len(content) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(content, &s) == nil {
+		return s
+	}
+	var blocks []contentBlock
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
 }
 
 // EventStream is the interface consumed by the process manager.
@@ -72,6 +178,10 @@ type StreamParser struct {
 	reader io.Reader
 	events chan StreamEvent
 	done   chan error
+	lastID string // id of the last assistant message whose usage was sent
+	// reported is the session total of the last result, which the next
+	// result's usage is reported relative to.
+	reported UsageData
 }
 
 func NewStreamParser(r io.Reader, bufSize int) *StreamParser {
@@ -119,37 +229,43 @@ func (p *StreamParser) Parse(ctx context.Context) {
 
 		switch msg.Type {
 		case "assistant":
+			// Each content block of a message arrives on its own line,
+			// repeating the message's usage; send it once.
+			if m := msg.Message; m != nil && m.Usage != nil && m.ID != "" && m.ID != p.lastID {
+				p.lastID = m.ID
+				p.send(ctx, StreamEvent{Type: EventUsage, Usage: m.Usage.usageData(0), ParentID: msg.ParentToolUseID})
+			}
 			if msg.Message != nil {
 				for _, block := range msg.Message.Content {
-					switch block.Type {
-					case "text":
-						p.send(ctx, StreamEvent{Type: EventText, Text: block.Text})
-					case "tool_use":
-						p.send(ctx, StreamEvent{
-							Type:      EventToolUse,
-							ToolName:  block.Name,
-							ToolInput: string(block.Input),
-						})
-					case "tool_result":
-						p.send(ctx, StreamEvent{Type: EventToolResult, Text: block.Text})
+					if event, ok := contentEvent(block, msg.ParentToolUseID); ok {
+						p.send(ctx, event)
 					}
 				}
 			}
 		case "user":
+			// Tool results come back as user messages.
+			var results bool
+			if msg.Message != nil {
+				for _, block := range msg.Message.Content {
+					if block.Type != "tool_result" {
+						continue
+					}
+					event, _ := contentEvent(block, msg.ParentToolUseID)
+					p.send(ctx, event)
+					results = true
+				}
+			}
+			if results {
+				break
+			}
 			text := extractMessageContent(line, msg)
 			if text != "" {
-				p.send(ctx, StreamEvent{Type: EventUser, Text: text})
+				p.send(ctx, StreamEvent{Type: EventUser, Text: text, ParentID: msg.ParentToolUseID})
 			}
 		case "result":
 			event := StreamEvent{Type: EventResult, Text: msg.Result}
 			if msg.Usage != nil {
-				total := msg.Usage.InputTokens + msg.Usage.OutputTokens
-				event.Usage = &UsageData{
-					InputTokens:  msg.Usage.InputTokens,
-					OutputTokens: msg.Usage.OutputTokens,
-					TotalTokens:  total,
-					CostUSD:      msg.CostUSD,
-				}
+				event.Usage = p.resultUsage(msg.Usage.usageData(msg.CostUSD))
 			}
 			p.send(ctx, event)
 		case "rate_limit_event":
@@ -176,6 +292,23 @@ func (p *StreamParser) Parse(ctx context.Context) {
 	}
 }
 
+// resultUsage returns the usage of a result not already reported by an
+// earlier one. A conversation that answers more than one message, such as an
+// interjection or a request to wrap up, ends each answer with a result whose
+// usage and cost are totals for the whole session.
+func (p *StreamParser) resultUsage(total *UsageData) *UsageData {
+	prev := p.reported
+	p.reported = *total
+	return &UsageData{
+		InputTokens:         total.InputTokens - prev.InputTokens,
+		OutputTokens:        total.OutputTokens - prev.OutputTokens,
+		TotalTokens:         total.TotalTokens - prev.TotalTokens,
+		CacheCreationTokens: total.CacheCreationTokens - prev.CacheCreationTokens,
+		CacheReadTokens:     total.CacheReadTokens - prev.CacheReadTokens,
+		CostUSD:             total.CostUSD - prev.CostUSD,
+	}
+}
+
 func (p *StreamParser) send(ctx context.Context, event StreamEvent) {
 	select {
 	case <-ctx.Done():
@@ -0,0 +1,171 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Aider prints plain text rather than JSON. With --no-pretty a run looks like:
+//
+//    Aider v0.86.1
+//    Main model: anthropic/claude-sonnet-4 with diff edit format
+//    Git repo: .git with 42 files
+//    ...model reply...
+//    Applied edit to internal/foo.go
+//    Tokens: 4.2k sent, 312 received. Cost: $0.02 message, $0.02 session.
+//
+// Every non-banner line is text; the reply is reported again as the result
+// once the stream ends, with the usage summed over all token lines.
+
+var (
+	aiderTokensRe = regexp.MustCompile(`^Tokens: ([\d.]+[kM]?) sent(?:, [\d.]+[kM]? cache \w+)*, ([\d.]+[kM]?) received\.(?: Cost: \$([\d.]+) message)?`)
+	aiderCacheRe  = regexp.MustCompile(`, ([\d.]+[kM]?) cache (write|hit)`)
+	aiderEditRe   = regexp.MustCompile(`^Applied edit to (.+)$`)
+)
+
+// aiderBannerPrefixes mark the startup lines aider prints before the reply.
+var aiderBannerPrefixes = []string{
+	"Aider v",
+	"Main model:",
+	"Weak model:",
+	"Editor model:",
+	"Model:",
+	"Git repo:",
+	"Repo-map:",
+	"Use /help",
+	"Added ",
+	"https://aider.chat",
+}
+
+// AiderStreamParser translates aider's console output into StreamEvent values.
+type AiderStreamParser struct {
+	reader io.Reader
+	events chan StreamEvent
+	done   chan error
+}
+
+func NewAiderStreamParser(r io.Reader, bufSize int) *AiderStreamParser {
+	if bufSize <= 0 {
+		bufSize = 256
+	}
+	return &AiderStreamParser{
+		reader: r,
+		events: make(chan StreamEvent, bufSize),
+		done:   make(chan error, 1),
+	}
+}
+
+func (p *AiderStreamParser) Parse(ctx context.Context) {
+	defer close(p.events)
+
+	scanner := bufio.NewScanner(p.reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var reply []string
+	var usage *UsageData
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			p.done <- ctx.Err()
+			return
+		default:
+		}
+
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if m := aiderTokensRe.FindStringSubmatch(line); m != nil {
+			if usage == nil {
+				usage = &UsageData{}
+			}
+			// Aider's sent count includes cache writes and hits.
+			in, out := parseAiderCount(m[1]), parseAiderCount(m[2])
+			for _, c := range aiderCacheRe.FindAllStringSubmatch(line, -1) {
+				n := parseAiderCount(c[1])
+				if c[2] == "hit" {
+					usage.CacheReadTokens += n
+				} else {
+					usage.CacheCreationTokens += n
+				}
+				in -= n
+			}
+			if in < 0 {
+				in = 0
+			}
+			usage.InputTokens += in
+			usage.OutputTokens += out
+			usage.TotalTokens += in + out
+			if m[3] != "" {
+				c, _ := strconv.ParseFloat(m[3], 64)
+				usage.CostUSD += c
+			}
+			continue
+		}
+		if m := aiderEditRe.FindStringSubmatch(line); m != nil {
+			p.send(ctx, StreamEvent{Type: EventToolUse, ToolName: "Edit", ToolInput: jsonInput("file_path", m[1])})
+			continue
+		}
+		if isAiderBanner(line) {
+			p.send(ctx, StreamEvent{Type: EventRaw, Text: line})
+			continue
+		}
+		reply = append(reply, line)
+		p.send(ctx, StreamEvent{Type: EventText, Text: line})
+	}
+
+	if err := scanner.Err(); err != nil {
+		p.done <- err
+		return
+	}
+	if len(reply) > 0 || usage != nil {
+		p.send(ctx, StreamEvent{Type: EventResult, Text: strings.Join(reply, "\n"), Usage: usage})
+	}
+	p.done <- nil
+}
+
+func isAiderBanner(line string) bool {
+	for _, prefix := range aiderBannerPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAiderCount parses aider's abbreviated token counts: 312, 4.2k, 1.1M.
+func parseAiderCount(s string) int {
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mult, s = 1e3, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "M"):
+		mult, s = 1e6, strings.TrimSuffix(s, "M")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int(v*mult + 0.5)
+}
+
+func (p *AiderStreamParser) send(ctx context.Context, event StreamEvent) {
+	select {
+	case <-ctx.Done():
+	case p.events <- event:
+	}
+}
+
+func (p *AiderStreamParser) Events() <-chan StreamEvent {
+	return p.events
+}
+
+func (p *AiderStreamParser) Done() <-chan error {
+	return p.done
+}
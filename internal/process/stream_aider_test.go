@@ -0,0 +1,84 @@
+package process
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func collectAiderEvents(t *testing.T, parser *AiderStreamParser, ctx context.Context) []StreamEvent {
+	t.Helper()
+	go parser.Parse(ctx)
+
+	var events []StreamEvent
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event, ok := <-parser.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("timeout waiting for parser to finish")
+			return events
+		}
+	}
+}
+
+func TestAiderParseSession(t *testing.T) {
+	input := strings.Join([]string{
+		"Aider v0.86.1",
+		"Main model: anthropic/claude-sonnet-4-20250514 with diff edit format",
+		"Git repo: .git with 42 files",
+		"",
+		"I'll add the missing nil check.",
+		"Applied edit to internal/foo.go",
+		"Tokens: 4.2k sent, 312 received. Cost: $0.02 message, $0.02 session.",
+		"The check is in place.",
+		"Tokens: 1.5k sent, 1.2k cache hit, 80 received. Cost: $0.0051 message, $0.03 session.",
+	}, "\n") + "\n"
+
+	events := collectAiderEvents(t, NewAiderStreamParser(strings.NewReader(input), 20), context.Background())
+
+	var types []StreamEventType
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	want := []StreamEventType{EventRaw, EventRaw, EventRaw, EventText, EventToolUse, EventText, EventResult}
+	if len(types) != len(want) {
+		t.Fatalf("expected %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, types)
+		}
+	}
+
+	edit := events[4]
+	if edit.ToolName != "Edit" || edit.ToolInput != `{"file_path":"internal/foo.go"}` {
+		t.Errorf("unexpected edit event: %+v", edit)
+	}
+
+	result := events[6]
+	if result.Text != "I'll add the missing nil check.\nThe check is in place." {
+		t.Errorf("unexpected result text: %q", result.Text)
+	}
+	u := result.Usage
+	if u == nil || u.InputTokens != 4500 || u.OutputTokens != 392 || u.TotalTokens != 4892 || u.CacheReadTokens != 1200 {
+		t.Fatalf("unexpected usage: %+v", u)
+	}
+	if u.CostUSD < 0.0250 || u.CostUSD > 0.0252 {
+		t.Errorf("CostUSD = %f, want 0.0251", u.CostUSD)
+	}
+}
+
+func TestParseAiderCount(t *testing.T) {
+	tests := map[string]int{"312": 312, "4.2k": 4200, "1.1M": 1100000, "x": 0}
+	for in, want := range tests {
+		if got := parseAiderCount(in); got != want {
+			t.Errorf("parseAiderCount(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
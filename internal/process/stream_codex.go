@@ -0,0 +1,225 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Codex parser handles the JSON Lines that `codex exec --json` prints:
+//
+//    {"type":"thread.started","thread_id":"..."}
+//    {"type":"turn.started"}
+//    {"type":"item.started","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","status":"in_progress"}}
+//    {"type":"item.completed","item":{"id":"item_1","type":"command_execution","aggregated_output":"...","exit_code":0}}
+//    {"type":"item.completed","item":{"id":"item_2","type":"agent_message","text":"..."}}
+//    {"type":"turn.completed","usage":{"input_tokens":24763,"cached_input_tokens":24448,"output_tokens":122}}
+//
+// Codex reports no cost, so results carry token counts only.
+
+type cxEvent struct {
+	Type    string          `json:"type"`
+	Item    *cxItem         `json:"item,omitempty"`
+	Usage   *cxUsage        `json:"usage,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+type cxItem struct {
+	ID               string     `json:"id"`
+	Type             string     `json:"type"`
+	Text             string     `json:"text,omitempty"`
+	Command          string     `json:"command,omitempty"`
+	AggregatedOutput string     `json:"aggregated_output,omitempty"`
+	Changes          []cxChange `json:"changes,omitempty"`
+	Server           string     `json:"server,omitempty"`
+	Tool             string     `json:"tool,omitempty"`
+	Query            string     `json:"query,omitempty"`
+	Message          string     `json:"message,omitempty"`
+	ExitCode         *int       `json:"exit_code,omitempty"`
+	Status           string     `json:"status,omitempty"`
+}
+
+type cxChange struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+}
+
+type cxUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+}
+
+// CodexStreamParser translates Codex CLI JSON events into StreamEvent values.
+type CodexStreamParser struct {
+	reader io.Reader
+	events chan StreamEvent
+	done   chan error
+
+	started     map[string]bool // items whose tool use was already reported
+	lastMessage string
+}
+
+func NewCodexStreamParser(r io.Reader, bufSize int) *CodexStreamParser {
+	if bufSize <= 0 {
+		bufSize = 256
+	}
+	return &CodexStreamParser{
+		reader:  r,
+		events:  make(chan StreamEvent, bufSize),
+		done:    make(chan error, 1),
+		started: make(map[string]bool),
+	}
+}
+
+func (p *CodexStreamParser) Parse(ctx context.Context) {
+	defer close(p.events)
+
+	scanner := bufio.NewScanner(p.reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			p.done <- ctx.Err()
+			return
+		default:
+		}
+
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var ev cxEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			p.send(ctx, StreamEvent{Type: EventRaw, Text: line})
+			continue
+		}
+
+		switch ev.Type {
+		case "thread.started", "turn.started":
+			// Lifecycle markers — skip silently
+
+		case "item.started":
+			if ev.Item == nil {
+				p.send(ctx, StreamEvent{Type: EventRaw, Text: line})
+				continue
+			}
+			p.toolUse(ctx, ev.Item)
+
+		case "item.completed":
+			if ev.Item == nil {
+				p.send(ctx, StreamEvent{Type: EventRaw, Text: line})
+				continue
+			}
+			p.itemCompleted(ctx, ev.Item)
+
+		case "turn.completed":
+			event := StreamEvent{Type: EventResult, Text: p.lastMessage}
+			if ev.Usage != nil {
+				// Codex counts cached tokens as part of input_tokens.
+				input := ev.Usage.InputTokens - ev.Usage.CachedInputTokens
+				event.Usage = &UsageData{
+					InputTokens:     input,
+					OutputTokens:    ev.Usage.OutputTokens,
+					TotalTokens:     input + ev.Usage.OutputTokens,
+					CacheReadTokens: ev.Usage.CachedInputTokens,
+				}
+			}
+			p.lastMessage = ""
+			p.send(ctx, event)
+
+		case "turn.failed":
+			var errEv struct {
+				Message string `json:"message"`
+			}
+			text := line
+			if json.Unmarshal(ev.Error, &errEv) == nil && errEv.Message != "" {
+				text = errEv.Message
+			}
+			p.send(ctx, StreamEvent{Type: EventError, Text: text})
+
+		case "error":
+			text := ev.Message
+			if text == "" {
+				text = line
+			}
+			p.send(ctx, StreamEvent{Type: EventError, Text: text})
+
+		default:
+			p.send(ctx, StreamEvent{Type: EventRaw, Text: line})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		p.done <- err
+	} else {
+		p.done <- nil
+	}
+}
+
+// toolUse reports the tool call an item stands for, once per item.
+func (p *CodexStreamParser) toolUse(ctx context.Context, item *cxItem) {
+	if item.ID != "" {
+		if p.started[item.ID] {
+			return
+		}
+		p.started[item.ID] = true
+	}
+	switch item.Type {
+	case "command_execution":
+		p.send(ctx, StreamEvent{Type: EventToolUse, ToolName: "Bash", ToolInput: jsonInput("command", item.Command), ToolID: item.ID})
+	case "mcp_tool_call":
+		p.send(ctx, StreamEvent{Type: EventToolUse, ToolName: item.Server + "." + item.Tool, ToolID: item.ID})
+	case "web_search":
+		p.send(ctx, StreamEvent{Type: EventToolUse, ToolName: "WebSearch", ToolInput: jsonInput("query", item.Query), ToolID: item.ID})
+	}
+}
+
+func (p *CodexStreamParser) itemCompleted(ctx context.Context, item *cxItem) {
+	switch item.Type {
+	case "agent_message":
+		p.lastMessage = item.Text
+		p.send(ctx, StreamEvent{Type: EventText, Text: item.Text})
+	case "reasoning":
+		p.send(ctx, StreamEvent{Type: EventText, Text: item.Text})
+	case "command_execution":
+		p.toolUse(ctx, item)
+		failed := item.Status == "failed" || (item.ExitCode != nil && *item.ExitCode != 0)
+		p.send(ctx, StreamEvent{Type: EventToolResult, Text: item.AggregatedOutput, ToolID: item.ID, IsError: failed})
+	case "mcp_tool_call", "web_search":
+		p.toolUse(ctx, item)
+		p.send(ctx, StreamEvent{Type: EventToolResult, ToolID: item.ID, IsError: item.Status == "failed"})
+	case "file_change":
+		for _, c := range item.Changes {
+			p.send(ctx, StreamEvent{Type: EventToolUse, ToolName: "Edit", ToolInput: jsonInput("file_path", c.Path)})
+		}
+	case "error":
+		p.send(ctx, StreamEvent{Type: EventError, Text: item.Message})
+	}
+}
+
+// jsonInput encodes a single-field tool input the way Claude Code reports it.
+func jsonInput(key, value string) string {
+	data, _ := json.Marshal(map[string]string{key: value})
+	return string(data)
+}
+
+func (p *CodexStreamParser) send(ctx context.Context, event StreamEvent) {
+	select {
+	case <-ctx.Done():
+	case p.events <- event:
+	}
+}
+
+func (p *CodexStreamParser) Events() <-chan StreamEvent {
+	return p.events
+}
+
+func (p *CodexStreamParser) Done() <-chan error {
+	return p.done
+}
@@ -0,0 +1,114 @@
+package process
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func collectCodexEvents(t *testing.T, parser *CodexStreamParser, ctx context.Context) []StreamEvent {
+	t.Helper()
+	go parser.Parse(ctx)
+
+	var events []StreamEvent
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event, ok := <-parser.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("timeout waiting for parser to finish")
+			return events
+		}
+	}
+}
+
+func TestCodexParseTurn(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"thread.started","thread_id":"0199a213-81c0-7800-8aa1-bbab2a035a53"}`,
+		`{"type":"turn.started"}`,
+		`{"type":"item.completed","item":{"id":"item_0","type":"reasoning","text":"**Scanning the repo**"}}`,
+		`{"type":"item.started","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","aggregated_output":"","status":"in_progress"}}`,
+		`{"type":"item.completed","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","aggregated_output":"go.mod\nmain.go\n","exit_code":0,"status":"completed"}}`,
+		`{"type":"item.completed","item":{"id":"item_2","type":"file_change","changes":[{"path":"main.go","kind":"update"}],"status":"completed"}}`,
+		`{"type":"item.completed","item":{"id":"item_3","type":"agent_message","text":"Done."}}`,
+		`{"type":"turn.completed","usage":{"input_tokens":24763,"cached_input_tokens":24448,"output_tokens":122}}`,
+	}, "\n") + "\n"
+
+	events := collectCodexEvents(t, NewCodexStreamParser(strings.NewReader(input), 20), context.Background())
+
+	want := []struct {
+		typ  StreamEventType
+		tool string
+		text string
+	}{
+		{EventText, "", "**Scanning the repo**"},
+		{EventToolUse, "Bash", ""},
+		{EventToolResult, "", "go.mod\nmain.go\n"},
+		{EventToolUse, "Edit", ""},
+		{EventText, "", "Done."},
+		{EventResult, "", "Done."},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, w := range want {
+		if events[i].Type != w.typ || events[i].ToolName != w.tool || (w.text != "" && events[i].Text != w.text) {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], w)
+		}
+	}
+	if events[1].ToolInput != `{"command":"bash -lc ls"}` {
+		t.Errorf("Bash input = %q", events[1].ToolInput)
+	}
+	if events[3].ToolInput != `{"file_path":"main.go"}` {
+		t.Errorf("Edit input = %q", events[3].ToolInput)
+	}
+	if events[1].ToolID != "item_1" || events[2].ToolID != "item_1" || events[2].IsError {
+		t.Errorf("expected a successful result paired with item_1, got %+v and %+v", events[1], events[2])
+	}
+	u := events[5].Usage
+	// Cached tokens are split out of Codex's input_tokens.
+	if u == nil || u.InputTokens != 315 || u.OutputTokens != 122 || u.TotalTokens != 437 || u.CacheReadTokens != 24448 {
+		t.Errorf("unexpected usage: %+v", u)
+	}
+}
+
+func TestCodexFailedCommand(t *testing.T) {
+	input := `{"type":"item.completed","item":{"id":"item_4","type":"command_execution","command":"bash -lc 'go test ./...'","aggregated_output":"","exit_code":1,"status":"failed"}}` + "\n"
+
+	events := collectCodexEvents(t, NewCodexStreamParser(strings.NewReader(input), 20), context.Background())
+
+	if len(events) != 2 {
+		t.Fatalf("expected tool use and result, got %+v", events)
+	}
+	if events[1].Type != EventToolResult || events[1].ToolID != "item_4" || !events[1].IsError {
+		t.Errorf("expected a failed result for item_4, got %+v", events[1])
+	}
+}
+
+func TestCodexParseErrors(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"error","message":"stream disconnected before completion"}`,
+		`{"type":"turn.failed","error":{"message":"usage limit reached"}}`,
+		`not json`,
+	}, "\n") + "\n"
+
+	events := collectCodexEvents(t, NewCodexStreamParser(strings.NewReader(input), 10), context.Background())
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].Type != EventError || events[0].Text != "stream disconnected before completion" {
+		t.Errorf("unexpected event 0: %+v", events[0])
+	}
+	if events[1].Type != EventError || events[1].Text != "usage limit reached" {
+		t.Errorf("unexpected event 1: %+v", events[1])
+	}
+	if events[2].Type != EventRaw {
+		t.Errorf("expected raw event for invalid JSON, got %s", events[2].Type)
+	}
+}
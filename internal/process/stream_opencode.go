@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"strings"
+	"time"
 )
 
 // OpenCode parser handles two event formats that appear in the same stream:
@@ -45,6 +46,7 @@ type ocPart struct {
 	Type   string       `json:"type"`
 	Text   string       `json:"text,omitempty"`
 	Tool   string       `json:"tool,omitempty"`
+	CallID string       `json:"callID,omitempty"`
 	State  *ocToolState `json:"state,omitempty"`
 	Cost   float64      `json:"cost,omitempty"`
 	Tokens *ocTokens    `json:"tokens,omitempty"`
@@ -57,6 +59,13 @@ type ocToolState struct {
 	Output string          `json:"output,omitempty"`
 	Error  string          `json:"error,omitempty"`
 	Title  string          `json:"title,omitempty"`
+	Time   *ocTime         `json:"time,omitempty"`
+}
+
+// ocTime is a tool call's start and end, in Unix milliseconds.
+type ocTime struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
 }
 
 type ocTokens struct {
@@ -94,8 +103,10 @@ type ccMessage struct {
 }
 
 type ccUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 }
 
 type ccRateLimitInfo struct {
@@ -181,12 +192,24 @@ func (p *OpenCodeStreamParser) Parse(ctx context.Context) {
 				Type:      EventToolUse,
 				ToolName:  part.Tool,
 				ToolInput: toolInput,
+				ToolID:    part.CallID,
 			})
-			if part.State != nil && part.State.Output != "" {
-				p.send(ctx, StreamEvent{
-					Type: EventToolResult,
-					Text: part.State.Output,
-				})
+			// OpenCode reports a tool call once it has finished, so the
+			// result carries the call's own timing.
+			if part.State != nil && (part.State.Output != "" || part.State.Status == "error") {
+				result := StreamEvent{
+					Type:    EventToolResult,
+					Text:    part.State.Output,
+					ToolID:  part.CallID,
+					IsError: part.State.Status == "error",
+				}
+				if result.IsError && result.Text == "" {
+					result.Text = part.State.Error
+				}
+				if t := part.State.Time; t != nil && t.End > t.Start {
+					result.Elapsed = time.Duration(t.End-t.Start) * time.Millisecond
+				}
+				p.send(ctx, result)
 			}
 
 		case "step_start":
@@ -210,6 +233,10 @@ func (p *OpenCodeStreamParser) Parse(ctx context.Context) {
 					TotalTokens:  total,
 					CostUSD:      part.Cost,
 				}
+				if part.Tokens.Cache != nil {
+					usage.CacheCreationTokens = part.Tokens.Cache.Write
+					usage.CacheReadTokens = part.Tokens.Cache.Read
+				}
 			}
 			p.send(ctx, StreamEvent{
 				Type:  EventResult,
@@ -264,10 +291,12 @@ func (p *OpenCodeStreamParser) Parse(ctx context.Context) {
 				if json.Unmarshal(ev.Usage, &usage) == nil {
 					total := usage.InputTokens + usage.OutputTokens
 					event.Usage = &UsageData{
-						InputTokens:  usage.InputTokens,
-						OutputTokens: usage.OutputTokens,
-						TotalTokens:  total,
-						CostUSD:      ev.CostUSD,
+						InputTokens:         usage.InputTokens,
+						OutputTokens:        usage.OutputTokens,
+						TotalTokens:         total,
+						CacheCreationTokens: usage.CacheCreationInputTokens,
+						CacheReadTokens:     usage.CacheReadInputTokens,
+						CostUSD:             ev.CostUSD,
 					}
 				}
 			}
@@ -84,6 +84,12 @@ func TestOCParseToolUse(t *testing.T) {
 	if events[1].Text != "No files found" {
 		t.Errorf("expected 'No files found', got %q", events[1].Text)
 	}
+	if events[0].ToolID != "toolu_abc" || events[1].ToolID != "toolu_abc" {
+		t.Errorf("expected both events to carry the call ID, got %q and %q", events[0].ToolID, events[1].ToolID)
+	}
+	if events[1].Elapsed != 15*time.Millisecond {
+		t.Errorf("expected elapsed 15ms from the call's timing, got %v", events[1].Elapsed)
+	}
 }
 
 func TestOCParseToolUseRunning(t *testing.T) {
@@ -369,3 +369,125 @@ func makeBlockingPipe() (*blockingReader, *writeCloser) {
 	r := &blockingReader{w: w}
 	return r, w
 }
+
+func TestParseThinkingEvent(t *testing.T) {
+	input := `{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"Check the tests first."},{"type":"redacted_thinking","data":"abc"}]}}` + "\n"
+	parser := NewStreamParser(strings.NewReader(input), 10)
+
+	events := collectEvents(t, parser, context.Background())
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != EventThinking || events[0].Text != "Check the tests first." {
+		t.Errorf("unexpected thinking event: %+v", events[0])
+	}
+	if events[1].Type != EventThinking || events[1].Text != "(redacted)" {
+		t.Errorf("unexpected redacted thinking event: %+v", events[1])
+	}
+}
+
+func TestParseSubAgentEvents(t *testing.T) {
+	input := `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Task","input":{"description":"Explore"}}]}}
+{"type":"user","parent_tool_use_id":"toolu_1","message":{"content":[{"type":"text","text":"Find the config loader"}]}}
+{"type":"assistant","parent_tool_use_id":"toolu_1","message":{"content":[{"type":"tool_use","id":"toolu_2","name":"Grep","input":{"pattern":"Load"}}]}}
+`
+	parser := NewStreamParser(strings.NewReader(input), 10)
+
+	events := collectEvents(t, parser, context.Background())
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].ToolID != "toolu_1" || events[0].ParentID != "" {
+		t.Errorf("expected top-level Task call with id toolu_1, got %+v", events[0])
+	}
+	for _, ev := range events[1:] {
+		if ev.ParentID != "toolu_1" {
+			t.Errorf("expected sub-agent event under toolu_1, got %+v", ev)
+		}
+	}
+}
+
+func TestParseMessageUsageOncePerMessage(t *testing.T) {
+	input := `{"type":"assistant","message":{"id":"msg_1","usage":{"input_tokens":10,"output_tokens":5},"content":[{"type":"text","text":"Reading"}]}}
+{"type":"assistant","message":{"id":"msg_1","usage":{"input_tokens":10,"output_tokens":5},"content":[{"type":"tool_use","id":"toolu_1","name":"Read","input":{}}]}}
+{"type":"assistant","message":{"id":"msg_2","usage":{"input_tokens":20,"output_tokens":8},"content":[{"type":"text","text":"Done"}]}}
+`
+	parser := NewStreamParser(strings.NewReader(input), 10)
+
+	events := collectEvents(t, parser, context.Background())
+
+	var usage []*UsageData
+	for _, ev := range events {
+		if ev.Type == EventUsage {
+			usage = append(usage, ev.Usage)
+		}
+	}
+	if len(usage) != 2 {
+		t.Fatalf("expected one usage event per message, got %d in %+v", len(usage), events)
+	}
+	if usage[0].TotalTokens != 15 || usage[1].TotalTokens != 28 {
+		t.Errorf("unexpected usage: %+v, %+v", usage[0], usage[1])
+	}
+	if events[0].Type != EventUsage {
+		t.Errorf("expected usage before the message's content, got %+v", events[0])
+	}
+}
+
+func TestParseResultCacheUsage(t *testing.T) {
+	input := `{"type":"result","result":"done","usage":{"input_tokens":200,"output_tokens":100,"cache_creation_input_tokens":300,"cache_read_input_tokens":1500},"total_cost_usd":0.01}` + "\n"
+	parser := NewStreamParser(strings.NewReader(input), 10)
+
+	events := collectEvents(t, parser, context.Background())
+
+	if len(events) != 1 || events[0].Usage == nil {
+		t.Fatalf("expected a result with usage, got %+v", events)
+	}
+	u := events[0].Usage
+	if u.TotalTokens != 300 || u.CacheCreationTokens != 300 || u.CacheReadTokens != 1500 {
+		t.Errorf("unexpected usage: %+v", u)
+	}
+	if rate := u.CacheHitRate(); rate != 0.75 {
+		t.Errorf("expected cache hit rate 0.75, got %v", rate)
+	}
+}
+
+func TestParseResultUsageIsIncremental(t *testing.T) {
+	// After an interjection the second result repeats the session totals.
+	input := `{"type":"result","result":"first","usage":{"input_tokens":100,"output_tokens":50},"total_cost_usd":0.03}` + "\n" +
+		`{"type":"result","result":"second","usage":{"input_tokens":160,"output_tokens":70},"total_cost_usd":0.05}` + "\n"
+	parser := NewStreamParser(strings.NewReader(input), 10)
+
+	events := collectEvents(t, parser, context.Background())
+
+	if len(events) != 2 || events[1].Usage == nil {
+		t.Fatalf("expected two results with usage, got %+v", events)
+	}
+	u := events[1].Usage
+	if u.InputTokens != 60 || u.OutputTokens != 20 || u.TotalTokens != 80 {
+		t.Errorf("expected the second result to report only its own tokens, got %+v", u)
+	}
+	if diff := u.CostUSD - 0.02; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected cost 0.02, got %f", u.CostUSD)
+	}
+}
+
+func TestParseToolResultEvents(t *testing.T) {
+	input := `{"type":"user","message":{"role":"user","content":[{"tool_use_id":"toolu_1","type":"tool_result","content":"file contents here"}]}}
+{"type":"user","message":{"role":"user","content":[{"tool_use_id":"toolu_2","type":"tool_result","content":[{"type":"text","text":"exit status 1"}],"is_error":true}]}}
+`
+	parser := NewStreamParser(strings.NewReader(input), 10)
+
+	events := collectEvents(t, parser, context.Background())
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != EventToolResult || events[0].ToolID != "toolu_1" || events[0].Text != "file contents here" || events[0].IsError {
+		t.Errorf("unexpected result event: %+v", events[0])
+	}
+	if events[1].ToolID != "toolu_2" || events[1].Text != "exit status 1" || !events[1].IsError {
+		t.Errorf("unexpected error result event: %+v", events[1])
+	}
+}
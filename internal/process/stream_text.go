@@ -0,0 +1,76 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// TextStreamParser handles runtimes that print plain text. Every line is
+// reported as text, and the whole output is reported again as the result
+// once the stream ends. Plain text carries no usage.
+type TextStreamParser struct {
+	reader io.Reader
+	events chan StreamEvent
+	done   chan error
+}
+
+func NewTextStreamParser(r io.Reader, bufSize int) *TextStreamParser {
+	if bufSize <= 0 {
+		bufSize = 256
+	}
+	return &TextStreamParser{
+		reader: r,
+		events: make(chan StreamEvent, bufSize),
+		done:   make(chan error, 1),
+	}
+}
+
+func (p *TextStreamParser) Parse(ctx context.Context) {
+	defer close(p.events)
+
+	scanner := bufio.NewScanner(p.reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			p.done <- ctx.Err()
+			return
+		default:
+		}
+
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		p.send(ctx, StreamEvent{Type: EventText, Text: line})
+	}
+
+	if err := scanner.Err(); err != nil {
+		p.done <- err
+		return
+	}
+	if len(lines) > 0 {
+		p.send(ctx, StreamEvent{Type: EventResult, Text: strings.Join(lines, "\n")})
+	}
+	p.done <- nil
+}
+
+func (p *TextStreamParser) send(ctx context.Context, event StreamEvent) {
+	select {
+	case <-ctx.Done():
+	case p.events <- event:
+	}
+}
+
+func (p *TextStreamParser) Events() <-chan StreamEvent {
+	return p.events
+}
+
+func (p *TextStreamParser) Done() <-chan error {
+	return p.done
+}
@@ -0,0 +1,53 @@
+package process
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func collectTextEvents(t *testing.T, parser *TextStreamParser, ctx context.Context) []StreamEvent {
+	t.Helper()
+	go parser.Parse(ctx)
+
+	var events []StreamEvent
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event, ok := <-parser.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("timeout waiting for parser to finish")
+			return events
+		}
+	}
+}
+
+func TestTextParseLines(t *testing.T) {
+	input := "first line\n\n  indented\r\n"
+	events := collectTextEvents(t, NewTextStreamParser(strings.NewReader(input), 10), context.Background())
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d: %+v", len(events), events)
+	}
+	if events[0].Type != EventText || events[0].Text != "first line" {
+		t.Errorf("unexpected event 0: %+v", events[0])
+	}
+	if events[1].Type != EventText || events[1].Text != "  indented" {
+		t.Errorf("unexpected event 1: %+v", events[1])
+	}
+	if events[2].Type != EventResult || events[2].Text != "first line\n  indented" || events[2].Usage != nil {
+		t.Errorf("unexpected result: %+v", events[2])
+	}
+}
+
+func TestTextParseEmpty(t *testing.T) {
+	events := collectTextEvents(t, NewTextStreamParser(strings.NewReader(""), 10), context.Background())
+	if len(events) != 0 {
+		t.Errorf("expected no events, got %+v", events)
+	}
+}
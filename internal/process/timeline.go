@@ -0,0 +1,151 @@
+package process
+
+import (
+	"sync"
+	"time"
+)
+
+// maxTimelineCalls bounds the tool calls a Timeline keeps; the oldest are
+// dropped first.
+const maxTimelineCalls = 5000
+
+// ToolCall is one tool invocation of a run, paired with its result.
+type ToolCall struct {
+	ID      string
+	Skill   string
+	Name    string
+	Summary string // one-line description, as in the log view
+	Depth   int    // 1 for calls made by a Task sub-agent
+	Span    int    // index of the skill span the call was made in; -1 if none
+	Start   time.Time
+	End     time.Time // zero until the result arrives
+	Error   bool
+}
+
+// Done reports whether the call's result has arrived.
+func (c ToolCall) Done() bool {
+	return !c.End.IsZero()
+}
+
+// Duration returns how long the call took, or has been running as of now.
+func (c ToolCall) Duration(now time.Time) time.Duration {
+	if c.Done() {
+		return c.End.Sub(c.Start)
+	}
+	return now.Sub(c.Start)
+}
+
+// SkillSpan is the wall-clock span of one skill process.
+type SkillSpan struct {
+	Name  string
+	Start time.Time
+	End   time.Time // zero while the process runs
+}
+
+// Timeline records when a run's skills and tool calls started and ended.
+// Tool calls are paired with their results by tool-use ID; results of
+// runtimes that report no IDs close the oldest open call without one.
+type Timeline struct {
+	mu     sync.RWMutex
+	skills []SkillSpan
+	calls  []ToolCall
+}
+
+// NewTimeline creates an empty timeline.
+func NewTimeline() *Timeline {
+	return &Timeline{}
+}
+
+// StartSkill opens the span of a skill process.
+func (t *Timeline) StartSkill(name string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endSkill(at)
+	t.skills = append(t.skills, SkillSpan{Name: name, Start: at})
+}
+
+// EndSkill closes the span of the running skill process.
+func (t *Timeline) EndSkill(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endSkill(at)
+}
+
+func (t *Timeline) endSkill(at time.Time) {
+	if n := len(t.skills); n > 0 && t.skills[n-1].End.IsZero() {
+		t.skills[n-1].End = at
+	}
+}
+
+// Record updates the timeline with a stream event received at at. Only
+// tool_use and tool_result events are recorded.
+func (t *Timeline) Record(event StreamEvent, skill string, at time.Time) {
+	switch event.Type {
+	case EventToolUse:
+		depth := 0
+		if event.ParentID != "" {
+			depth = 1
+		}
+		t.startTool(ToolCall{
+			ID:      event.ToolID,
+			Skill:   skill,
+			Name:    event.ToolName,
+			Summary: ToolUseSummary(event.ToolName, event.ToolInput),
+			Depth:   depth,
+			Start:   at,
+		})
+	case EventToolResult:
+		t.finishTool(event.ToolID, event.IsError, event.Elapsed, at)
+	}
+}
+
+func (t *Timeline) startTool(c ToolCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Some runtimes report a call again when it finishes.
+	if c.ID != "" {
+		for _, open := range t.calls {
+			if open.ID == c.ID && !open.Done() {
+				return
+			}
+		}
+	}
+	c.Span = len(t.skills) - 1
+	t.calls = append(t.calls, c)
+	if len(t.calls) > maxTimelineCalls {
+		t.calls = append([]ToolCall(nil), t.calls[len(t.calls)-maxTimelineCalls:]...)
+	}
+}
+
+// finishTool closes the call a result answers. A positive elapsed moves the
+// call's start back for runtimes that only report finished calls.
+func (t *Timeline) finishTool(id string, isError bool, elapsed time.Duration, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.calls {
+		c := &t.calls[i]
+		if c.Done() || c.ID != id {
+			continue
+		}
+		c.End = at
+		c.Error = isError
+		if elapsed > 0 {
+			c.Start = at.Add(-elapsed)
+		}
+		return
+	}
+}
+
+// Skills returns the skill spans in start order.
+func (t *Timeline) Skills() []SkillSpan {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]SkillSpan(nil), t.skills...)
+}
+
+// Calls returns the tool calls in the order they were made.
+func (t *Timeline) Calls() []ToolCall {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]ToolCall(nil), t.calls...)
+}
@@ -0,0 +1,83 @@
+package process
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimelinePairsResultsByID(t *testing.T) {
+	tl := NewTimeline()
+	t0 := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	tl.StartSkill("build", t0)
+	tl.Record(StreamEvent{Type: EventToolUse, ToolName: "Bash", ToolInput: `{"command":"go test ./..."}`, ToolID: "a"}, "build", t0.Add(time.Second))
+	tl.Record(StreamEvent{Type: EventToolUse, ToolName: "Read", ToolID: "b"}, "build", t0.Add(2*time.Second))
+	tl.Record(StreamEvent{Type: EventToolResult, ToolID: "b"}, "build", t0.Add(3*time.Second))
+	tl.Record(StreamEvent{Type: EventToolResult, ToolID: "a", IsError: true}, "build", t0.Add(10*time.Second))
+	tl.EndSkill(t0.Add(12 * time.Second))
+
+	calls := tl.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+	if calls[0].Summary != "Tool: Bash — go test ./..." || calls[0].Duration(time.Time{}) != 9*time.Second || !calls[0].Error {
+		t.Errorf("unexpected Bash call: %+v", calls[0])
+	}
+	if calls[1].Duration(time.Time{}) != time.Second || calls[1].Error {
+		t.Errorf("unexpected Read call: %+v", calls[1])
+	}
+	if calls[0].Span != 0 {
+		t.Errorf("expected call in span 0, got %d", calls[0].Span)
+	}
+	skills := tl.Skills()
+	if len(skills) != 1 || skills[0].End != t0.Add(12*time.Second) {
+		t.Errorf("unexpected skill spans: %+v", skills)
+	}
+}
+
+func TestTimelineResultsWithoutIDs(t *testing.T) {
+	tl := NewTimeline()
+	t0 := time.Now()
+	tl.StartSkill("build", t0)
+	tl.Record(StreamEvent{Type: EventToolUse, ToolName: "Bash"}, "build", t0)
+	tl.Record(StreamEvent{Type: EventToolUse, ToolName: "Read"}, "build", t0.Add(time.Second))
+	tl.Record(StreamEvent{Type: EventToolResult}, "build", t0.Add(2*time.Second))
+
+	calls := tl.Calls()
+	if !calls[0].Done() || calls[1].Done() {
+		t.Errorf("expected the oldest open call to be closed, got %+v", calls)
+	}
+}
+
+func TestTimelineReportedElapsed(t *testing.T) {
+	tl := NewTimeline()
+	t0 := time.Now()
+	tl.StartSkill("build", t0)
+	// Runtimes that report finished calls send the use and result together.
+	tl.Record(StreamEvent{Type: EventToolUse, ToolName: "bash", ToolID: "c"}, "build", t0.Add(5*time.Second))
+	tl.Record(StreamEvent{Type: EventToolUse, ToolName: "bash", ToolID: "c"}, "build", t0.Add(5*time.Second))
+	tl.Record(StreamEvent{Type: EventToolResult, ToolID: "c", Elapsed: 4 * time.Second}, "build", t0.Add(5*time.Second))
+
+	calls := tl.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected a repeated call to be recorded once, got %d", len(calls))
+	}
+	if calls[0].Start != t0.Add(time.Second) || calls[0].Duration(time.Time{}) != 4*time.Second {
+		t.Errorf("expected the call to start 4s before its result, got %+v", calls[0])
+	}
+}
+
+func TestTimelineSkillSpans(t *testing.T) {
+	tl := NewTimeline()
+	t0 := time.Now()
+	tl.StartSkill("build", t0)
+	tl.StartSkill("test", t0.Add(time.Minute))
+	tl.Record(StreamEvent{Type: EventToolUse, ToolName: "Bash"}, "test", t0.Add(2*time.Minute))
+
+	skills := tl.Skills()
+	if len(skills) != 2 || skills[0].End != t0.Add(time.Minute) || !skills[1].End.IsZero() {
+		t.Fatalf("unexpected spans: %+v", skills)
+	}
+	if c := tl.Calls()[0]; c.Span != 1 {
+		t.Errorf("expected call in span 1, got %d", c.Span)
+	}
+}
@@ -0,0 +1,200 @@
+// Package report aggregates the cost and outcome of runs, grouped by skill,
+// workflow, model or day, for `agtop report`.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+// Dimensions a report can group runs by.
+const (
+	BySkill    = "skill"
+	ByWorkflow = "workflow"
+	ByModel    = "model"
+	ByDay      = "day"
+)
+
+// Dimensions lists the valid values of the --by flag.
+var Dimensions = []string{BySkill, ByWorkflow, ByModel, ByDay}
+
+// Row is the aggregate of one group of runs. Accepted, Failed and the rates
+// derived from them count whole runs, so a run that used several skills or
+// models counts toward each of their rows.
+type Row struct {
+	Key             string  `json:"key"`
+	Runs            int     `json:"runs"`
+	Accepted        int     `json:"accepted"`
+	Failed          int     `json:"failed"` // failed or rejected
+	SuccessRate     float64 `json:"success_rate"`
+	Tokens          int     `json:"tokens"`
+	CostUSD         float64 `json:"cost_usd"`
+	AvgCostUSD      float64 `json:"avg_cost_usd"`
+	CostPerAccepted float64 `json:"cost_per_accepted_usd"`
+}
+
+// Decided returns how many of the row's runs were accepted, rejected or
+// failed. Runs still in progress or awaiting review are left out of the
+// success rate.
+func (r Row) Decided() int {
+	return r.Accepted + r.Failed
+}
+
+// Report is the result of Build.
+type Report struct {
+	By    string    `json:"by"`
+	Since time.Time `json:"since,omitempty"`
+	Rows  []Row     `json:"rows"`
+	Total Row       `json:"total"`
+}
+
+// Build groups the runs created at or after since by the dimension by. Rows
+// for days are in date order; the others are by cost, highest first.
+func Build(runs []run.Run, by string, since time.Time) (Report, error) {
+	rep := Report{By: by, Since: since}
+	if !validDimension(by) {
+		return rep, fmt.Errorf("unknown report dimension %q (want %s)", by, strings.Join(Dimensions, ", "))
+	}
+
+	rows := make(map[string]*Row)
+	row := func(key string) *Row {
+		if key == "" {
+			key = "-"
+		}
+		r, ok := rows[key]
+		if !ok {
+			r = &Row{Key: key}
+			rows[key] = r
+		}
+		return r
+	}
+	rep.Total.Key = "total"
+
+	for _, r := range runs {
+		if r.CreatedAt.Before(since) {
+			continue
+		}
+		addRun(&rep.Total, r, r.Tokens, r.Cost)
+
+		switch by {
+		case ByWorkflow:
+			addRun(row(r.Workflow), r, r.Tokens, r.Cost)
+		case ByDay:
+			addRun(row(r.CreatedAt.Local().Format("2006-01-02")), r, r.Tokens, r.Cost)
+		case BySkill, ByModel:
+			// Group the run's skill executions, so each key counts the
+			// run once with the tokens and cost spent under it.
+			type usage struct {
+				tokens int
+				cost   float64
+			}
+			var keys []string
+			per := make(map[string]*usage)
+			for _, sc := range r.SkillCosts {
+				key := sc.SkillName
+				if by == ByModel {
+					key = sc.Model
+					if key == "" {
+						key = r.Model
+					}
+				}
+				u, ok := per[key]
+				if !ok {
+					u = &usage{}
+					per[key] = u
+					keys = append(keys, key)
+				}
+				u.tokens += sc.TotalTokens
+				u.cost += sc.CostUSD
+			}
+			if len(keys) == 0 && by == ByModel {
+				addRun(row(r.Model), r, r.Tokens, r.Cost)
+			}
+			for _, key := range keys {
+				addRun(row(key), r, per[key].tokens, per[key].cost)
+			}
+		}
+	}
+
+	for _, r := range rows {
+		finish(r)
+		rep.Rows = append(rep.Rows, *r)
+	}
+	finish(&rep.Total)
+
+	sort.Slice(rep.Rows, func(i, j int) bool {
+		a, b := rep.Rows[i], rep.Rows[j]
+		if by != ByDay && a.CostUSD != b.CostUSD {
+			return a.CostUSD > b.CostUSD
+		}
+		return a.Key < b.Key
+	})
+	return rep, nil
+}
+
+func validDimension(by string) bool {
+	for _, d := range Dimensions {
+		if d == by {
+			return true
+		}
+	}
+	return false
+}
+
+func addRun(row *Row, r run.Run, tokens int, cost float64) {
+	row.Runs++
+	row.Tokens += tokens
+	row.CostUSD += cost
+	switch r.State {
+	case run.StateAccepted:
+		row.Accepted++
+	case run.StateRejected, run.StateFailed:
+		row.Failed++
+	}
+}
+
+func finish(row *Row) {
+	if row.Runs > 0 {
+		row.AvgCostUSD = row.CostUSD / float64(row.Runs)
+	}
+	if n := row.Decided(); n > 0 {
+		row.SuccessRate = float64(row.Accepted) / float64(n)
+	}
+	if row.Accepted > 0 {
+		row.CostPerAccepted = row.CostUSD / float64(row.Accepted)
+	}
+}
+
+// ParseSince returns the start of the window a --since value describes,
+// counted back from now. It accepts days ("30d"), weeks ("2w"), Go
+// durations ("12h") and "all" for no limit.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "all" {
+		return time.Time{}, nil
+	}
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid --since %q", s)
+		}
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid --since %q (want e.g. 30d, 2w, 12h or all)", s)
+	}
+	return now.Add(-d), nil
+}
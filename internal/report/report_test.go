@@ -0,0 +1,174 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/justinpbarnett/agtop/internal/cost"
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+func testRuns(now time.Time) []run.Run {
+	return []run.Run{
+		{
+			ID: "1", Workflow: "sdlc", Model: "opus", State: run.StateAccepted,
+			Tokens: 300, Cost: 3, CreatedAt: now.Add(-2 * time.Hour),
+			SkillCosts: []cost.SkillCost{
+				{SkillName: "spec", Model: "opus", TotalTokens: 100, CostUSD: 1},
+				{SkillName: "build", Model: "sonnet", TotalTokens: 150, CostUSD: 1.5},
+				{SkillName: "build", Model: "sonnet", TotalTokens: 50, CostUSD: 0.5},
+			},
+		},
+		{
+			ID: "2", Workflow: "build", Model: "sonnet", State: run.StateFailed,
+			Tokens: 100, Cost: 1, CreatedAt: now.Add(-time.Hour),
+			SkillCosts: []cost.SkillCost{{SkillName: "build", TotalTokens: 100, CostUSD: 1}},
+		},
+		{
+			ID: "3", Workflow: "build", Model: "sonnet", State: run.StateAccepted,
+			Tokens: 100, Cost: 1, CreatedAt: now.Add(-30 * time.Minute),
+			SkillCosts: []cost.SkillCost{{SkillName: "build", TotalTokens: 100, CostUSD: 1}},
+		},
+		{
+			ID: "4", Workflow: "build", State: run.StateReviewing,
+			Tokens: 50, Cost: 0.5, CreatedAt: now.Add(-10 * time.Minute),
+		},
+		{
+			ID: "old", Workflow: "build", State: run.StateAccepted,
+			Cost: 100, CreatedAt: now.Add(-60 * 24 * time.Hour),
+		},
+	}
+}
+
+func findRow(t *testing.T, rep Report, key string) Row {
+	t.Helper()
+	for _, r := range rep.Rows {
+		if r.Key == key {
+			return r
+		}
+	}
+	t.Fatalf("no row %q in %+v", key, rep.Rows)
+	return Row{}
+}
+
+func TestBuildByWorkflow(t *testing.T) {
+	now := time.Now()
+	rep, err := Build(testRuns(now), ByWorkflow, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Rows) != 2 {
+		t.Fatalf("rows = %+v, want 2", rep.Rows)
+	}
+	if rep.Rows[0].Key != "sdlc" {
+		t.Errorf("first row = %q, want the costliest (sdlc)", rep.Rows[0].Key)
+	}
+
+	build := findRow(t, rep, "build")
+	if build.Runs != 3 || build.Accepted != 1 || build.Failed != 1 {
+		t.Errorf("build counts = %+v", build)
+	}
+	if build.SuccessRate != 0.5 {
+		t.Errorf("build success rate = %v, want 0.5 (in-review run left out)", build.SuccessRate)
+	}
+	if build.CostUSD != 2.5 || build.CostPerAccepted != 2.5 {
+		t.Errorf("build cost = %v per accepted = %v, want 2.5 and 2.5", build.CostUSD, build.CostPerAccepted)
+	}
+
+	if rep.Total.Runs != 4 || rep.Total.CostUSD != 5.5 {
+		t.Errorf("total = %+v, want 4 runs costing 5.5 (old run excluded)", rep.Total)
+	}
+}
+
+func TestBuildBySkill(t *testing.T) {
+	now := time.Now()
+	rep, err := Build(testRuns(now), BySkill, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := findRow(t, rep, "build")
+	if build.Runs != 3 {
+		t.Errorf("build runs = %d, want 3 (each run counted once)", build.Runs)
+	}
+	if build.Tokens != 400 || build.CostUSD != 4 {
+		t.Errorf("build tokens = %d cost = %v, want 400 and 4", build.Tokens, build.CostUSD)
+	}
+	spec := findRow(t, rep, "spec")
+	if spec.Runs != 1 || spec.Accepted != 1 || spec.CostPerAccepted != 1 {
+		t.Errorf("spec = %+v", spec)
+	}
+}
+
+func TestBuildByModel(t *testing.T) {
+	now := time.Now()
+	rep, err := Build(testRuns(now), ByModel, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sonnet := findRow(t, rep, "sonnet")
+	// Run 1's build skill ran on sonnet; runs 2 and 3 fall back to the
+	// run's model.
+	if sonnet.Runs != 3 || sonnet.CostUSD != 4 {
+		t.Errorf("sonnet = %+v, want 3 runs costing 4", sonnet)
+	}
+	if opus := findRow(t, rep, "opus"); opus.CostUSD != 1 {
+		t.Errorf("opus cost = %v, want 1", opus.CostUSD)
+	}
+	// Run 4 has no skill costs and no model.
+	if none := findRow(t, rep, "-"); none.Runs != 1 || none.CostUSD != 0.5 {
+		t.Errorf("unknown model = %+v", none)
+	}
+}
+
+func TestBuildByDay(t *testing.T) {
+	day := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+	runs := []run.Run{
+		{ID: "b", Cost: 1, CreatedAt: day.Add(24 * time.Hour)},
+		{ID: "a", Cost: 2, CreatedAt: day},
+		{ID: "c", Cost: 3, CreatedAt: day.Add(time.Hour)},
+	}
+	rep, err := Build(runs, ByDay, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Rows) != 2 || rep.Rows[0].Key != "2025-03-10" || rep.Rows[1].Key != "2025-03-11" {
+		t.Fatalf("rows = %+v, want 2025-03-10 then 2025-03-11", rep.Rows)
+	}
+	if rep.Rows[0].Runs != 2 || rep.Rows[0].CostUSD != 5 {
+		t.Errorf("first day = %+v", rep.Rows[0])
+	}
+}
+
+func TestBuildUnknownDimension(t *testing.T) {
+	if _, err := Build(nil, "branch", time.Time{}); err == nil {
+		t.Error("expected error for unknown dimension")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"30d", now.Add(-30 * 24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"all", time.Time{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "d", "-3d", "soon", "0h"} {
+		if _, err := ParseSince(bad, now); err == nil {
+			t.Errorf("ParseSince(%q) should fail", bad)
+		}
+	}
+}
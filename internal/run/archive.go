@@ -0,0 +1,72 @@
+package run
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const archiveFile = "archive.jsonl"
+
+// ArchivedRun is the record of a run kept after it was removed, so reports
+// can still count it.
+type ArchivedRun struct {
+	Run        Run       `json:"run"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// Archive appends r to the project's archive of removed runs.
+func (p *Persistence) Archive(r Run) error {
+	if r.ID == "" {
+		return nil
+	}
+	data, err := json.Marshal(ArchivedRun{Run: r, ArchivedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("marshal archived run: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(p.sessionsDir, archiveFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return nil
+}
+
+// LoadArchive returns the archived runs in the order they were removed.
+func (p *Persistence) LoadArchive() ([]ArchivedRun, error) {
+	f, err := os.Open(filepath.Join(p.sessionsDir, archiveFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	defer f.Close()
+
+	var runs []ArchivedRun
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for sc.Scan() {
+		var ar ArchivedRun
+		// A line cut short by a crash is skipped.
+		if json.Unmarshal(sc.Bytes(), &ar) == nil && ar.Run.ID != "" {
+			runs = append(runs, ar)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return runs, fmt.Errorf("read archive: %w", err)
+	}
+	return runs, nil
+}
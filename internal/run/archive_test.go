@@ -0,0 +1,63 @@
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPersistenceArchive(t *testing.T) {
+	p := tempPersistence(t)
+
+	runs, err := p.LoadArchive()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("expected an empty archive, got %v, %v", runs, err)
+	}
+
+	for _, r := range []Run{
+		{ID: "001", Workflow: "build", State: StateAccepted, Cost: 1.5, CreatedAt: time.Now()},
+		{ID: "002", Workflow: "sdlc", State: StateRejected, Cost: 4, CreatedAt: time.Now()},
+	} {
+		if err := p.Archive(r); err != nil {
+			t.Fatalf("Archive: %v", err)
+		}
+	}
+
+	runs, err = p.LoadArchive()
+	if err != nil {
+		t.Fatalf("LoadArchive: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Run.ID != "001" || runs[1].Run.State != StateRejected || runs[1].Run.Cost != 4 {
+		t.Errorf("unexpected archive: %+v", runs)
+	}
+	if runs[0].ArchivedAt.IsZero() {
+		t.Error("expected the archive time to be set")
+	}
+
+	// The archive is not a session file.
+	sessions, err := p.Load()
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("expected no sessions, got %d, %v", len(sessions), err)
+	}
+}
+
+func TestLoadArchiveSkipsTruncatedLine(t *testing.T) {
+	p := tempPersistence(t)
+	p.Archive(Run{ID: "001", State: StateAccepted})
+
+	f, err := os.OpenFile(filepath.Join(p.sessionsDir, archiveFile), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"run":{"id":"00`)
+	f.Close()
+
+	runs, err := p.LoadArchive()
+	if err != nil {
+		t.Fatalf("LoadArchive: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("expected 1 archived run, got %d", len(runs))
+	}
+}
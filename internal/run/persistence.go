@@ -37,6 +37,7 @@ type Persistence struct {
 	mu          sync.Mutex
 	lastSave    map[string]time.Time
 	lastState   map[string]State
+	saveMu      sync.Mutex // serializes writes of session files
 }
 
 func NewPersistence(projectRoot string) (*Persistence, error) {
@@ -208,16 +209,26 @@ func (p *Persistence) BindStore(store *Store, getLogTail func(runID string) []st
 				stdoutPath, stderrPath = getLogPaths(r.ID)
 			}
 
+			p.saveMu.Lock()
+			// Re-read the run, so a save that waited for another one
+			// does not write an older state over it.
+			if fresh, ok := store.Get(r.ID); ok {
+				r = fresh
+			}
 			if err := p.Save(r, logTail, stdoutPath, stderrPath); err != nil {
 				log.Printf("warning: save session %s: %v", r.ID, err)
 			}
+			p.saveMu.Unlock()
 		}
 	})
 }
 
 // FinalSave synchronously saves all non-terminal runs, bypassing debounce.
 // Called during TUI shutdown to ensure PIDs and state are preserved on disk.
+// It waits for a save in progress, so the process can exit once it returns.
 func (p *Persistence) FinalSave(store *Store, getLogPaths func(runID string) (string, string)) {
+	p.saveMu.Lock()
+	defer p.saveMu.Unlock()
 	for _, r := range store.List() {
 		if strings.Contains(r.ID, ":") {
 			continue
@@ -247,6 +258,9 @@ type RehydrateCallbacks struct {
 	Reconnect func(runID string, pid int, stdoutPath, stderrPath string)
 	// ReplayLogFile reads log files for a dead/terminal process. Called instead of InjectBuffer when log files exist.
 	ReplayLogFile func(runID string, stdoutPath, stderrPath string)
+	// RestoreEvents rebuilds a dead/terminal run's logs from its structured event log.
+	// Tried before ReplayLogFile and InjectBuffer; reports false when the run has none.
+	RestoreEvents func(runID string) bool
 }
 
 // SkillCost is re-exported here to avoid a circular import in the callback
@@ -278,7 +292,9 @@ func (p *Persistence) Rehydrate(store *Store, cb RehydrateCallbacks) (RehydrateR
 		r := sf.Run
 		hasLogFiles := sf.StdoutLogPath != "" && sf.StderrLogPath != ""
 
-		if !r.IsTerminal() {
+		// Runs waiting at an approval gate have no process to lose; they
+		// stay gated until someone approves or rejects them.
+		if !r.IsTerminal() && r.State != StateAwaitingApproval {
 			if r.PID > 0 && IsProcessAlive(r.PID) {
 				if hasLogFiles && cb.Reconnect != nil {
 					// Live process with log files: reconnect via file tailing
@@ -297,20 +313,13 @@ func (p *Persistence) Rehydrate(store *Store, cb RehydrateCallbacks) (RehydrateR
 				r.State = StateFailed
 				r.Error = "process no longer running (agtop restarted)"
 				r.PID = 0
+				r.Container = ""
 				store.Add(&r)
-				if hasLogFiles && cb.ReplayLogFile != nil {
-					cb.ReplayLogFile(r.ID, sf.StdoutLogPath, sf.StderrLogPath)
-				} else if cb.InjectBuffer != nil && len(sf.LogTail) > 0 {
-					cb.InjectBuffer(r.ID, sf.LogTail)
-				}
+				restoreLogs(sf, cb)
 			}
 		} else {
 			store.Add(&r)
-			if hasLogFiles && cb.ReplayLogFile != nil {
-				cb.ReplayLogFile(r.ID, sf.StdoutLogPath, sf.StderrLogPath)
-			} else if cb.InjectBuffer != nil && len(sf.LogTail) > 0 {
-				cb.InjectBuffer(r.ID, sf.LogTail)
-			}
+			restoreLogs(sf, cb)
 		}
 
 		if cb.RecordCost != nil {
@@ -333,6 +342,19 @@ func (p *Persistence) Rehydrate(store *Store, cb RehydrateCallbacks) (RehydrateR
 	return result, nil
 }
 
+// restoreLogs restores the logs of a run that is no longer running, from
+// the most complete source available.
+func restoreLogs(sf SessionFile, cb RehydrateCallbacks) {
+	id := sf.Run.ID
+	switch {
+	case cb.RestoreEvents != nil && cb.RestoreEvents(id):
+	case sf.StdoutLogPath != "" && sf.StderrLogPath != "" && cb.ReplayLogFile != nil:
+		cb.ReplayLogFile(id, sf.StdoutLogPath, sf.StderrLogPath)
+	case cb.InjectBuffer != nil && len(sf.LogTail) > 0:
+		cb.InjectBuffer(id, sf.LogTail)
+	}
+}
+
 // RehydrateWithWatcher loads sessions and starts a PID watcher for live processes.
 // Returns RehydrateResult and a cancel function for the watcher.
 func (p *Persistence) RehydrateWithWatcher(store *Store, cb RehydrateCallbacks) (RehydrateResult, context.CancelFunc, error) {
@@ -381,6 +403,7 @@ func WatchPIDs(runIDs []string, store *Store, interval time.Duration) context.Ca
 							r.State = StateFailed
 							r.Error = "process exited while agtop was not running"
 							r.PID = 0
+							r.Container = ""
 						})
 					}
 				}
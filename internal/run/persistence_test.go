@@ -354,6 +354,43 @@ func TestPersistenceRehydrateRestoresLogTail(t *testing.T) {
 	}
 }
 
+func TestPersistenceRehydratePrefersEventLog(t *testing.T) {
+	p := tempPersistence(t)
+	store := NewStore()
+
+	r := Run{ID: "001", State: StateCompleted, CreatedAt: time.Now()}
+	if err := p.Save(r, []string{"log line"}, "/tmp/001.stdout", "/tmp/001.stderr"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	var restored, replayed, injected bool
+	p.Rehydrate(store, RehydrateCallbacks{
+		RestoreEvents: func(runID string) bool {
+			restored = runID == "001"
+			return true
+		},
+		ReplayLogFile: func(string, string, string) { replayed = true },
+		InjectBuffer:  func(string, []string) { injected = true },
+	})
+
+	if !restored {
+		t.Error("expected the event log to be restored")
+	}
+	if replayed || injected {
+		t.Error("expected no fallback once the event log was restored")
+	}
+
+	// Without an event log the stdout log is replayed as before.
+	store = NewStore()
+	p.Rehydrate(store, RehydrateCallbacks{
+		RestoreEvents: func(string) bool { return false },
+		ReplayLogFile: func(string, string, string) { replayed = true },
+	})
+	if !replayed {
+		t.Error("expected the log files to be replayed without an event log")
+	}
+}
+
 func TestPersistenceRemove(t *testing.T) {
 	p := tempPersistence(t)
 
@@ -19,6 +19,9 @@ const (
 	StateRejected  State = "rejected"
 	StateFailed    State = "failed"
 	StateMerging   State = "merging"
+
+	// StateAwaitingApproval is set while a workflow waits at an approve gate.
+	StateAwaitingApproval State = "approval"
 )
 
 type Run struct {
@@ -36,15 +39,20 @@ type Run struct {
 	Tokens          int               `json:"tokens"`
 	TokensIn        int               `json:"tokens_in"`
 	TokensOut       int               `json:"tokens_out"`
+	CacheRead       int               `json:"cache_read_tokens,omitempty"`
+	CacheWrite      int               `json:"cache_write_tokens,omitempty"`
 	Cost            float64           `json:"cost"`
 	CreatedAt       time.Time         `json:"created_at"`
 	StartedAt       time.Time         `json:"started_at"`
 	CompletedAt     time.Time         `json:"completed_at"`
 	CurrentSkill    string            `json:"current_skill"`
 	Model           string            `json:"model"`
+	Runtime         string            `json:"runtime,omitempty"`
+	SessionID       string            `json:"session_id,omitempty"`
 	Command         string            `json:"command"`
 	Error           string            `json:"error"`
 	PID             int               `json:"pid"`
+	Container       string            `json:"container,omitempty"`
 	SkillCosts      []cost.SkillCost  `json:"skill_costs"`
 	DevServerPort   int               `json:"dev_server_port"`
 	DevServerURL    string            `json:"dev_server_url"`
@@ -55,6 +63,68 @@ type Run struct {
 	SubWorktrees    []SubWorktreeInfo `json:"sub_worktrees,omitempty"`
 	Worktrees       map[string]string `json:"worktrees,omitempty"`
 	Branches        map[string]string `json:"branches,omitempty"`
+	Nodes           []NodeStatus      `json:"nodes,omitempty"`
+	// Outputs holds the JSON results of skills that declare an outputs
+	// schema, keyed by skill name (step name in graph workflows).
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+	// LastOutput is the result text of the last skill a linear workflow
+	// finished. A run resumed after a pause, restart or approve gate hands it
+	// to its next skill.
+	LastOutput string `json:"last_output,omitempty"`
+	// LogSegments marks where each skill's output starts in the run's stdout
+	// log, so a restored run parses every part with the runtime that wrote it.
+	LogSegments []LogSegment `json:"log_segments,omitempty"`
+}
+
+// LogSegment is the part of a run's stdout log written by one skill.
+type LogSegment struct {
+	Offset    int64     `json:"offset"` // byte offset where the skill's output starts
+	Skill     string    `json:"skill,omitempty"`
+	Runtime   string    `json:"runtime,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// NodeState is the status of a single step in a graph workflow.
+type NodeState string
+
+const (
+	NodePending   NodeState = "pending"
+	NodeRunning   NodeState = "running"
+	NodeCompleted NodeState = "completed"
+	NodeFailed    NodeState = "failed"
+	NodeSkipped   NodeState = "skipped"
+)
+
+// NodeStatus tracks one step of a graph workflow. Runs of linear workflows
+// leave Run.Nodes empty.
+type NodeStatus struct {
+	Name        string    `json:"name"`
+	Skill       string    `json:"skill"`
+	Needs       []string  `json:"needs,omitempty"`
+	State       NodeState `json:"state"`
+	Error       string    `json:"error,omitempty"`
+	Loop        string    `json:"loop,omitempty"`
+	Loops       int       `json:"loops,omitempty"`
+	MaxLoops    int       `json:"max_loops,omitempty"`
+	Output      string    `json:"output,omitempty"` // result text of a completed step
+	StartedAt   time.Time `json:"started_at,omitempty"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+}
+
+// Icon returns a single-character status indicator for the node.
+func (n NodeStatus) Icon() string {
+	switch n.State {
+	case NodeRunning:
+		return "●"
+	case NodeCompleted:
+		return "✓"
+	case NodeFailed:
+		return "✗"
+	case NodeSkipped:
+		return "–"
+	default:
+		return "◌"
+	}
 }
 
 type SubWorktreeInfo struct {
@@ -69,6 +139,8 @@ func (r *Run) StatusIcon() string {
 		return "●"
 	case StatePaused:
 		return "◐"
+	case StateAwaitingApproval:
+		return "◇"
 	case StateCompleted, StateAccepted:
 		return "✓"
 	case StateFailed, StateRejected:
@@ -92,6 +164,16 @@ func (r *Run) IsTerminal() bool {
 	return false
 }
 
+// CacheHitRate returns the share of the run's prompt tokens that were read
+// from the prompt cache.
+func (r *Run) CacheHitRate() float64 {
+	prompt := r.TokensIn + r.CacheRead + r.CacheWrite
+	if prompt == 0 {
+		return 0
+	}
+	return float64(r.CacheRead) / float64(prompt)
+}
+
 func (r *Run) ElapsedTime() time.Duration {
 	if r.StartedAt.IsZero() {
 		return 0
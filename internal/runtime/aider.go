@@ -0,0 +1,53 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+type AiderRuntime struct {
+	aiderPath string
+}
+
+func NewAiderRuntime() (*AiderRuntime, error) {
+	path, err := exec.LookPath("aider")
+	if err != nil {
+		return nil, fmt.Errorf("aider binary not found in PATH — install from https://aider.chat")
+	}
+	return &AiderRuntime{aiderPath: path}, nil
+}
+
+// BuildArgs runs a single aider message non-interactively. Aider's own
+// commits are disabled because agtop commits after each skill.
+func (a *AiderRuntime) BuildArgs(prompt string, opts RunOptions) []string {
+	args := []string{
+		"--message", prompt,
+		"--yes-always",
+		"--no-pretty",
+		"--no-stream",
+		"--no-auto-commits",
+		"--no-check-update",
+	}
+	if opts.Model != "" {
+		args = append(args, "--model", opts.Model)
+	}
+	return args
+}
+
+func (a *AiderRuntime) Start(_ context.Context, prompt string, opts RunOptions) (*Process, error) {
+	args := a.BuildArgs(prompt, opts)
+	return startCommand(exec.Command(a.aiderPath, args...), opts)
+}
+
+func (a *AiderRuntime) Stop(proc *Process) error {
+	return stopCommand(proc)
+}
+
+func (a *AiderRuntime) Pause(proc *Process) error {
+	return pauseCommand(proc)
+}
+
+func (a *AiderRuntime) Resume(proc *Process) error {
+	return resumeCommand(proc)
+}
@@ -0,0 +1,35 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAiderBuildArgs(t *testing.T) {
+	rt := &AiderRuntime{aiderPath: "/usr/bin/aider"}
+	args := rt.BuildArgs("fix the bug", RunOptions{Model: "sonnet", PermissionMode: "plan"})
+
+	expected := []string{
+		"--message", "fix the bug",
+		"--yes-always",
+		"--no-pretty",
+		"--no-stream",
+		"--no-auto-commits",
+		"--no-check-update",
+		"--model", "sonnet",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
+
+func TestAiderBuildArgsNoModel(t *testing.T) {
+	rt := &AiderRuntime{aiderPath: "/usr/bin/aider"}
+	args := rt.BuildArgs("fix the bug", RunOptions{})
+
+	for _, a := range args {
+		if a == "--model" {
+			t.Fatalf("expected no --model flag, got %v", args)
+		}
+	}
+}
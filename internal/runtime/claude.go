@@ -2,14 +2,17 @@ package runtime
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os/exec"
 	"strconv"
 	"strings"
-	"syscall"
-	"time"
 )
 
+// ClaudeRuntime runs Claude Code with stream-json input and output. The
+// prompt is sent as the first message on stdin, which stays open so more
+// messages can be sent while the conversation runs; Claude exits once stdin
+// is closed and the last message has been answered.
 type ClaudeRuntime struct {
 	claudePath string
 }
@@ -22,9 +25,10 @@ func NewClaudeRuntime() (*ClaudeRuntime, error) {
 	return &ClaudeRuntime{claudePath: path}, nil
 }
 
-func (c *ClaudeRuntime) BuildArgs(prompt string, opts RunOptions) []string {
+func (c *ClaudeRuntime) BuildArgs(opts RunOptions) []string {
 	args := []string{
-		"-p", prompt,
+		"-p",
+		"--input-format", "stream-json",
 		"--output-format", "stream-json",
 		"--verbose",
 	}
@@ -40,84 +44,57 @@ func (c *ClaudeRuntime) BuildArgs(prompt string, opts RunOptions) []string {
 	if opts.PermissionMode != "" {
 		args = append(args, "--permission-mode", opts.PermissionMode)
 	}
+	if opts.Resume != "" {
+		args = append(args, "--resume", opts.Resume)
+	} else if opts.Continue {
+		args = append(args, "--continue")
+	}
 	return args
 }
 
 func (c *ClaudeRuntime) Start(_ context.Context, prompt string, opts RunOptions) (*Process, error) {
-	args := c.BuildArgs(prompt, opts)
+	args := c.BuildArgs(opts)
 	// Use exec.Command (not CommandContext) so the subprocess survives parent exit.
 	// Lifecycle is managed via explicit signals in Stop/Pause/Resume.
-	cmd := exec.Command(c.claudePath, args...)
-	if opts.WorkDir != "" {
-		cmd.Dir = opts.WorkDir
+	proc, err := startWithInput(exec.Command(c.claudePath, args...), opts)
+	if err != nil {
+		return nil, err
 	}
-
-	proc := &Process{Cmd: cmd}
-
-	if opts.StdoutFile != nil {
-		cmd.Stdout = opts.StdoutFile
-		proc.StdoutPath = opts.StdoutFile.Name()
-	} else {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return nil, fmt.Errorf("stdout pipe: %w", err)
-		}
-		proc.Stdout = stdout
+	if err := c.SendMessage(proc, prompt); err != nil {
+		proc.Stdin.Close()
+		_ = stopCommand(proc)
+		return nil, fmt.Errorf("send prompt: %w", err)
 	}
+	return proc, nil
+}
 
-	if opts.StderrFile != nil {
-		cmd.Stderr = opts.StderrFile
-		proc.StderrPath = opts.StderrFile.Name()
-	} else {
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return nil, fmt.Errorf("stderr pipe: %w", err)
-		}
-		proc.Stderr = stderr
+// SendMessage writes text to proc's stdin as a stream-json user message.
+func (c *ClaudeRuntime) SendMessage(proc *Process, text string) error {
+	if proc.Stdin == nil {
+		return fmt.Errorf("process does not accept input")
 	}
-
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("start: %w", err)
+	data, err := json.Marshal(map[string]interface{}{
+		"type": "user",
+		"message": map[string]interface{}{
+			"role":    "user",
+			"content": []interface{}{map[string]interface{}{"type": "text", "text": text}},
+		},
+	})
+	if err != nil {
+		return err
 	}
-
-	proc.PID = cmd.Process.Pid
-
-	doneCh := make(chan error, 1)
-	go func() {
-		doneCh <- cmd.Wait()
-	}()
-	proc.Done = doneCh
-
-	return proc, nil
+	_, err = proc.Stdin.Write(append(data, '\n'))
+	return err
 }
 
 func (c *ClaudeRuntime) Stop(proc *Process) error {
-	if proc.Cmd == nil || proc.Cmd.Process == nil {
-		return nil
-	}
-	_ = proc.Cmd.Process.Signal(syscall.SIGTERM)
-	go func() {
-		timer := time.NewTimer(5 * time.Second)
-		defer timer.Stop()
-		select {
-		case <-proc.Done:
-		case <-timer.C:
-			_ = proc.Cmd.Process.Signal(syscall.SIGKILL)
-		}
-	}()
-	return nil
+	return stopCommand(proc)
 }
 
 func (c *ClaudeRuntime) Pause(proc *Process) error {
-	if proc.Cmd == nil || proc.Cmd.Process == nil {
-		return nil
-	}
-	return proc.Cmd.Process.Signal(syscall.SIGSTOP)
+	return pauseCommand(proc)
 }
 
 func (c *ClaudeRuntime) Resume(proc *Process) error {
-	if proc.Cmd == nil || proc.Cmd.Process == nil {
-		return nil
-	}
-	return proc.Cmd.Process.Signal(syscall.SIGCONT)
+	return resumeCommand(proc)
 }
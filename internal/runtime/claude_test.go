@@ -1,15 +1,18 @@
 package runtime
 
 import (
+	"encoding/json"
+	"io"
+	"os"
 	"reflect"
 	"testing"
 )
 
 func TestBuildArgsMinimal(t *testing.T) {
 	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
-	args := rt.BuildArgs("do something", RunOptions{})
+	args := rt.BuildArgs(RunOptions{})
 
-	expected := []string{"-p", "do something", "--output-format", "stream-json", "--verbose"}
+	expected := []string{"-p", "--input-format", "stream-json", "--output-format", "stream-json", "--verbose"}
 	if !reflect.DeepEqual(args, expected) {
 		t.Errorf("expected %v, got %v", expected, args)
 	}
@@ -17,7 +20,7 @@ func TestBuildArgsMinimal(t *testing.T) {
 
 func TestBuildArgsAllFlags(t *testing.T) {
 	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
-	args := rt.BuildArgs("build feature", RunOptions{
+	args := rt.BuildArgs(RunOptions{
 		Model:          "sonnet",
 		MaxTurns:       10,
 		AllowedTools:   []string{"Read", "Write", "Bash"},
@@ -26,7 +29,8 @@ func TestBuildArgsAllFlags(t *testing.T) {
 	})
 
 	expected := []string{
-		"-p", "build feature",
+		"-p",
+		"--input-format", "stream-json",
 		"--output-format", "stream-json",
 		"--verbose",
 		"--model", "sonnet",
@@ -41,9 +45,9 @@ func TestBuildArgsAllFlags(t *testing.T) {
 
 func TestBuildArgsModelOnly(t *testing.T) {
 	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
-	args := rt.BuildArgs("test", RunOptions{Model: "opus"})
+	args := rt.BuildArgs(RunOptions{Model: "opus"})
 
-	expected := []string{"-p", "test", "--output-format", "stream-json", "--verbose", "--model", "opus"}
+	expected := []string{"-p", "--input-format", "stream-json", "--output-format", "stream-json", "--verbose", "--model", "opus"}
 	if !reflect.DeepEqual(args, expected) {
 		t.Errorf("expected %v, got %v", expected, args)
 	}
@@ -51,9 +55,9 @@ func TestBuildArgsModelOnly(t *testing.T) {
 
 func TestBuildArgsMaxTurnsZero(t *testing.T) {
 	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
-	args := rt.BuildArgs("test", RunOptions{MaxTurns: 0})
+	args := rt.BuildArgs(RunOptions{MaxTurns: 0})
 
-	expected := []string{"-p", "test", "--output-format", "stream-json", "--verbose"}
+	expected := []string{"-p", "--input-format", "stream-json", "--output-format", "stream-json", "--verbose"}
 	if !reflect.DeepEqual(args, expected) {
 		t.Errorf("max turns 0 should be omitted: expected %v, got %v", expected, args)
 	}
@@ -61,9 +65,9 @@ func TestBuildArgsMaxTurnsZero(t *testing.T) {
 
 func TestBuildArgsSingleTool(t *testing.T) {
 	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
-	args := rt.BuildArgs("test", RunOptions{AllowedTools: []string{"Read"}})
+	args := rt.BuildArgs(RunOptions{AllowedTools: []string{"Read"}})
 
-	expected := []string{"-p", "test", "--output-format", "stream-json", "--verbose", "--allowedTools", "Read"}
+	expected := []string{"-p", "--input-format", "stream-json", "--output-format", "stream-json", "--verbose", "--allowedTools", "Read"}
 	if !reflect.DeepEqual(args, expected) {
 		t.Errorf("expected %v, got %v", expected, args)
 	}
@@ -71,11 +75,77 @@ func TestBuildArgsSingleTool(t *testing.T) {
 
 func TestBuildArgsWorkDirOnly(t *testing.T) {
 	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
-	args := rt.BuildArgs("test", RunOptions{WorkDir: "/tmp/work"})
+	args := rt.BuildArgs(RunOptions{WorkDir: "/tmp/work"})
 
 	// WorkDir is set via cmd.Dir, not as a CLI flag
-	expected := []string{"-p", "test", "--output-format", "stream-json", "--verbose"}
+	expected := []string{"-p", "--input-format", "stream-json", "--output-format", "stream-json", "--verbose"}
 	if !reflect.DeepEqual(args, expected) {
 		t.Errorf("expected %v, got %v", expected, args)
 	}
 }
+
+func TestBuildArgsResume(t *testing.T) {
+	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
+	args := rt.BuildArgs(RunOptions{Resume: "sess-1", Continue: true})
+
+	// An explicit session wins over continuing the latest one.
+	expected := []string{"-p", "--input-format", "stream-json", "--output-format", "stream-json", "--verbose", "--resume", "sess-1"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+
+	args = rt.BuildArgs(RunOptions{Continue: true})
+	expected = []string{"-p", "--input-format", "stream-json", "--output-format", "stream-json", "--verbose", "--continue"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
+	proc := &Process{Stdin: w}
+
+	if err := rt.SendMessage(proc, "use the \"v2\" API instead"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	w.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg struct {
+		Type    string `json:"type"`
+		Message struct {
+			Role    string `json:"role"`
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"message"`
+	}
+	if data[len(data)-1] != '\n' {
+		t.Errorf("message should end with a newline: %q", data)
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("message is not JSON: %v", err)
+	}
+	if msg.Type != "user" || msg.Message.Role != "user" || len(msg.Message.Content) != 1 {
+		t.Fatalf("unexpected message: %s", data)
+	}
+	if got := msg.Message.Content[0].Text; got != `use the "v2" API instead` {
+		t.Errorf("text = %q", got)
+	}
+}
+
+func TestSendMessageNoStdin(t *testing.T) {
+	rt := &ClaudeRuntime{claudePath: "/usr/bin/claude"}
+	if err := rt.SendMessage(&Process{}, "hi"); err == nil {
+		t.Error("expected an error for a process without stdin")
+	}
+}
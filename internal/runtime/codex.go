@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+type CodexRuntime struct {
+	codexPath string
+	sandbox   string
+}
+
+// NewCodexRuntime finds the OpenAI Codex CLI. sandbox is passed to
+// --sandbox (read-only, workspace-write or danger-full-access); empty
+// leaves Codex's own default.
+func NewCodexRuntime(sandbox string) (*CodexRuntime, error) {
+	path, err := exec.LookPath("codex")
+	if err != nil {
+		return nil, fmt.Errorf("codex binary not found in PATH — install from https://github.com/openai/codex")
+	}
+	return &CodexRuntime{codexPath: path, sandbox: sandbox}, nil
+}
+
+func (c *CodexRuntime) BuildArgs(prompt string, opts RunOptions) []string {
+	args := []string{"exec", "--json", "--skip-git-repo-check"}
+	if opts.Model != "" {
+		args = append(args, "--model", opts.Model)
+	}
+	if c.sandbox != "" {
+		args = append(args, "--sandbox", c.sandbox)
+	}
+	// "--" keeps a prompt that starts with a dash from being read as a flag.
+	return append(args, "--", prompt)
+}
+
+func (c *CodexRuntime) Start(_ context.Context, prompt string, opts RunOptions) (*Process, error) {
+	args := c.BuildArgs(prompt, opts)
+	return startCommand(exec.Command(c.codexPath, args...), opts)
+}
+
+func (c *CodexRuntime) Stop(proc *Process) error {
+	return stopCommand(proc)
+}
+
+func (c *CodexRuntime) Pause(proc *Process) error {
+	return pauseCommand(proc)
+}
+
+func (c *CodexRuntime) Resume(proc *Process) error {
+	return resumeCommand(proc)
+}
@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodexBuildArgsMinimal(t *testing.T) {
+	rt := &CodexRuntime{codexPath: "/usr/bin/codex"}
+	args := rt.BuildArgs("do something", RunOptions{})
+
+	expected := []string{"exec", "--json", "--skip-git-repo-check", "--", "do something"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
+
+func TestCodexBuildArgsAllFlags(t *testing.T) {
+	rt := &CodexRuntime{codexPath: "/usr/bin/codex", sandbox: "workspace-write"}
+	args := rt.BuildArgs("build feature", RunOptions{
+		Model:    "gpt-5-codex",
+		MaxTurns: 10,
+	})
+
+	expected := []string{
+		"exec", "--json", "--skip-git-repo-check",
+		"--model", "gpt-5-codex",
+		"--sandbox", "workspace-write",
+		"--", "build feature",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
+
+func TestCodexBuildArgsDashPrompt(t *testing.T) {
+	rt := &CodexRuntime{codexPath: "/usr/bin/codex"}
+	args := rt.BuildArgs("-fix the flaky test", RunOptions{})
+
+	if n := len(args); n < 2 || args[n-2] != "--" || args[n-1] != "-fix the flaky test" {
+		t.Errorf("expected the prompt after --, got %v", args)
+	}
+}
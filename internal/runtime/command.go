@@ -0,0 +1,119 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// startCommand wires cmd's output to opts' log files (or to pipes when none
+// are set), starts it, and returns the running process. Every CLI runtime
+// launches its agent through here, inside opts.Sandbox when one is set.
+func startCommand(cmd *exec.Cmd, opts RunOptions) (*Process, error) {
+	proc := &Process{}
+	if opts.Sandbox != nil {
+		wrapped, container, err := opts.Sandbox.wrap(cmd, opts.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		cmd = wrapped
+		proc.Container = container
+		// Its own session makes the sandbox a process group leader, so
+		// signals reach the agent inside, and detaches it from the terminal.
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	}
+	if opts.WorkDir != "" {
+		cmd.Dir = opts.WorkDir
+	}
+	proc.Cmd = cmd
+
+	if opts.StdoutFile != nil {
+		cmd.Stdout = opts.StdoutFile
+		proc.StdoutPath = opts.StdoutFile.Name()
+	} else {
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, fmt.Errorf("stdout pipe: %w", err)
+		}
+		proc.Stdout = stdout
+	}
+
+	if opts.StderrFile != nil {
+		cmd.Stderr = opts.StderrFile
+		proc.StderrPath = opts.StderrFile.Name()
+	} else {
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			return nil, fmt.Errorf("stderr pipe: %w", err)
+		}
+		proc.Stderr = stderr
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+
+	proc.PID = cmd.Process.Pid
+
+	doneCh := make(chan error, 1)
+	go func() {
+		doneCh <- cmd.Wait()
+	}()
+	proc.Done = doneCh
+
+	return proc, nil
+}
+
+// startWithInput starts cmd like startCommand, with its stdin connected to
+// a pipe whose write end is left open in proc.Stdin.
+func startWithInput(cmd *exec.Cmd, opts RunOptions) (*Process, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdin pipe: %w", err)
+	}
+	cmd.Stdin = r
+	proc, err := startCommand(cmd, opts)
+	// The child holds its own copy of the read end.
+	r.Close()
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	proc.Stdin = w
+	return proc, nil
+}
+
+// stopCommand sends SIGTERM, then SIGKILL if the process is still running
+// five seconds later.
+func stopCommand(proc *Process) error {
+	if proc.Cmd == nil || proc.Cmd.Process == nil {
+		return nil
+	}
+	_ = Signal(proc.PID, proc.Container, syscall.SIGTERM)
+	go func() {
+		timer := time.NewTimer(5 * time.Second)
+		defer timer.Stop()
+		select {
+		case <-proc.Done:
+		case <-timer.C:
+			_ = Signal(proc.PID, proc.Container, syscall.SIGKILL)
+		}
+	}()
+	return nil
+}
+
+func pauseCommand(proc *Process) error {
+	if proc.Cmd == nil || proc.Cmd.Process == nil {
+		return nil
+	}
+	return Signal(proc.PID, proc.Container, syscall.SIGSTOP)
+}
+
+func resumeCommand(proc *Process) error {
+	if proc.Cmd == nil || proc.Cmd.Process == nil {
+		return nil
+	}
+	return Signal(proc.PID, proc.Container, syscall.SIGCONT)
+}
@@ -0,0 +1,44 @@
+package runtime
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartWithInput(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	proc, err := startWithInput(exec.Command("cat"), RunOptions{StdoutFile: out})
+	if err != nil {
+		t.Fatalf("startWithInput: %v", err)
+	}
+	if proc.Stdin == nil {
+		t.Fatal("expected Stdin to be set")
+	}
+
+	if _, err := proc.Stdin.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	proc.Stdin.Close()
+
+	// cat only exits once stdin is closed.
+	if err := <-proc.Done; err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello\n" {
+		t.Errorf("stdout = %q, want %q", data, "hello\n")
+	}
+}
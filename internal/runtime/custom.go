@@ -0,0 +1,101 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+)
+
+// CustomRuntime runs an arbitrary agent command configured under
+// [runtime.custom.<name>]. Each argument of the command is a template:
+// {prompt}, {model}, {skill} and {workdir} are replaced with the run's
+// values, and an argument that is exactly a placeholder is dropped when the
+// value is empty. If no argument uses {prompt}, the prompt is written to the
+// command's stdin instead.
+type CustomRuntime struct {
+	command  []string
+	protocol string
+}
+
+func NewCustomRuntime(name string, cfg config.CustomRuntimeConfig) (*CustomRuntime, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, fmt.Errorf("runtime.custom.%s has no command", name)
+	}
+	command := append([]string(nil), cfg.Command...)
+	path, err := exec.LookPath(command[0])
+	if err != nil {
+		return nil, fmt.Errorf("runtime.custom.%s: %s not found", name, command[0])
+	}
+	// A relative path would otherwise resolve against the run's worktree.
+	if strings.ContainsRune(command[0], filepath.Separator) {
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, fmt.Errorf("runtime.custom.%s: %w", name, err)
+		}
+	}
+	command[0] = path
+
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = ProtocolText
+	}
+	return &CustomRuntime{command: command, protocol: protocol}, nil
+}
+
+// Protocol returns the output protocol the command was configured with.
+func (c *CustomRuntime) Protocol() string {
+	return c.protocol
+}
+
+// BuildArgs expands the command template, without the executable. The
+// second result reports whether the prompt was placed in an argument.
+func (c *CustomRuntime) BuildArgs(prompt string, opts RunOptions) ([]string, bool) {
+	values := map[string]string{
+		"{prompt}":  prompt,
+		"{model}":   opts.Model,
+		"{skill}":   opts.Skill,
+		"{workdir}": opts.WorkDir,
+	}
+	pairs := make([]string, 0, 2*len(values))
+	for k, v := range values {
+		pairs = append(pairs, k, v)
+	}
+	r := strings.NewReplacer(pairs...)
+
+	args := make([]string, 0, len(c.command)-1)
+	usesPrompt := false
+	for _, tmpl := range c.command[1:] {
+		if strings.Contains(tmpl, "{prompt}") {
+			usesPrompt = true
+		}
+		if v, ok := values[tmpl]; ok && v == "" {
+			continue
+		}
+		args = append(args, r.Replace(tmpl))
+	}
+	return args, usesPrompt
+}
+
+func (c *CustomRuntime) Start(_ context.Context, prompt string, opts RunOptions) (*Process, error) {
+	args, usesPrompt := c.BuildArgs(prompt, opts)
+	cmd := exec.Command(c.command[0], args...)
+	if !usesPrompt {
+		cmd.Stdin = strings.NewReader(prompt)
+	}
+	return startCommand(cmd, opts)
+}
+
+func (c *CustomRuntime) Stop(proc *Process) error {
+	return stopCommand(proc)
+}
+
+func (c *CustomRuntime) Pause(proc *Process) error {
+	return pauseCommand(proc)
+}
+
+func (c *CustomRuntime) Resume(proc *Process) error {
+	return resumeCommand(proc)
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/justinpbarnett/agtop/internal/config"
+)
+
+func TestCustomBuildArgs(t *testing.T) {
+	rt := &CustomRuntime{command: []string{"/bin/agent", "run", "--model", "{model}", "--task={skill}", "{prompt}"}}
+	args, usesPrompt := rt.BuildArgs("fix it", RunOptions{Model: "opus", Skill: "build"})
+
+	expected := []string{"run", "--model", "opus", "--task=build", "fix it"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+	if !usesPrompt {
+		t.Error("expected the prompt to be passed as an argument")
+	}
+}
+
+func TestCustomBuildArgsDropsEmptyPlaceholders(t *testing.T) {
+	rt := &CustomRuntime{command: []string{"/bin/agent", "{model}", "--dir={workdir}"}}
+	args, usesPrompt := rt.BuildArgs("fix it", RunOptions{})
+
+	expected := []string{"--dir="}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+	if usesPrompt {
+		t.Error("expected the prompt to go to stdin")
+	}
+}
+
+func TestCustomStartWritesPromptToStdin(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	rt, err := NewCustomRuntime("echo", config.CustomRuntimeConfig{Command: []string{"cat"}})
+	if err != nil {
+		t.Fatalf("NewCustomRuntime: %v", err)
+	}
+	if rt.Protocol() != ProtocolText {
+		t.Errorf("expected default protocol %q, got %q", ProtocolText, rt.Protocol())
+	}
+
+	proc, err := rt.Start(context.Background(), "hello from stdin", RunOptions{WorkDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	out, _ := io.ReadAll(proc.Stdout)
+	if err := <-proc.Done; err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if string(out) != "hello from stdin" {
+		t.Errorf("expected prompt echoed back, got %q", out)
+	}
+}
+
+func TestNewCustomRuntimeMissingCommand(t *testing.T) {
+	_, err := NewCustomRuntime("ghost", config.CustomRuntimeConfig{Command: []string{"agtop-no-such-agent"}})
+	if err == nil {
+		t.Fatal("expected error for a missing command")
+	}
+}
+
+func TestProtocolFor(t *testing.T) {
+	cfg := &config.RuntimeConfig{Custom: map[string]config.CustomRuntimeConfig{
+		"wrapper": {Command: []string{"wrapper"}, Protocol: "stream-json"},
+		"plain":   {Command: []string{"plain"}},
+	}}
+	tests := map[string]string{
+		"":         ProtocolStreamJSON,
+		"claude":   ProtocolStreamJSON,
+		"opencode": ProtocolOpenCode,
+		"codex":    ProtocolCodex,
+		"wrapper":  ProtocolStreamJSON,
+		"plain":    ProtocolText,
+	}
+	for name, want := range tests {
+		if got := ProtocolFor(cfg, name); got != want {
+			t.Errorf("ProtocolFor(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/justinpbarnett/agtop/internal/config"
 )
@@ -11,13 +12,32 @@ import (
 const (
 	RuntimeClaude   = "claude"
 	RuntimeOpenCode = "opencode"
+	RuntimeCodex    = "codex"
+	RuntimeAider    = "aider"
+	RuntimeReplay   = "replay"
 )
 
 // NewRuntime creates a Runtime based on the configured default. If the preferred
 // runtime binary is missing, it falls back to the other. Returns an error only
-// if neither runtime is available.
+// if neither runtime is available. Codex, aider, replay and custom runtimes
+// never fall back: choosing one of them is deliberate.
 func NewRuntime(cfg *config.RuntimeConfig) (Runtime, string, error) {
+	if _, ok := cfg.Custom[cfg.Default]; ok {
+		rt, err := NewRuntimeByName(cfg, cfg.Default)
+		if err != nil {
+			return nil, "", err
+		}
+		return rt, cfg.Default, nil
+	}
+
 	switch cfg.Default {
+	case RuntimeCodex, RuntimeAider, RuntimeReplay:
+		rt, err := NewRuntimeByName(cfg, cfg.Default)
+		if err != nil {
+			return nil, "", err
+		}
+		return rt, cfg.Default, nil
+
 	case RuntimeOpenCode:
 		rt, err := NewOpenCodeRuntime()
 		if err == nil {
@@ -43,3 +63,68 @@ func NewRuntime(cfg *config.RuntimeConfig) (Runtime, string, error) {
 		return rt2, RuntimeOpenCode, nil
 	}
 }
+
+// NewRuntimeByName creates the named runtime without falling back to another.
+// It is used for runtimes selected per skill or per workflow.
+func NewRuntimeByName(cfg *config.RuntimeConfig, name string) (Runtime, error) {
+	switch name {
+	case RuntimeClaude:
+		rt, err := NewClaudeRuntime()
+		if err != nil {
+			return nil, err
+		}
+		return rt, nil
+	case RuntimeOpenCode:
+		rt, err := NewOpenCodeRuntime()
+		if err != nil {
+			return nil, err
+		}
+		return rt, nil
+	case RuntimeCodex:
+		rt, err := NewCodexRuntime(cfg.Codex.Sandbox)
+		if err != nil {
+			return nil, err
+		}
+		return rt, nil
+	case RuntimeAider:
+		rt, err := NewAiderRuntime()
+		if err != nil {
+			return nil, err
+		}
+		return rt, nil
+	case RuntimeReplay:
+		rt, err := newReplayFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return rt, nil
+	default:
+		if c, ok := cfg.Custom[name]; ok {
+			rt, err := NewCustomRuntime(name, c)
+			if err != nil {
+				return nil, err
+			}
+			return rt, nil
+		}
+		return nil, fmt.Errorf("unknown runtime %q", name)
+	}
+}
+
+// ProtocolFor returns the output protocol of the named runtime.
+func ProtocolFor(cfg *config.RuntimeConfig, name string) string {
+	if c, ok := cfg.Custom[name]; ok {
+		if c.Protocol == "" {
+			return ProtocolText
+		}
+		return c.Protocol
+	}
+	switch name {
+	case RuntimeOpenCode, RuntimeCodex, RuntimeAider:
+		return name
+	}
+	return ProtocolStreamJSON
+}
+
+func newReplayFromConfig(cfg *config.RuntimeConfig) (*ReplayRuntime, error) {
+	return NewReplayRuntime(cfg.Replay.Dir, time.Duration(cfg.Replay.Delay)*time.Millisecond)
+}
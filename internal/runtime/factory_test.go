@@ -53,3 +53,23 @@ func TestNewRuntimeEmptyDefault(t *testing.T) {
 		t.Errorf("expected runtime name 'claude' or 'opencode', got %q", name)
 	}
 }
+
+func TestNewRuntimeReplay(t *testing.T) {
+	cfg := &config.RuntimeConfig{Default: "replay", Replay: config.ReplayConfig{Dir: t.TempDir()}}
+
+	rt, name, err := NewRuntime(cfg)
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+	if _, ok := rt.(*ReplayRuntime); !ok || name != RuntimeReplay {
+		t.Errorf("expected replay runtime, got %T %q", rt, name)
+	}
+}
+
+func TestNewRuntimeReplayMissingDir(t *testing.T) {
+	cfg := &config.RuntimeConfig{Default: "replay", Replay: config.ReplayConfig{Dir: "/nonexistent/replay"}}
+
+	if _, _, err := NewRuntime(cfg); err == nil {
+		t.Fatal("expected error for a missing replay directory")
+	}
+}
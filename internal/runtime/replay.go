@@ -0,0 +1,361 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReplayRuntime stands in for a model by replaying scripts, so workflows
+// and skills can be exercised offline. Each Start picks a script by skill
+// name from the replay directory and writes it to stdout as Claude Code
+// stream-json, which the existing stream parser consumes unchanged.
+//
+// For the nth launch of a skill, the first of these files that exists is
+// used: <skill>.<n>.jsonl, <skill>.<n>.yaml, <skill>.jsonl, <skill>.yaml,
+// default.jsonl, default.yaml. A .jsonl file is a recorded transcript and
+// is replayed line by line; a .yaml file is a ReplayScript.
+type ReplayRuntime struct {
+	dir   string
+	delay time.Duration
+
+	mu       sync.Mutex
+	launches map[string]int
+	controls map[*Process]*replayControl
+}
+
+// ReplayScript is the YAML form of a scripted skill run.
+type ReplayScript struct {
+	Events []ReplayEvent `yaml:"events"`
+	Exit   int           `yaml:"exit"` // non-zero makes the process fail after the last event
+}
+
+// ReplayEvent is one step of a ReplayScript. Type is one of text,
+// tool_use, write or result. A write event creates Path (relative to the
+// working directory) with Content and reports it as a Write tool call.
+type ReplayEvent struct {
+	Type         string                 `yaml:"type"`
+	Text         string                 `yaml:"text"`
+	Name         string                 `yaml:"name"`
+	Input        map[string]interface{} `yaml:"input"`
+	Path         string                 `yaml:"path"`
+	Content      string                 `yaml:"content"`
+	InputTokens  int                    `yaml:"input_tokens"`
+	OutputTokens int                    `yaml:"output_tokens"`
+	Cost         float64                `yaml:"cost"`
+}
+
+var errReplayStopped = errors.New("replay stopped")
+
+// NewReplayRuntime creates a replay runtime reading scripts from dir. delay
+// is waited before each event.
+func NewReplayRuntime(dir string, delay time.Duration) (*ReplayRuntime, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("replay directory: %w", err)
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("replay directory %s not found", abs)
+	}
+	return &ReplayRuntime{
+		dir:      abs,
+		delay:    delay,
+		launches: make(map[string]int),
+		controls: make(map[*Process]*replayControl),
+	}, nil
+}
+
+func (r *ReplayRuntime) Start(_ context.Context, _ string, opts RunOptions) (*Process, error) {
+	skill := opts.Skill
+	if skill == "" {
+		skill = "default"
+	}
+	r.mu.Lock()
+	r.launches[skill]++
+	n := r.launches[skill]
+	r.mu.Unlock()
+
+	path, err := r.scriptPath(skill, n)
+	if err != nil {
+		return nil, err
+	}
+	lines, exit, err := loadReplay(path, opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	proc := &Process{}
+	var out io.Writer
+	var pw *io.PipeWriter
+	if opts.StdoutFile != nil {
+		out = opts.StdoutFile
+		proc.StdoutPath = opts.StdoutFile.Name()
+	} else {
+		var pr *io.PipeReader
+		pr, pw = io.Pipe()
+		out = pw
+		proc.Stdout = pr
+	}
+	if opts.StderrFile != nil {
+		proc.StderrPath = opts.StderrFile.Name()
+	} else {
+		proc.Stderr = io.NopCloser(strings.NewReader(""))
+	}
+
+	ctl := newReplayControl()
+	r.mu.Lock()
+	r.controls[proc] = ctl
+	r.mu.Unlock()
+
+	doneCh := make(chan error, 1)
+	proc.Done = doneCh
+	go func() {
+		err := r.play(ctl, out, lines)
+		if err == nil && exit != 0 {
+			err = fmt.Errorf("exit status %d", exit)
+		}
+		if pw != nil {
+			pw.Close()
+		}
+		r.mu.Lock()
+		delete(r.controls, proc)
+		r.mu.Unlock()
+		doneCh <- err
+	}()
+	return proc, nil
+}
+
+func (r *ReplayRuntime) play(ctl *replayControl, out io.Writer, lines []replayLine) error {
+	for _, line := range lines {
+		if !ctl.wait(r.delay) {
+			return errReplayStopped
+		}
+		if line.write != nil {
+			if err := line.write(); err != nil {
+				return err
+			}
+		}
+		if _, err := out.Write(append(line.data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scriptPath returns the script for the nth launch of skill.
+func (r *ReplayRuntime) scriptPath(skill string, n int) (string, error) {
+	var names []string
+	for _, base := range []string{skill + "." + strconv.Itoa(n), skill, "default"} {
+		names = append(names, base+".jsonl", base+".yaml")
+	}
+	for _, name := range names {
+		path := filepath.Join(r.dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no replay script for skill %q in %s", skill, r.dir)
+}
+
+func (r *ReplayRuntime) Stop(proc *Process) error {
+	if ctl := r.control(proc); ctl != nil {
+		ctl.stop()
+	}
+	return nil
+}
+
+func (r *ReplayRuntime) Pause(proc *Process) error {
+	if ctl := r.control(proc); ctl != nil {
+		ctl.setPaused(true)
+	}
+	return nil
+}
+
+func (r *ReplayRuntime) Resume(proc *Process) error {
+	if ctl := r.control(proc); ctl != nil {
+		ctl.setPaused(false)
+	}
+	return nil
+}
+
+func (r *ReplayRuntime) control(proc *Process) *replayControl {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.controls[proc]
+}
+
+// replayLine is one stream-json line, with the file write a write event
+// performs just before it is emitted.
+type replayLine struct {
+	data  []byte
+	write func() error
+}
+
+// loadReplay reads a transcript or script into the lines to emit.
+func loadReplay(path, workDir string) ([]replayLine, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("read replay script: %w", err)
+	}
+
+	if strings.HasSuffix(path, ".jsonl") {
+		var lines []replayLine
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for sc.Scan() {
+			if line := bytes.TrimSpace(sc.Bytes()); len(line) > 0 {
+				lines = append(lines, replayLine{data: append([]byte(nil), line...)})
+			}
+		}
+		return lines, 0, sc.Err()
+	}
+
+	var script ReplayScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, 0, fmt.Errorf("parse replay script %s: %w", path, err)
+	}
+	lines := make([]replayLine, 0, len(script.Events))
+	for i, ev := range script.Events {
+		line, err := ev.line(workDir)
+		if err != nil {
+			return nil, 0, fmt.Errorf("replay script %s: event %d: %w", path, i+1, err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, script.Exit, nil
+}
+
+// line converts the event to the stream-json Claude Code would print.
+func (ev ReplayEvent) line(workDir string) (replayLine, error) {
+	var msg map[string]interface{}
+	var write func() error
+
+	switch ev.Type {
+	case "text":
+		msg = assistantMessage(map[string]interface{}{"type": "text", "text": ev.Text})
+	case "tool_use":
+		if ev.Name == "" {
+			return replayLine{}, fmt.Errorf("tool_use needs a name")
+		}
+		input := ev.Input
+		if input == nil {
+			input = map[string]interface{}{}
+		}
+		msg = assistantMessage(map[string]interface{}{"type": "tool_use", "name": ev.Name, "input": input})
+	case "write":
+		if !filepath.IsLocal(ev.Path) {
+			return replayLine{}, fmt.Errorf("write path %q must be relative to the working directory", ev.Path)
+		}
+		target := filepath.Join(workDir, ev.Path)
+		content := ev.Content
+		write = func() error {
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			return os.WriteFile(target, []byte(content), 0o644)
+		}
+		msg = assistantMessage(map[string]interface{}{
+			"type":  "tool_use",
+			"name":  "Write",
+			"input": map[string]interface{}{"file_path": ev.Path, "content": ev.Content},
+		})
+	case "result":
+		msg = map[string]interface{}{
+			"type":   "result",
+			"result": ev.Text,
+			"usage": map[string]interface{}{
+				"input_tokens":  ev.InputTokens,
+				"output_tokens": ev.OutputTokens,
+			},
+			"total_cost_usd": ev.Cost,
+		}
+	default:
+		return replayLine{}, fmt.Errorf("unknown event type %q", ev.Type)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return replayLine{}, err
+	}
+	return replayLine{data: data, write: write}, nil
+}
+
+func assistantMessage(block map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "assistant",
+		"message": map[string]interface{}{"content": []interface{}{block}},
+	}
+}
+
+// replayControl lets Stop, Pause and Resume act on a replay in progress.
+type replayControl struct {
+	mu      sync.Mutex
+	resumed chan struct{} // closed while not paused
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func newReplayControl() *replayControl {
+	c := &replayControl{resumed: make(chan struct{}), stopped: make(chan struct{})}
+	close(c.resumed)
+	return c
+}
+
+// wait blocks while the replay is paused, then waits delay. It returns
+// false once the replay has been stopped.
+func (c *replayControl) wait(delay time.Duration) bool {
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+	select {
+	case <-resumed:
+	case <-c.stopped:
+		return false
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-c.stopped:
+			return false
+		}
+	}
+	select {
+	case <-c.stopped:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *replayControl) setPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.resumed:
+		if paused {
+			c.resumed = make(chan struct{})
+		}
+	default:
+		if !paused {
+			close(c.resumed)
+		}
+	}
+}
+
+func (c *replayControl) stop() {
+	c.once.Do(func() { close(c.stopped) })
+}
@@ -0,0 +1,159 @@
+package runtime
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeReplayFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func replayOutput(t *testing.T, rt *ReplayRuntime, opts RunOptions) (string, error) {
+	t.Helper()
+	proc, err := rt.Start(context.Background(), "prompt", opts)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	out, err := io.ReadAll(proc.Stdout)
+	if err != nil {
+		t.Fatalf("read stdout: %v", err)
+	}
+	select {
+	case err := <-proc.Done:
+		return string(out), err
+	case <-time.After(2 * time.Second):
+		t.Fatal("replay did not finish")
+		return "", nil
+	}
+}
+
+func TestReplayScript(t *testing.T) {
+	dir := t.TempDir()
+	writeReplayFile(t, dir, "build.yaml", `
+events:
+  - type: text
+    text: Reading the spec
+  - type: tool_use
+    name: Read
+    input: {file_path: main.go}
+  - type: write
+    path: pkg/out.txt
+    content: hello
+  - type: result
+    text: done
+    input_tokens: 100
+    output_tokens: 50
+    cost: 0.01
+`)
+	rt, err := NewReplayRuntime(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+
+	out, err := replayOutput(t, rt, RunOptions{Skill: "build", WorkDir: work})
+	if err != nil {
+		t.Fatalf("unexpected exit error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	want := []string{
+		`{"message":{"content":[{"text":"Reading the spec","type":"text"}]},"type":"assistant"}`,
+		`{"message":{"content":[{"input":{"file_path":"main.go"},"name":"Read","type":"tool_use"}]},"type":"assistant"}`,
+		`{"message":{"content":[{"input":{"content":"hello","file_path":"pkg/out.txt"},"name":"Write","type":"tool_use"}]},"type":"assistant"}`,
+		`{"result":"done","total_cost_usd":0.01,"type":"result","usage":{"input_tokens":100,"output_tokens":50}}`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want), len(lines), out)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d:\n got %s\nwant %s", i, lines[i], want[i])
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(work, "pkg", "out.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("expected write event to create the file, got %q, %v", data, err)
+	}
+}
+
+func TestReplayScriptSelection(t *testing.T) {
+	dir := t.TempDir()
+	writeReplayFile(t, dir, "review.1.jsonl", `{"type":"result","result":"first"}`+"\n")
+	writeReplayFile(t, dir, "review.yaml", "events:\n  - type: result\n    text: later\n")
+	writeReplayFile(t, dir, "default.yaml", "events:\n  - type: result\n    text: fallback\n")
+	rt, err := NewReplayRuntime(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct{ skill, want string }{
+		{"review", `"result":"first"`},
+		{"review", `"result":"later"`},
+		{"spec", `"result":"fallback"`},
+	} {
+		out, _ := replayOutput(t, rt, RunOptions{Skill: tt.skill})
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%s: expected %s, got %s", tt.skill, tt.want, out)
+		}
+	}
+}
+
+func TestReplayScriptExitAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeReplayFile(t, dir, "test.yaml", "exit: 2\nevents:\n  - type: result\n    text: failing\n")
+	writeReplayFile(t, dir, "bad.yaml", "events:\n  - type: sing\n")
+	writeReplayFile(t, dir, "escape.yaml", "events:\n  - type: write\n    path: ../x\n")
+	rt, err := NewReplayRuntime(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := replayOutput(t, rt, RunOptions{Skill: "test"}); err == nil || !strings.Contains(err.Error(), "exit status 2") {
+		t.Errorf("expected exit status 2, got %v", err)
+	}
+	for skill, wantErr := range map[string]string{
+		"bad":     `unknown event type "sing"`,
+		"escape":  "must be relative",
+		"missing": `no replay script for skill "missing"`,
+	} {
+		if _, err := rt.Start(context.Background(), "prompt", RunOptions{Skill: skill}); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", skill, wantErr, err)
+		}
+	}
+}
+
+func TestReplayStop(t *testing.T) {
+	dir := t.TempDir()
+	writeReplayFile(t, dir, "build.yaml", "events:\n  - type: text\n    text: a\n  - type: result\n    text: b\n")
+	rt, err := NewReplayRuntime(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proc, err := rt.Start(context.Background(), "prompt", RunOptions{Skill: "build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Pause(proc); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Stop(proc); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-proc.Done:
+		if err == nil {
+			t.Error("expected a stopped replay to report an error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stop did not end the replay")
+	}
+}
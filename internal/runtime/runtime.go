@@ -14,6 +14,29 @@ type Runtime interface {
 	Resume(proc *Process) error
 }
 
+// Output protocols the process manager can parse. Built-in runtimes other
+// than claude are named after their protocol.
+const (
+	ProtocolStreamJSON = "stream-json"
+	ProtocolOpenCode   = "opencode"
+	ProtocolCodex      = "codex"
+	ProtocolAider      = "aider"
+	ProtocolText       = "text"
+)
+
+// MessageRuntime is implemented by runtimes that accept user messages on
+// the stdin of a running process, so a conversation can be redirected
+// without restarting it. Processes they start have Stdin set.
+type MessageRuntime interface {
+	SendMessage(proc *Process, text string) error
+}
+
+// ProtocolRuntime is implemented by runtimes whose output protocol is not
+// implied by their name.
+type ProtocolRuntime interface {
+	Protocol() string
+}
+
 type RunOptions struct {
 	Model          string
 	WorkDir        string
@@ -21,6 +44,11 @@ type RunOptions struct {
 	MaxTurns       int
 	PermissionMode string
 	Agent          string
+	Skill          string   // Skill being launched (the replay runtime picks its script by it)
+	Runtime        string   // Runtime to launch with (empty = the manager's default)
+	Resume         string   // Session ID of an earlier conversation to carry on (claude only)
+	Continue       bool     // Carry on the most recent conversation in WorkDir (claude only)
+	Sandbox        *Sandbox // If set, confine the agent process (see Sandbox)
 	StdoutFile     *os.File // If set, redirect process stdout to this file instead of a pipe
 	StderrFile     *os.File // If set, redirect process stderr to this file instead of a pipe
 }
@@ -28,9 +56,11 @@ type RunOptions struct {
 type Process struct {
 	PID        int
 	Cmd        *exec.Cmd
+	Stdin      io.WriteCloser // Open while the process accepts messages (see MessageRuntime); nil otherwise
 	Stdout     io.ReadCloser
 	Stderr     io.ReadCloser
 	Done       <-chan error
 	StdoutPath string // Log file path (set when using file-based output)
 	StderrPath string // Log file path (set when using file-based output)
+	Container  string // Docker container the process runs in, if sandboxed with docker
 }
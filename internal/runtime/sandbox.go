@@ -0,0 +1,232 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	SandboxBwrap  = "bwrap"
+	SandboxDocker = "docker"
+)
+
+// Sandbox confines an agent process to its worktree. Every runtime that
+// launches a CLI applies it. Only the worktree (and its git metadata) is
+// writable, $HOME is hidden except for the Expose paths the agent needs for
+// its own config and credentials, and the network can be cut off. Of the
+// repository's shared git directory only the parts a commit writes to are
+// writable; its config and hooks stay read-only.
+type Sandbox struct {
+	Type    string   // SandboxBwrap or SandboxDocker
+	Network bool     // allow network access
+	Image   string   // docker image providing the agent CLI
+	Expose  []string // paths relative to $HOME made available read-write
+	Env     []string // docker: host environment variables passed through
+}
+
+// wrap returns the command that runs cmd inside the sandbox, and for docker
+// the name of the container it will create.
+func (s *Sandbox) wrap(cmd *exec.Cmd, workDir string) (*exec.Cmd, string, error) {
+	if workDir == "" {
+		return nil, "", fmt.Errorf("sandbox requires a working directory")
+	}
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("sandbox: %w", err)
+	}
+	home, _ := os.UserHomeDir()
+
+	var wrapped *exec.Cmd
+	var container string
+	switch s.Type {
+	case SandboxBwrap:
+		path, err := exec.LookPath("bwrap")
+		if err != nil {
+			return nil, "", fmt.Errorf("bwrap not found in PATH — install bubblewrap or disable the sandbox")
+		}
+		wrapped = exec.Command(path, s.bwrapArgs(cmd, workDir, home)...)
+	case SandboxDocker:
+		path, err := exec.LookPath("docker")
+		if err != nil {
+			return nil, "", fmt.Errorf("docker not found in PATH — install docker or disable the sandbox")
+		}
+		container = "agtop-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		wrapped = exec.Command(path, s.dockerArgs(cmd, workDir, home, container)...)
+	default:
+		return nil, "", fmt.Errorf("unknown sandbox %q", s.Type)
+	}
+	wrapped.Stdin = cmd.Stdin
+	wrapped.Env = cmd.Env
+	return wrapped, container, nil
+}
+
+func (s *Sandbox) bwrapArgs(cmd *exec.Cmd, workDir, home string) []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--unshare-pid",
+		"--unshare-ipc",
+	}
+	if home != "" {
+		args = append(args, "--tmpfs", home)
+		// The agent binary itself may live under $HOME (~/.local/bin).
+		if dir := executableDir(cmd.Path); dir != "" && within(dir, home) {
+			args = append(args, "--ro-bind", dir, dir)
+		}
+		if strings.HasPrefix(cmd.Path, home) {
+			args = append(args, "--ro-bind-try", cmd.Path, cmd.Path)
+		}
+		for _, p := range s.Expose {
+			target := filepath.Join(home, p)
+			args = append(args, "--bind-try", target, target)
+		}
+	}
+	if gitDir, commonDir := worktreeGitDirs(workDir); gitDir != "" {
+		args = append(args, "--ro-bind", commonDir, commonDir)
+		for _, p := range writableGitPaths(commonDir) {
+			args = append(args, "--bind", p, p)
+		}
+		args = append(args, "--bind", gitDir, gitDir)
+	}
+	args = append(args, "--bind", workDir, workDir, "--chdir", workDir)
+	if !s.Network {
+		args = append(args, "--unshare-net")
+	}
+	args = append(args, "--")
+	return append(args, cmd.Args...)
+}
+
+func (s *Sandbox) dockerArgs(cmd *exec.Cmd, workDir, home, container string) []string {
+	args := []string{"run", "--rm", "--name", container, "--init"}
+	if cmd.Stdin != nil {
+		args = append(args, "-i")
+	}
+	args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	if !s.Network {
+		args = append(args, "--network", "none")
+	}
+	if home != "" {
+		args = append(args, "-e", "HOME="+home)
+		for _, p := range s.Expose {
+			target := filepath.Join(home, p)
+			if _, err := os.Stat(target); err == nil {
+				args = append(args, "-v", target+":"+target)
+			}
+		}
+	}
+	for _, name := range s.Env {
+		args = append(args, "-e", name)
+	}
+	if gitDir, commonDir := worktreeGitDirs(workDir); gitDir != "" {
+		args = append(args, "-v", commonDir+":"+commonDir+":ro")
+		for _, p := range writableGitPaths(commonDir) {
+			args = append(args, "-v", p+":"+p)
+		}
+		args = append(args, "-v", gitDir+":"+gitDir)
+	}
+	args = append(args, "-v", workDir+":"+workDir, "-w", workDir, s.Image)
+	// The image provides its own copy of the agent CLI.
+	args = append(args, filepath.Base(cmd.Path))
+	return append(args, cmd.Args[1:]...)
+}
+
+// worktreeGitDirs returns the git directory of a linked worktree and the
+// repository's common git directory, or empty strings if workDir is not a
+// linked worktree.
+func worktreeGitDirs(workDir string) (gitDir, commonDir string) {
+	data, err := os.ReadFile(filepath.Join(workDir, ".git"))
+	if err != nil {
+		return "", ""
+	}
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", ""
+	}
+	gitDir = strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(workDir, gitDir)
+	}
+	commonDir = filepath.Join(gitDir, "..", "..")
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		if c := strings.TrimSpace(string(data)); filepath.IsAbs(c) {
+			commonDir = c
+		} else {
+			commonDir = filepath.Join(gitDir, c)
+		}
+	}
+	return filepath.Clean(gitDir), filepath.Clean(commonDir)
+}
+
+// sharedGitPaths are the parts of a repository's common git directory that
+// committing in a linked worktree writes: new objects, the branch ref and its
+// reflog.
+var sharedGitPaths = []string{"objects", "refs", "logs", "packed-refs"}
+
+// writableGitPaths returns the sharedGitPaths that exist in commonDir. The
+// logs directory is created first, as git cannot create it inside the
+// read-only mount.
+func writableGitPaths(commonDir string) []string {
+	_ = os.MkdirAll(filepath.Join(commonDir, "logs"), 0o755)
+	var paths []string
+	for _, name := range sharedGitPaths {
+		p := filepath.Join(commonDir, name)
+		if _, err := os.Stat(p); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// executableDir returns the directory holding the real executable behind
+// path, following symlinks.
+func executableDir(path string) string {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Dir(real)
+}
+
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// Signal delivers sig to an agent process started by a runtime, given only
+// its PID and container, so it also works for processes reconnected after a
+// restart. A sandboxed process leads its own process group, which is
+// signalled as a whole so the agent inside receives it too. A docker
+// container is paused, resumed and killed through docker, because the docker
+// client cannot pass SIGSTOP on.
+func Signal(pid int, container string, sig syscall.Signal) error {
+	if container != "" {
+		var args []string
+		switch sig {
+		case syscall.SIGSTOP:
+			args = []string{"pause", container}
+		case syscall.SIGCONT:
+			args = []string{"unpause", container}
+		default:
+			args = []string{"kill", "--signal", strconv.Itoa(int(sig)), container}
+		}
+		if err := exec.Command("docker", args...).Run(); err != nil {
+			return fmt.Errorf("docker %s: %w", args[0], err)
+		}
+		return nil
+	}
+	if pid <= 0 {
+		return nil
+	}
+	if pgid, err := syscall.Getpgid(pid); err == nil && pgid == pid {
+		return syscall.Kill(-pid, sig)
+	}
+	return syscall.Kill(pid, sig)
+}
@@ -0,0 +1,211 @@
+package runtime
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeWorktree lays out a linked worktree the way git does and returns its
+// path and git directory.
+func fakeWorktree(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	commonDir := filepath.Join(root, "repo", ".git")
+	gitDir := filepath.Join(commonDir, "worktrees", "001")
+	workDir := filepath.Join(root, "worktrees", "001")
+	for _, dir := range []string{gitDir, workDir, filepath.Join(commonDir, "objects"), filepath.Join(commonDir, "refs")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0o644)
+	os.WriteFile(filepath.Join(workDir, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644)
+	return workDir, gitDir
+}
+
+func TestWorktreeGitDirs(t *testing.T) {
+	workDir, gitDir := fakeWorktree(t)
+	gotGit, gotCommon := worktreeGitDirs(workDir)
+	if gotGit != gitDir {
+		t.Errorf("gitDir = %q, want %q", gotGit, gitDir)
+	}
+	if want := filepath.Dir(filepath.Dir(gitDir)); gotCommon != want {
+		t.Errorf("commonDir = %q, want %q", gotCommon, want)
+	}
+
+	if g, c := worktreeGitDirs(t.TempDir()); g != "" || c != "" {
+		t.Errorf("expected no git dirs outside a worktree, got %q, %q", g, c)
+	}
+}
+
+func TestBwrapArgs(t *testing.T) {
+	workDir, gitDir := fakeWorktree(t)
+	s := &Sandbox{Type: SandboxBwrap, Expose: []string{".claude"}}
+	cmd := exec.Command("/usr/bin/claude", "-p", "hi")
+
+	args := strings.Join(s.bwrapArgs(cmd, workDir, "/home/dev"), " ")
+	commonDir := filepath.Dir(filepath.Dir(gitDir))
+
+	for _, want := range []string{
+		"--ro-bind / /",
+		"--tmpfs /home/dev",
+		"--bind-try /home/dev/.claude /home/dev/.claude",
+		"--ro-bind " + commonDir + " " + commonDir,
+		"--bind " + filepath.Join(commonDir, "objects") + " " + filepath.Join(commonDir, "objects"),
+		"--bind " + filepath.Join(commonDir, "refs") + " " + filepath.Join(commonDir, "refs"),
+		"--bind " + filepath.Join(commonDir, "logs") + " " + filepath.Join(commonDir, "logs"),
+		"--bind " + gitDir + " " + gitDir,
+		"--bind " + workDir + " " + workDir,
+		"--chdir " + workDir,
+		"--unshare-net",
+		"-- /usr/bin/claude -p hi",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in bwrap args: %s", want, args)
+		}
+	}
+	if strings.Contains(args, "packed-refs") {
+		t.Errorf("expected no mount for a missing packed-refs: %s", args)
+	}
+	// Shared git paths must be bound over the read-only common dir.
+	if strings.Index(args, "--ro-bind "+commonDir) > strings.Index(args, "--bind "+filepath.Join(commonDir, "objects")) {
+		t.Errorf("writable git paths must come after the read-only common dir: %s", args)
+	}
+	// Home must be hidden before anything is bound inside it.
+	if strings.Index(args, "--tmpfs /home/dev") > strings.Index(args, "--bind-try /home/dev/.claude") {
+		t.Errorf("home tmpfs must come before exposed paths: %s", args)
+	}
+
+	s.Network = true
+	if args := strings.Join(s.bwrapArgs(cmd, workDir, "/home/dev"), " "); strings.Contains(args, "--unshare-net") {
+		t.Errorf("expected network to stay shared: %s", args)
+	}
+}
+
+func TestDockerArgs(t *testing.T) {
+	workDir, gitDir := fakeWorktree(t)
+	commonDir := filepath.Dir(filepath.Dir(gitDir))
+	s := &Sandbox{Type: SandboxDocker, Image: "agents:latest", Env: []string{"ANTHROPIC_API_KEY"}}
+	cmd := exec.Command("/home/dev/.local/bin/claude", "-p", "hi")
+
+	args := s.dockerArgs(cmd, workDir, "/home/dev", "agtop-x")
+	joined := strings.Join(args, " ")
+
+	for _, want := range []string{
+		"run --rm --name agtop-x",
+		"--network none",
+		"-e HOME=/home/dev",
+		"-e ANTHROPIC_API_KEY",
+		"-v " + commonDir + ":" + commonDir + ":ro",
+		"-v " + filepath.Join(commonDir, "objects") + ":" + filepath.Join(commonDir, "objects") + " ",
+		"-v " + filepath.Join(commonDir, "refs") + ":" + filepath.Join(commonDir, "refs") + " ",
+		"-v " + workDir + ":" + workDir + " -w " + workDir,
+		"agents:latest claude -p hi",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in docker args: %s", want, joined)
+		}
+	}
+	if strings.Contains(joined, " -i ") {
+		t.Errorf("expected no -i without stdin: %s", joined)
+	}
+}
+
+func TestBwrapCommitInWorktree(t *testing.T) {
+	if _, err := exec.LookPath("bwrap"); err != nil {
+		t.Skip("bwrap not available")
+	}
+	if err := exec.Command("bwrap", "--ro-bind", "/", "/", "--dev", "/dev", "--", "true").Run(); err != nil {
+		t.Skipf("bwrap cannot create a sandbox here: %v", err)
+	}
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	workDir := filepath.Join(root, "worktree")
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=agtop", "GIT_AUTHOR_EMAIL=agtop@localhost",
+		"GIT_COMMITTER_NAME=agtop", "GIT_COMMITTER_EMAIL=agtop@localhost",
+	)
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command(gitPath, append([]string{"-C", dir}, args...)...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	os.MkdirAll(repo, 0o755)
+	git(repo, "init", "-q")
+	git(repo, "commit", "-q", "--allow-empty", "-m", "init")
+	git(repo, "worktree", "add", "-q", "-b", "agtop/001", workDir)
+	os.WriteFile(filepath.Join(workDir, "change.txt"), []byte("change\n"), 0o644)
+
+	git(workDir, "add", "change.txt")
+
+	cmd := exec.Command(gitPath, "commit", "-q", "--no-verify", "-m", "sandboxed")
+	cmd.Env = env
+	s := &Sandbox{Type: SandboxBwrap}
+	wrapped, _, err := s.wrap(cmd, workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := wrapped.CombinedOutput(); err != nil {
+		t.Fatalf("commit in sandbox: %v\n%s", err, out)
+	}
+
+	if got := git(repo, "log", "-1", "--format=%s", "agtop/001"); got != "sandboxed" {
+		t.Errorf("branch head = %q, want the sandboxed commit", got)
+	}
+}
+
+func TestSandboxWrapErrors(t *testing.T) {
+	cmd := exec.Command("/bin/true")
+	if _, _, err := (&Sandbox{Type: SandboxBwrap}).wrap(cmd, ""); err == nil {
+		t.Error("expected error without a working directory")
+	}
+	if _, _, err := (&Sandbox{Type: "jail"}).wrap(cmd, t.TempDir()); err == nil || !strings.Contains(err.Error(), `unknown sandbox "jail"`) {
+		t.Errorf("expected unknown sandbox error, got %v", err)
+	}
+}
+
+func TestSignalProcessGroup(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	// A group leader whose child would outlive a signal to the leader alone.
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	if err := Signal(cmd.Process.Pid, "", syscall.SIGKILL); err != nil {
+		t.Fatalf("Signal: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process group was not killed")
+	}
+	// The orphaned sleep is reaped by init shortly after it is killed.
+	deadline := time.Now().Add(2 * time.Second)
+	for syscall.Kill(-cmd.Process.Pid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected every process in the group to be gone")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
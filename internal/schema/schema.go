@@ -0,0 +1,286 @@
+// Package schema checks skill outputs against the JSON Schema a skill
+// declares under `outputs:` in its SKILL.md frontmatter. Only the keywords
+// that describe a result's shape are supported: type, properties, required,
+// items and enum. Annotations such as description are ignored.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema is a parsed JSON Schema.
+type Schema struct {
+	Types      []string
+	Properties map[string]*Schema
+	Required   []string
+	Items      *Schema
+	Enum       []interface{}
+
+	raw interface{}
+}
+
+var knownTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// Parse builds a schema from a decoded YAML or JSON document.
+func Parse(doc interface{}) (*Schema, error) {
+	return parse(normalize(doc), "outputs")
+}
+
+func parse(doc interface{}, path string) (*Schema, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object", path)
+	}
+	s := &Schema{raw: m}
+
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		s.Types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: type must be a string or list of strings", path)
+			}
+			s.Types = append(s.Types, name)
+		}
+	default:
+		return nil, fmt.Errorf("%s: type must be a string or list of strings", path)
+	}
+	for _, t := range s.Types {
+		if !knownTypes[t] {
+			return nil, fmt.Errorf("%s: unknown type %q", path, t)
+		}
+	}
+
+	if props, ok := m["properties"]; ok {
+		pm, ok := props.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: properties must be an object", path)
+		}
+		s.Properties = make(map[string]*Schema, len(pm))
+		for name, sub := range pm {
+			ps, err := parse(sub, path+"."+name)
+			if err != nil {
+				return nil, err
+			}
+			s.Properties[name] = ps
+		}
+	}
+
+	if req, ok := m["required"]; ok {
+		list, ok := req.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: required must be a list", path)
+		}
+		for _, v := range list {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: required must list property names", path)
+			}
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	if items, ok := m["items"]; ok {
+		is, err := parse(items, path+"[]")
+		if err != nil {
+			return nil, err
+		}
+		s.Items = is
+	}
+
+	if enum, ok := m["enum"]; ok {
+		list, ok := enum.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: enum must be a list", path)
+		}
+		s.Enum = list
+	}
+
+	return s, nil
+}
+
+// Validate checks a decoded JSON value against the schema. The error names
+// the path of the first value that does not match.
+func (s *Schema) Validate(v interface{}) error {
+	return s.validate(v, "")
+}
+
+func (s *Schema) validate(v interface{}, path string) error {
+	if len(s.Types) > 0 {
+		ok := false
+		for _, t := range s.Types {
+			if isType(v, t) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%sexpected %s, got %s", prefix(path), strings.Join(s.Types, " or "), typeName(v))
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		ok := false
+		for _, e := range s.Enum {
+			if equal(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s%s is not one of %s", prefix(path), format(v), format(s.Enum))
+		}
+	}
+
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := x[name]; !ok {
+				return fmt.Errorf("%smissing required property %q", prefix(path), name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if pv, ok := x[name]; ok {
+				if err := s.Properties[name].validate(pv, join(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range x {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// String returns the schema as indented JSON, suitable for a prompt.
+func (s *Schema) String() string {
+	data, err := json.MarshalIndent(s.raw, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func isType(v interface{}, t string) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == float64(int64(f))
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func equal(a, b interface{}) bool {
+	return format(a) == format(b)
+}
+
+func format(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func prefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// normalize converts YAML-decoded values to the types encoding/json
+// produces, so schemas written in frontmatter compare like JSON ones.
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			out[k] = normalize(val)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			out[fmt.Sprint(k)] = normalize(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i, val := range x {
+			out[i] = normalize(val)
+		}
+		return out
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	}
+	return v
+}
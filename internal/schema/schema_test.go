@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const reviewSchemaYAML = `
+type: object
+required: [success, review_issues]
+properties:
+  success:
+    type: boolean
+  review_summary:
+    type: string
+    description: short summary
+  review_issues:
+    type: array
+    items:
+      type: object
+      required: [issue_severity]
+      properties:
+        review_issue_number:
+          type: integer
+        issue_severity:
+          type: string
+          enum: [skippable, tech_debt, blocker]
+`
+
+func mustParseYAML(t *testing.T, src string) *Schema {
+	t.Helper()
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	s, err := Parse(doc)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return s
+}
+
+func decode(t *testing.T, src string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(src), &v); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	return v
+}
+
+func TestValidate(t *testing.T) {
+	s := mustParseYAML(t, reviewSchemaYAML)
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"valid", `{"success": true, "review_issues": []}`, ""},
+		{"valid issues", `{"success": false, "review_issues": [{"review_issue_number": 1, "issue_severity": "blocker"}]}`, ""},
+		{"extra properties allowed", `{"success": true, "review_issues": [], "screenshots": []}`, ""},
+		{"not an object", `[1, 2]`, "expected object, got array"},
+		{"missing required", `{"success": true}`, `missing required property "review_issues"`},
+		{"wrong type", `{"success": "yes", "review_issues": []}`, "success: expected boolean, got string"},
+		{"bad enum", `{"success": true, "review_issues": [{"issue_severity": "minor"}]}`, `review_issues[0].issue_severity: "minor" is not one of`},
+		{"not an integer", `{"success": true, "review_issues": [{"review_issue_number": 1.5, "issue_severity": "blocker"}]}`, "review_issues[0].review_issue_number: expected integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(decode(t, tt.value))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateTypeList(t *testing.T) {
+	s := mustParseYAML(t, `type: [string, "null"]`)
+	if err := s.Validate(nil); err != nil {
+		t.Errorf("null should match: %v", err)
+	}
+	if err := s.Validate("x"); err != nil {
+		t.Errorf("string should match: %v", err)
+	}
+	if err := s.Validate(1.0); err == nil {
+		t.Error("number should not match")
+	}
+}
+
+func TestValidateNumericEnumFromYAML(t *testing.T) {
+	// YAML decodes 1 as int while JSON decodes it as float64.
+	s := mustParseYAML(t, `enum: [1, 2]`)
+	if err := s.Validate(decode(t, `2`)); err != nil {
+		t.Errorf("expected 2 to match: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{`type: widget`, `unknown type "widget"`},
+		{`properties: [a, b]`, "properties must be an object"},
+		{`required: success`, "required must be a list"},
+		{"properties:\n  path:\n    type: 3", "outputs.path: type must be"},
+		{`items: true`, "outputs[]: schema must be an object"},
+	}
+	for _, tt := range tests {
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(tt.src), &doc); err != nil {
+			t.Fatalf("yaml: %v", err)
+		}
+		_, err := Parse(doc)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Parse(%q): expected error containing %q, got %v", tt.src, tt.wantErr, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	s := mustParseYAML(t, "type: object\nproperties:\n  path:\n    type: string\n")
+	var back map[string]interface{}
+	if err := json.Unmarshal([]byte(s.String()), &back); err != nil {
+		t.Fatalf("String() is not JSON: %v", err)
+	}
+	if back["type"] != "object" {
+		t.Errorf("expected type object, got %v", back["type"])
+	}
+}
@@ -10,10 +10,13 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/justinpbarnett/agtop/internal/config"
+	"github.com/justinpbarnett/agtop/internal/control"
 	"github.com/justinpbarnett/agtop/internal/cost"
 	"github.com/justinpbarnett/agtop/internal/engine"
 	gitpkg "github.com/justinpbarnett/agtop/internal/git"
@@ -66,6 +69,7 @@ type App struct {
 	diffGen         *gitpkg.DiffGenerator
 	persistence     *run.Persistence
 	jiraExpander    *jira.Expander
+	controlServer   *control.Server
 	pidWatchCancel  func()
 	width           int
 	height          int
@@ -78,6 +82,7 @@ type App struct {
 	helpOverlay     *panels.HelpOverlay
 	newRunModal     *panels.NewRunModal
 	followUpModal   *panels.FollowUpModal
+	interjectModal  *panels.InterjectModal
 	runPickerModal  *panels.RunPickerModal
 	onboarding      *panels.OnboardingModal
 	keys            KeyMap
@@ -86,6 +91,28 @@ type App struct {
 	updateRepo      string
 	fullscreenPanel int                  // -1 = normal layout, panelDetail/panelLogView = fullscreen
 	runStates       map[string]run.State // tracks previous run states to detect transitions
+	replay          Stepper              // set for `agtop replay`; the dashboard is read-only
+	newRunForecast  panels.NewRunForecastFunc
+}
+
+// Stepper advances a paused replay by one event.
+type Stepper interface {
+	Step()
+}
+
+// replayKeys are the keys a replay dashboard handles: moving between
+// panels and runs, scrolling, searching, copying, help, quitting and
+// pausing the replay. Any other key could change a run and is refused.
+var replayKeys = map[string]bool{
+	"esc": true, "ctrl+c": true, "q": true, "?": true,
+	"tab": true, "1": true, "2": true, "3": true,
+	"h": true, "l": true, "left": true, "right": true,
+	"j": true, "k": true, "up": true, "down": true,
+	"g": true, "G": true, "[": true, "]": true,
+	"pgup": true, "pgdown": true, "home": true, "end": true,
+	"ctrl+d": true, "ctrl+u": true, "ctrl+f": true, "ctrl+b": true,
+	"/": true, "N": true, "y": true, "enter": true, "ctrl+o": true,
+	" ": true,
 }
 
 func NewApp(cfg *config.Config) App {
@@ -93,12 +120,20 @@ func NewApp(cfg *config.Config) App {
 
 	tracker := cost.NewTracker()
 	maxCostPerRun := cfg.Limits.MaxCostPerRun
+	budget := cost.Budget{
+		Daily:   cfg.Limits.DailyBudget,
+		Weekly:  cfg.Limits.WeeklyBudget,
+		Monthly: cfg.Limits.MonthlyBudget,
+	}
 	if cfg.Runtime.Default == "claude" && cfg.Runtime.Claude.Subscription {
-		maxCostPerRun = 0 // subscription billing — disable cost threshold
+		// Subscription billing — disable the cost threshold and budgets.
+		maxCostPerRun = 0
+		budget = cost.Budget{}
 	}
 	limiter := &cost.LimitChecker{
 		MaxTokensPerRun: cfg.Limits.MaxTokensPerRun,
 		MaxCostPerRun:   maxCostPerRun,
+		Budget:          budget,
 	}
 
 	var safetyMatcher *safety.PatternMatcher
@@ -132,6 +167,24 @@ func NewApp(cfg *config.Config) App {
 		log.Printf("warning: %v (starting without process management)", rtErr)
 	} else {
 		mgr = process.NewManager(store, rt, rtName, sessionsDir, &cfg.Limits, tracker, limiter, safetyMatcher)
+		mgr.SetPricing(cfg.Pricing)
+		mgr.SetSkillLimits(cfg.Skills)
+		if dir, err := cost.LedgerDir(); err != nil {
+			log.Printf("warning: spend ledger: %v", err)
+		} else {
+			mgr.SetLedger(cost.NewLedger(dir))
+		}
+		for _, name := range cfg.RuntimeOverrides() {
+			if name == rtName {
+				continue
+			}
+			extra, err := runtime.NewRuntimeByName(&cfg.Runtime, name)
+			if err != nil {
+				log.Printf("warning: runtime %s: %v", name, err)
+				continue
+			}
+			mgr.AddRuntime(name, extra)
+		}
 	}
 
 	reg := engine.NewRegistry(cfg)
@@ -145,30 +198,14 @@ func NewApp(cfg *config.Config) App {
 	}
 
 	// Discover repositories (single repo or multi-repo)
-	var repos []string
-	if len(cfg.Project.Repos) > 0 {
-		// Explicit repos from config — resolve relative to project root
-		for _, r := range cfg.Project.Repos {
-			if filepath.IsAbs(r) {
-				repos = append(repos, r)
-			} else {
-				repos = append(repos, filepath.Join(projectRoot, r))
-			}
-		}
-	} else {
-		repos, _ = gitpkg.DiscoverRepos(projectRoot)
-	}
-
-	var wt *gitpkg.WorktreeManager
+	repos := gitpkg.ProjectRepos(projectRoot, cfg.Project.Repos)
+	wt := gitpkg.NewProjectWorktreeManager(projectRoot, repos, cfg.Project.WorktreePath)
 	var dg *gitpkg.DiffGenerator
 	if len(repos) > 1 {
-		wt = gitpkg.NewMultiRepoWorktreeManager(projectRoot, repos)
 		dg = gitpkg.NewMultiRepoDiffGenerator(projectRoot, repos)
 	} else if len(repos) == 1 {
-		wt = gitpkg.NewWorktreeManagerAt(repos[0], cfg.Project.WorktreePath)
 		dg = gitpkg.NewDiffGenerator(repos[0])
 	} else {
-		wt = gitpkg.NewWorktreeManagerAt(projectRoot, cfg.Project.WorktreePath)
 		dg = gitpkg.NewDiffGenerator(projectRoot)
 	}
 
@@ -193,6 +230,7 @@ func NewApp(cfg *config.Config) App {
 			}
 			cb.Reconnect = mgr.Reconnect
 			cb.ReplayLogFile = mgr.ReplayLogFile
+			cb.RestoreEvents = mgr.RestoreEventLog
 		}
 		rehydrateResult, cancel, rehydrateErr := persist.RehydrateWithWatcher(store, cb)
 		if rehydrateErr != nil {
@@ -203,6 +241,15 @@ func NewApp(cfg *config.Config) App {
 			log.Printf("rehydrated %d runs from session", rehydrateResult.Count)
 		}
 
+		// Removed runs still count toward cost forecasts.
+		if archived, err := persist.LoadArchive(); err != nil {
+			log.Printf("warning: %v", err)
+		} else {
+			for _, ar := range archived {
+				tracker.AddHistory(ar.Run.SkillCosts...)
+			}
+		}
+
 		// Resume workflows for reconnected runs
 		if exec != nil {
 			for _, id := range rehydrateResult.ReconnectedIDs {
@@ -261,10 +308,26 @@ func NewApp(cfg *config.Config) App {
 	}
 	d := panels.NewDetail()
 
+	var newRunForecast panels.NewRunForecastFunc
+	if exec != nil {
+		d.SetForecaster(func(r *run.Run) (cost.Forecast, bool) {
+			return tracker.Forecast(exec.RemainingSteps(*r))
+		})
+		newRunForecast = func(workflow string) (string, string) {
+			f, ok := tracker.Forecast(exec.PlannedSteps(workflow))
+			if !ok {
+				return "", ""
+			}
+			_, warning := limiter.CheckForecast(f)
+			return f.String(), warning
+		}
+	}
+
 	selected := rl.SelectedRun()
 	d.SetRun(selected)
 	if selected != nil && mgr != nil {
 		lv.SetRun(selected.ID, selected.CurrentSkill, selected.Branch, mgr.Buffer(selected.ID), mgr.EntryBuffer(selected.ID), !selected.IsTerminal())
+		lv.SetTimeline(mgr.Timeline(selected.ID))
 	}
 
 	app := App{
@@ -287,6 +350,20 @@ func NewApp(cfg *config.Config) App {
 		keys:            DefaultKeyMap(),
 		fullscreenPanel: -1,
 		runStates:       runStates,
+		newRunForecast:  newRunForecast,
+	}
+
+	if mgr != nil && mgr.Ledger() != nil {
+		app.statusBar.SetBudget(mgr.Ledger(), limiter.Budget)
+	}
+
+	// Config warnings go to the status bar; stderr is hidden by the alt screen.
+	if warnings := append(cfg.Warnings(), reg.PricingWarnings()...); len(warnings) > 0 {
+		flash := warnings[0]
+		if len(warnings) > 1 {
+			flash += fmt.Sprintf(" (+%d more warnings)", len(warnings)-1)
+		}
+		app.statusBar.SetFlashWithLevel(flash, panels.FlashWarning)
 	}
 
 	if !config.LocalConfigExists() {
@@ -297,9 +374,59 @@ func NewApp(cfg *config.Config) App {
 		app.updateRepo = cfg.Update.Repo
 	}
 
+	// Control socket: lets external tools drive this instance. The handler
+	// captures the app before the server field is set; it only needs the
+	// shared store/executor/worktree pointers.
+	if sessionsDir != "" {
+		srv := control.NewServer(control.SocketPath(sessionsDir), store, controlHandler{app: app})
+		if err := srv.Start(); err != nil {
+			log.Printf("warning: %v (control socket disabled)", err)
+		} else {
+			app.controlServer = srv
+		}
+	}
+
 	return app
 }
 
+// NewReplayApp builds a read-only dashboard over a store and manager that
+// are replaying a captured run. Nothing is persisted and runs cannot be
+// started, accepted or removed.
+func NewReplayApp(cfg *config.Config, store *run.Store, mgr *process.Manager, stepper Stepper) App {
+	rl := panels.NewRunList(store)
+	rl.SetFocused(true)
+	lv := panels.NewLogView()
+	if cfg.UI.LogScrollSpeed > 0 {
+		lv.SetScrollSpeed(cfg.UI.LogScrollSpeed)
+	}
+	d := panels.NewDetail()
+
+	selected := rl.SelectedRun()
+	d.SetRun(selected)
+	if selected != nil {
+		lv.SetRun(selected.ID, selected.CurrentSkill, selected.Branch, mgr.Buffer(selected.ID), mgr.EntryBuffer(selected.ID), !selected.IsTerminal())
+		lv.SetTimeline(mgr.Timeline(selected.ID))
+	}
+
+	sb := panels.NewStatusBar(store)
+	sb.SetFlash("Replay: Space pause/resume, . step")
+
+	return App{
+		config:          cfg,
+		store:           store,
+		manager:         mgr,
+		devServers:      server.NewDevServerManager(cfg.Project.DevServer),
+		runList:         rl,
+		logView:         lv,
+		detail:          d,
+		statusBar:       sb,
+		keys:            DefaultKeyMap(),
+		fullscreenPanel: -1,
+		runStates:       make(map[string]run.State),
+		replay:          stepper,
+	}
+}
+
 func (a App) Init() tea.Cmd {
 	cmds := []tea.Cmd{listenForChanges(a.store.Changes()), tickCmd(), animTickCmd()}
 	if a.updateRepo != "" {
@@ -322,12 +449,16 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.followUpModal != nil {
 			a.followUpModal.SetSize(msg.Width, msg.Height)
 		}
+		if a.interjectModal != nil {
+			a.interjectModal.SetSize(msg.Width, msg.Height)
+		}
 		return a, nil
 
 	case CloseModalMsg:
 		a.helpOverlay = nil
 		a.newRunModal = nil
 		a.followUpModal = nil
+		a.interjectModal = nil
 		a.runPickerModal = nil
 		a.onboarding = nil
 		return a, nil
@@ -365,6 +496,14 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case SpecResultMsg:
+		if msg.Err != nil {
+			a.detail.SetSpec(msg.RunID, fmt.Sprintf("(cannot read spec: %v)", msg.Err))
+		} else {
+			a.detail.SetSpec(msg.RunID, msg.Content)
+		}
+		return a, nil
+
 	case TickMsg:
 		return a, tickCmd()
 
@@ -419,63 +558,10 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case StartRunMsg:
 		if a.executor != nil {
-			newRun := &run.Run{
-				Workflow:  msg.Workflow,
-				Prompt:    msg.Prompt,
-				State:     run.StateQueued,
-				CreatedAt: time.Now(),
-			}
-			if msg.Model != "" {
-				newRun.Model = msg.Model
-			}
-			if a.jiraExpander != nil {
-				if key := a.jiraExpander.ExtractKey(msg.Prompt); key != "" {
-					if _, exists := a.store.Get(key); !exists {
-						newRun.ID = key
-					}
-				}
-			}
-			runID := a.store.Add(newRun)
-
-			if len(a.config.Repos) > 0 {
-				result, err := a.worktrees.CreateMulti(runID, a.config.Repos)
-				if err != nil {
-					a.store.Update(runID, func(r *run.Run) {
-						r.State = run.StateFailed
-						r.Error = fmt.Sprintf("worktree create: %v", err)
-					})
-					return a, nil
-				}
-				a.store.Update(runID, func(r *run.Run) {
-					r.Worktree = result.RootPath
-					r.Branch = result.Branch
-					r.SubWorktrees = make([]run.SubWorktreeInfo, len(result.SubWorktrees))
-					for i, sw := range result.SubWorktrees {
-						r.SubWorktrees[i] = run.SubWorktreeInfo{
-							Name:     sw.Name,
-							Path:     sw.Path,
-							RepoRoot: sw.RepoRoot,
-						}
-					}
-				})
-			} else {
-				wtPath, branch, err := a.worktrees.Create(runID)
-				if err != nil {
-					a.store.Update(runID, func(r *run.Run) {
-						r.State = run.StateFailed
-						r.Error = fmt.Sprintf("worktree create: %v", err)
-					})
-					return a, nil
-				}
-				a.store.Update(runID, func(r *run.Run) {
-					r.Worktree = wtPath
-					r.Branch = branch
-				})
+			if _, err := a.startRun(msg); err != nil {
+				a.statusBar.SetFlashWithLevel(err.Error(), panels.FlashError)
+				return a, flashClearCmd()
 			}
-
-
-
-			a.executor.Execute(runID, msg.Workflow, msg.Prompt)
 		}
 		return a, nil
 
@@ -488,6 +574,13 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case SubmitInterjectMsg:
+		if err := a.interject(msg.RunID, msg.Message); err != nil {
+			a.statusBar.SetFlashWithLevel(errorFlash(err), panels.FlashError)
+			return a, flashClearCmd()
+		}
+		return a, nil
+
 	case UpdateAppliedMsg:
 		a.statusBar.SetFlashWithLevel(
 			fmt.Sprintf("Updated to v%s — please restart agtop", msg.Version),
@@ -580,6 +673,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, cmd
 		}
 
+		if a.interjectModal != nil {
+			var cmd tea.Cmd
+			a.interjectModal, cmd = a.interjectModal.Update(msg)
+			return a, cmd
+		}
+
 		if a.newRunModal != nil {
 			var cmd tea.Cmd
 			a.newRunModal, cmd = a.newRunModal.Update(msg)
@@ -605,6 +704,21 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, cmd
 		}
 
+		if a.replay != nil {
+			key := msg.String()
+			switch {
+			case key == ".":
+				a.replay.Step()
+				return a, nil
+			case key != "ctrl+c" && a.focusedPanel == panelRunList && a.runList.FilterActive():
+				// Typing a filter only narrows the list.
+				return a.routeKey(msg)
+			case !replayKeys[key]:
+				a.statusBar.SetFlashWithLevel("Replay is read-only", panels.FlashWarning)
+				return a, flashClearCmd()
+			}
+		}
+
 		switch msg.String() {
 		case "esc":
 			if a.fullscreenPanel >= 0 {
@@ -664,6 +778,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 		case "n":
 			a.newRunModal = panels.NewNewRunModal(a.width, a.height)
+			a.newRunModal.SetForecaster(a.newRunForecast)
 			return a, a.newRunModal.Init()
 		case "a":
 			return a.handleAccept()
@@ -684,6 +799,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a.handleDevServerToggle()
 		case "u":
 			return a.handleFollowUp()
+		case "i":
+			return a.handleInterject()
 		case "enter":
 			if a.focusedPanel == panelRunList && !a.runList.FilterActive() {
 				return a.handleRunPicker()
@@ -763,6 +880,15 @@ func (a App) View() string {
 		)
 	}
 
+	if a.interjectModal != nil {
+		modalView := a.interjectModal.View()
+		fullLayout = lipgloss.Place(a.width, a.height,
+			lipgloss.Center, lipgloss.Center, modalView,
+			lipgloss.WithWhitespaceChars(" "),
+			lipgloss.WithWhitespaceForeground(styles.TextDim),
+		)
+	}
+
 	if a.runPickerModal != nil {
 		modalView := a.runPickerModal.View()
 		fullLayout = lipgloss.Place(a.width, a.height,
@@ -810,11 +936,14 @@ func (a App) cleanup() {
 		a.persistence.FinalSave(a.store, a.manager.LogFilePaths)
 	}
 
-	// 5. Stop dev servers and PID watchers
+	// 5. Stop dev servers, PID watchers and the control socket
 	a.devServers.StopAll()
 	if a.pidWatchCancel != nil {
 		a.pidWatchCancel()
 	}
+	if a.controlServer != nil {
+		_ = a.controlServer.Close()
+	}
 }
 
 func (a App) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
@@ -905,9 +1034,11 @@ func (a *App) syncSelection() tea.Cmd {
 	if selected != nil {
 		var buf *process.RingBuffer
 		var eb *process.EntryBuffer
+		var tl *process.Timeline
 		if a.manager != nil {
 			buf = a.manager.Buffer(selected.ID)
 			eb = a.manager.EntryBuffer(selected.ID)
+			tl = a.manager.Timeline(selected.ID)
 		}
 
 		if selected.ID == a.lastSyncedRunID {
@@ -918,18 +1049,26 @@ func (a *App) syncSelection() tea.Cmd {
 			a.logView.SetRun(selected.ID, selected.CurrentSkill, selected.Branch, buf, eb, !selected.IsTerminal())
 			a.lastSyncedRunID = selected.ID
 		}
+		a.logView.SetTimeline(tl)
+
+		var specCmd tea.Cmd
+		if selected.State == run.StateAwaitingApproval && selected.SpecFile != "" {
+			specCmd = fetchSpec(selected.ID, selected.Worktree, selected.SpecFile)
+		}
 
 		if selected.Worktree != "" {
 			a.logView.SetDiffLoading()
-			return a.fetchDiff(selected.ID, selected.Worktree, selected.SubWorktrees)
+			return tea.Batch(a.fetchDiff(selected.ID, selected.Worktree, selected.SubWorktrees), specCmd)
 		}
 		if selected.State == run.StateQueued || selected.State == run.StateRouting {
 			a.logView.SetDiffWaiting()
 		} else {
 			a.logView.SetDiffNoBranch()
 		}
+		return specCmd
 	} else {
 		a.logView.SetRun("", "", "", nil, nil, false)
+		a.logView.SetTimeline(nil)
 		a.lastSyncedRunID = ""
 		a.logView.SetDiffEmpty()
 	}
@@ -966,6 +1105,23 @@ func (a *App) fetchDiff(runID, worktreeDir string, subWorktrees []run.SubWorktre
 	}
 }
 
+// fetchSpec reads the spec of a run waiting at an approve gate so it can be
+// reviewed before approving. Relative spec paths are resolved against the
+// run's worktree.
+func fetchSpec(runID, worktreeDir, specFile string) tea.Cmd {
+	return func() tea.Msg {
+		path := specFile
+		if !filepath.IsAbs(path) && worktreeDir != "" {
+			path = filepath.Join(worktreeDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return SpecResultMsg{RunID: runID, Err: err}
+		}
+		return SpecResultMsg{RunID: runID, Content: string(data)}
+	}
+}
+
 func (a *App) propagateSizes() {
 	l := a.layout
 	if a.fullscreenPanel == panelDetail {
@@ -990,6 +1146,40 @@ func (a *App) updateFocusState() {
 	a.detail.SetFocused(a.focusedPanel == panelDetail)
 }
 
+// startRun creates a run, sets up its worktree and hands it to the executor.
+// It returns the new run ID. Safe to call from goroutines (used by the control socket).
+func (a App) startRun(msg StartRunMsg) (string, error) {
+	if a.manager != nil {
+		if err := a.manager.CheckBudget(); err != nil {
+			return "", fmt.Errorf("not starting run: %w", err)
+		}
+	}
+	newRun := &run.Run{
+		Workflow:  msg.Workflow,
+		Prompt:    msg.Prompt,
+		State:     run.StateQueued,
+		CreatedAt: time.Now(),
+	}
+	if msg.Model != "" {
+		newRun.Model = msg.Model
+	}
+	if a.jiraExpander != nil {
+		if key := a.jiraExpander.ExtractKey(msg.Prompt); key != "" {
+			if _, exists := a.store.Get(key); !exists {
+				newRun.ID = key
+			}
+		}
+	}
+	runID := a.store.Add(newRun)
+
+	if err := engine.CreateWorktree(a.worktrees, a.store, runID, a.config.Repos); err != nil {
+		return runID, err
+	}
+
+	a.executor.Execute(runID, msg.Workflow, msg.Prompt)
+	return runID, nil
+}
+
 func (a App) handleAccept() (tea.Model, tea.Cmd) {
 	selected := a.runList.SelectedRun()
 	if selected == nil {
@@ -997,25 +1187,48 @@ func (a App) handleAccept() (tea.Model, tea.Cmd) {
 		return a, flashClearCmd()
 	}
 
-	runID := selected.ID
+	flash, err := a.acceptRun(selected.ID)
+	if err != nil {
+		a.statusBar.SetFlashWithLevel(errorFlash(err), panels.FlashError)
+		return a, flashClearCmd()
+	}
+	if flash == "" {
+		return a, nil
+	}
+	a.statusBar.SetFlashWithLevel(flash, panels.FlashSuccess)
+	return a, flashClearCmd()
+}
+
+// acceptRun merges a finished run, either through the auto-merge pipeline or
+// a local merge, or approves a run waiting at an approve gate. It returns a
+// status message describing what was started.
+// Safe to call from goroutines (used by the control socket).
+func (a App) acceptRun(runID string) (string, error) {
+	if r, ok := a.store.Get(runID); ok && r.State == run.StateAwaitingApproval {
+		if a.executor == nil {
+			return "", fmt.Errorf("no runtime available")
+		}
+		if err := a.executor.ApproveGate(runID, r.Prompt); err != nil {
+			return "", err
+		}
+		return "Approved, continuing workflow", nil
+	}
 
 	// Block accept while the executor still has an active worker for this run.
 	if a.executor != nil && a.executor.IsActive(runID) {
-		a.statusBar.SetFlashWithLevel("Cannot accept: run is still executing", panels.FlashError)
-		return a, flashClearCmd()
+		return "", fmt.Errorf("cannot accept: run is still executing")
 	}
 
 	// Re-read state fresh from the store to avoid acting on a stale cache.
 	fresh, ok := a.store.Get(runID)
 	if !ok {
-		return a, nil
+		return "", nil
 	}
 
 	// Allow re-accept of failed merge pipelines
 	if fresh.State != run.StateCompleted && fresh.State != run.StateReviewing &&
 		!(fresh.State == run.StateFailed && fresh.MergeStatus != "") {
-		a.statusBar.SetFlashWithLevel(fmt.Sprintf("Cannot accept: run is %s", fresh.State), panels.FlashError)
-		return a, flashClearCmd()
+		return "", fmt.Errorf("cannot accept: run is %s", fresh.State)
 	}
 
 	_ = a.devServers.Stop(runID)
@@ -1035,8 +1248,7 @@ func (a App) handleAccept() (tea.Model, tea.Cmd) {
 				a.cleanupRun(runID)
 			}
 		}()
-		a.statusBar.SetFlashWithLevel("Merge pipeline started", panels.FlashSuccess)
-		return a, flashClearCmd()
+		return "Merge pipeline started", nil
 	}
 
 	// Legacy flow: merge locally, with AI conflict resolution if available
@@ -1109,8 +1321,7 @@ func (a App) handleAccept() (tea.Model, tea.Cmd) {
 		})
 	}()
 
-	a.statusBar.SetFlashWithLevel("Merging and cleaning up...", panels.FlashSuccess)
-	return a, flashClearCmd()
+	return "Merging and cleaning up...", nil
 }
 
 func (a App) handleReject() (tea.Model, tea.Cmd) {
@@ -1120,29 +1331,60 @@ func (a App) handleReject() (tea.Model, tea.Cmd) {
 		return a, flashClearCmd()
 	}
 
-	runID := selected.ID
+	if err := a.rejectRun(selected.ID); err != nil {
+		a.statusBar.SetFlashWithLevel(errorFlash(err), panels.FlashError)
+		return a, flashClearCmd()
+	}
+	return a, nil
+}
+
+// rejectRun discards a finished run, or a run waiting at an approve gate,
+// and removes its worktree in the background.
+// Safe to call from goroutines (used by the control socket).
+func (a App) rejectRun(runID string) error {
+	if r, ok := a.store.Get(runID); ok && r.State == run.StateAwaitingApproval {
+		if a.executor == nil {
+			return fmt.Errorf("no runtime available")
+		}
+		// Graph steps that do not need the gate may still be running;
+		// remove the worktree only once they have stopped.
+		done := a.executor.Done(runID)
+		if err := a.executor.RejectGate(runID); err != nil {
+			return err
+		}
+		go func() {
+			<-done
+			a.discardWorktree(runID)
+		}()
+		return nil
+	}
 
 	// Block reject while the executor still has an active worker for this run.
 	if a.executor != nil && a.executor.IsActive(runID) {
-		a.statusBar.SetFlashWithLevel("Cannot reject: run is still executing", panels.FlashError)
-		return a, flashClearCmd()
+		return fmt.Errorf("cannot reject: run is still executing")
 	}
 
 	// Re-read state fresh from the store to avoid acting on a stale cache.
 	fresh, ok := a.store.Get(runID)
 	if !ok {
-		return a, nil
+		return nil
 	}
 
 	if fresh.State != run.StateCompleted && fresh.State != run.StateReviewing {
-		a.statusBar.SetFlashWithLevel(fmt.Sprintf("Cannot reject: run is %s", fresh.State), panels.FlashError)
-		return a, flashClearCmd()
+		return fmt.Errorf("cannot reject: run is %s", fresh.State)
 	}
 
 	a.store.Update(runID, func(r *run.Run) {
 		r.State = run.StateRejected
 	})
 
+	a.discardWorktree(runID)
+	return nil
+}
+
+// discardWorktree stops a rejected run's dev server and removes its worktree
+// in the background.
+func (a App) discardWorktree(runID string) {
 	_ = a.devServers.Stop(runID)
 	worktrees := a.worktrees
 	repos := a.config.Repos
@@ -1155,8 +1397,6 @@ func (a App) handleReject() (tea.Model, tea.Cmd) {
 		}
 		store.Update(runID, func(r *run.Run) { r.Worktree = "" })
 	}()
-
-	return a, nil
 }
 
 func (a App) handleDevServerToggle() (tea.Model, tea.Cmd) {
@@ -1270,26 +1510,39 @@ func (a App) handleCancel() (tea.Model, tea.Cmd) {
 		a.statusBar.SetFlashWithLevel("No run selected", panels.FlashWarning)
 		return a, flashClearCmd()
 	}
+	if err := a.cancelRun(selected.ID); err != nil {
+		a.statusBar.SetFlashWithLevel(errorFlash(err), panels.FlashError)
+		return a, flashClearCmd()
+	}
+	return a, nil
+}
+
+// cancelRun stops a queued, running, paused or gated run and marks it failed.
+func (a App) cancelRun(runID string) error {
 	if a.manager == nil {
-		return a, nil
+		return nil
 	}
-	if selected.State != run.StateRunning && selected.State != run.StatePaused && selected.State != run.StateQueued {
-		a.statusBar.SetFlashWithLevel(fmt.Sprintf("Cannot cancel: run is %s", selected.State), panels.FlashError)
-		return a, flashClearCmd()
+	r, ok := a.store.Get(runID)
+	if !ok {
+		return fmt.Errorf("run not found: %s", runID)
+	}
+	if r.State != run.StateRunning && r.State != run.StatePaused && r.State != run.StateQueued &&
+		r.State != run.StateAwaitingApproval {
+		return fmt.Errorf("cannot cancel: run is %s", r.State)
 	}
 
 	if a.executor != nil {
-		a.executor.Cancel(selected.ID)
+		a.executor.Cancel(runID)
 	}
-	if err := a.manager.Stop(selected.ID); err != nil {
+	if err := a.manager.Stop(runID); err != nil {
 		// Process may have already exited
-		a.store.Update(selected.ID, func(r *run.Run) {
+		a.store.Update(runID, func(r *run.Run) {
 			r.State = run.StateFailed
 			r.Error = "cancelled"
 			r.CompletedAt = time.Now()
 		})
 	}
-	return a, nil
+	return nil
 }
 
 func (a App) handleDelete() (tea.Model, tea.Cmd) {
@@ -1310,14 +1563,23 @@ func (a App) handleDelete() (tea.Model, tea.Cmd) {
 }
 
 // cleanupRun removes a run and all its associated resources: buffers, dev server,
-// store entry, worktree, session file, and log files. Safe to call from goroutines.
+// store entry, worktree, session file, and log files. The run's record is
+// archived for reports. Safe to call from goroutines.
 func (a App) cleanupRun(runID string) {
-	var stdoutLog, stderrLog string
+	var stdoutLog, stderrLog, eventLog string
 	if a.manager != nil {
 		stdoutLog, stderrLog = a.manager.LogFilePaths(runID)
+		eventLog = a.manager.EventLogPath(runID)
 		a.manager.RemoveBuffer(runID)
 	}
 
+	// Removed runs are archived so `agtop report` still counts them.
+	if r, ok := a.store.Get(runID); ok && a.persistence != nil {
+		if err := a.persistence.Archive(r); err != nil {
+			log.Printf("warning: archive run %s: %v", runID, err)
+		}
+	}
+
 	_ = a.devServers.Stop(runID)
 	a.store.Remove(runID)
 
@@ -1331,7 +1593,7 @@ func (a App) cleanupRun(runID string) {
 		if a.persistence != nil {
 			_ = a.persistence.Remove(runID)
 		}
-		process.RemoveLogFiles(stdoutLog, stderrLog)
+		process.RemoveLogFiles(stdoutLog, stderrLog, eventLog)
 	}()
 }
 
@@ -1361,6 +1623,49 @@ func (a App) handleFollowUp() (tea.Model, tea.Cmd) {
 	return a, a.followUpModal.Init()
 }
 
+func (a App) handleInterject() (tea.Model, tea.Cmd) {
+	selected := a.runList.SelectedRun()
+	if selected == nil {
+		a.statusBar.SetFlashWithLevel("No run selected", panels.FlashWarning)
+		return a, flashClearCmd()
+	}
+	if selected.State != run.StateRunning {
+		a.statusBar.SetFlashWithLevel(fmt.Sprintf("Cannot interject: run is %s", selected.State), panels.FlashError)
+		return a, flashClearCmd()
+	}
+
+	a.interjectModal = panels.NewInterjectModal(selected.ID, selected.CurrentSkill, a.width, a.height)
+	return a, a.interjectModal.Init()
+}
+
+// interject sends a message into the conversation of a running run's agent.
+func (a App) interject(runID, message string) error {
+	r, ok := a.store.Get(runID)
+	if !ok {
+		return fmt.Errorf("run not found: %s", runID)
+	}
+	if r.State != run.StateRunning {
+		return fmt.Errorf("cannot interject: run is %s", r.State)
+	}
+	if a.manager == nil {
+		return fmt.Errorf("no runtime available")
+	}
+	if err := a.manager.Interject(runID, message); err != nil {
+		return fmt.Errorf("interject: %w", err)
+	}
+	return nil
+}
+
+// errorFlash capitalizes an error for display in the status bar.
+func errorFlash(err error) string {
+	msg := err.Error()
+	if msg == "" {
+		return msg
+	}
+	r, size := utf8.DecodeRuneInString(msg)
+	return string(unicode.ToUpper(r)) + msg[size:]
+}
+
 func (a *App) autoStartDevServers() {
 	if a.config.Project.DevServer.Command == "" {
 		return
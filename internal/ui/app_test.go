@@ -8,6 +8,7 @@ import (
 	"github.com/justinpbarnett/agtop/internal/config"
 	"github.com/justinpbarnett/agtop/internal/engine"
 	"github.com/justinpbarnett/agtop/internal/jira"
+	"github.com/justinpbarnett/agtop/internal/process"
 	"github.com/justinpbarnett/agtop/internal/run"
 )
 
@@ -517,3 +518,65 @@ func TestRejectWorksForCompletedRunWithoutExecutor(t *testing.T) {
 		t.Errorf("expected StateRejected, got %s", r.State)
 	}
 }
+
+type countingStepper struct{ steps int }
+
+func (s *countingStepper) Step() { s.steps++ }
+
+func TestReplayAppIsReadOnly(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := run.NewStore()
+	store.Add(&run.Run{State: run.StateRunning, Prompt: "replayed"})
+	stepper := &countingStepper{}
+	mgr := process.NewManager(store, nil, "claude", "", &cfg.Limits, nil, nil, nil)
+	a := NewReplayApp(&cfg, store, mgr, stepper)
+	a = sendWindowSize(a, 200, 40)
+
+	a = sendKey(a, "j")
+	if strings.Contains(a.statusBar.View(), "Replay is read-only") {
+		t.Error("navigation keys should work in a replay")
+	}
+	// Keys outside the navigation allow-list are refused.
+	a = sendKey(a, "z")
+	if !strings.Contains(a.statusBar.View(), "Replay is read-only") {
+		t.Error("expected other keys to be refused in a replay")
+	}
+
+	a = sendKey(a, "n")
+	if a.newRunModal != nil {
+		t.Error("replay should not open the new run modal")
+	}
+	a = sendKey(a, ".")
+	if stepper.steps != 1 {
+		t.Errorf("expected one step, got %d", stepper.steps)
+	}
+}
+
+func TestInterjectOpensModalOnlyForRunningRuns(t *testing.T) {
+	a := newTestApp(t)
+	a = sendWindowSize(a, 120, 40)
+
+	id := a.store.Add(&run.Run{State: run.StateCompleted, Prompt: "test"})
+	m, _ := a.Update(RunStoreUpdatedMsg{})
+	a = m.(App)
+
+	a = sendKey(a, "i")
+	if a.interjectModal != nil {
+		t.Fatal("expected no interject modal for a completed run")
+	}
+
+	a.store.Update(id, func(r *run.Run) { r.State = run.StateRunning })
+	m, _ = a.Update(RunStoreUpdatedMsg{})
+	a = m.(App)
+
+	a = sendKey(a, "i")
+	if a.interjectModal == nil {
+		t.Fatal("expected interject modal for a running run")
+	}
+	a = sendSpecialKey(a, tea.KeyEsc)
+	m, _ = a.Update(CloseModalMsg{})
+	a = m.(App)
+	if a.interjectModal != nil {
+		t.Error("expected interject modal to close")
+	}
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+// controlHandler adapts the App's run actions to control.Handler so socket
+// clients go through the same checks as the key bindings. It holds a copy of
+// the App taken at startup; only the shared pointer fields are used.
+type controlHandler struct {
+	app App
+}
+
+func (h controlHandler) Execute(prompt, workflow, model string) (string, error) {
+	if h.app.executor == nil {
+		return "", fmt.Errorf("no runtime available")
+	}
+	return h.app.startRun(StartRunMsg{Prompt: prompt, Workflow: workflow, Model: model})
+}
+
+func (h controlHandler) Cancel(runID string) error {
+	return h.app.cancelRun(runID)
+}
+
+// Resume continues a paused run. A run paused mid-skill (its executor worker
+// is still waiting) gets its process resumed; otherwise the workflow restarts
+// from the last incomplete skill.
+func (h controlHandler) Resume(runID string) error {
+	r, ok := h.app.store.Get(runID)
+	if !ok {
+		return fmt.Errorf("run not found: %s", runID)
+	}
+	if r.State != run.StatePaused {
+		return fmt.Errorf("Cannot resume: run is %s", r.State)
+	}
+	if h.app.executor == nil || h.app.manager == nil {
+		return fmt.Errorf("no runtime available")
+	}
+	if h.app.executor.IsActive(runID) {
+		return h.app.manager.Resume(runID)
+	}
+	return h.app.executor.Resume(runID, r.Prompt)
+}
+
+func (h controlHandler) FollowUp(runID, prompt string) error {
+	if h.app.executor == nil {
+		return fmt.Errorf("no runtime available")
+	}
+	return h.app.executor.FollowUp(runID, prompt)
+}
+
+func (h controlHandler) Interject(runID, message string) error {
+	return h.app.interject(runID, message)
+}
+
+func (h controlHandler) Accept(runID string) error {
+	_, err := h.app.acceptRun(runID)
+	return err
+}
+
+func (h controlHandler) Reject(runID string) error {
+	return h.app.rejectRun(runID)
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/justinpbarnett/agtop/internal/run"
+)
+
+func TestControlHandlerAcceptCompletedRun(t *testing.T) {
+	a := newTestApp(t)
+	h := controlHandler{app: a}
+
+	id := a.store.Add(&run.Run{State: run.StateCompleted, Prompt: "done"})
+	if err := h.Accept(id); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	r, _ := a.store.Get(id)
+	if r.State != run.StateAccepted {
+		t.Errorf("expected StateAccepted, got %s", r.State)
+	}
+}
+
+func TestControlHandlerRejectRunningRun(t *testing.T) {
+	a := newTestApp(t)
+	h := controlHandler{app: a}
+
+	id := a.store.Add(&run.Run{State: run.StateRunning, Prompt: "busy"})
+	if err := h.Reject(id); err == nil {
+		t.Fatal("expected error rejecting a running run")
+	}
+	r, _ := a.store.Get(id)
+	if r.State != run.StateRunning {
+		t.Errorf("expected state to remain StateRunning, got %s", r.State)
+	}
+}
+
+func TestControlHandlerCancelTerminalRun(t *testing.T) {
+	a := newTestApp(t)
+	if a.manager == nil {
+		t.Skip("no runtime available")
+	}
+	h := controlHandler{app: a}
+
+	id := a.store.Add(&run.Run{State: run.StateCompleted, Prompt: "done"})
+	if err := h.Cancel(id); err == nil {
+		t.Fatal("expected error cancelling a completed run")
+	}
+}
+
+func TestControlHandlerResumeRequiresPaused(t *testing.T) {
+	a := newTestApp(t)
+	h := controlHandler{app: a}
+
+	id := a.store.Add(&run.Run{State: run.StateCompleted, Prompt: "done"})
+	if err := h.Resume(id); err == nil {
+		t.Fatal("expected error resuming a completed run")
+	}
+	if err := h.Resume("missing"); err == nil {
+		t.Fatal("expected error resuming an unknown run")
+	}
+}
+
+func TestControlHandlerExecuteWithoutExecutor(t *testing.T) {
+	a := newTestApp(t)
+	a.executor = nil
+	h := controlHandler{app: a}
+
+	if _, err := h.Execute("prompt", "build", ""); err == nil {
+		t.Fatal("expected error when no executor is available")
+	}
+}
+
+func TestControlHandlerRejectGatedRun(t *testing.T) {
+	a := newTestApp(t)
+	if a.executor == nil {
+		t.Skip("no runtime available")
+	}
+	h := controlHandler{app: a}
+
+	id := a.store.Add(&run.Run{State: run.StateAwaitingApproval, Prompt: "gated"})
+	if err := h.Reject(id); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	r, _ := a.store.Get(id)
+	if r.State != run.StateRejected {
+		t.Errorf("expected StateRejected, got %s", r.State)
+	}
+}
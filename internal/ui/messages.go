@@ -19,6 +19,9 @@ type ClearFlashMsg = panels.ClearFlashMsg
 // DiffResultMsg delivers async diff results for a run.
 type DiffResultMsg = panels.DiffResultMsg
 
+// SpecResultMsg delivers the spec file of a run waiting at an approve gate.
+type SpecResultMsg = panels.SpecResultMsg
+
 // SubmitNewRunMsg is sent when the user confirms the new run modal.
 type SubmitNewRunMsg = panels.SubmitNewRunMsg
 
@@ -31,6 +34,9 @@ type InitAcceptedMsg = panels.InitAcceptedMsg
 // SubmitFollowUpMsg is sent when the user confirms the follow-up modal.
 type SubmitFollowUpMsg = panels.SubmitFollowUpMsg
 
+// SubmitInterjectMsg is sent when the user confirms the interject modal.
+type SubmitInterjectMsg = panels.SubmitInterjectMsg
+
 // UpdateAvailableMsg is sent when a newer version is available.
 type UpdateAvailableMsg = panels.UpdateAvailableMsg
 
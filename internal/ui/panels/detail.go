@@ -9,6 +9,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/justinpbarnett/agtop/internal/cost"
 	"github.com/justinpbarnett/agtop/internal/run"
 	"github.com/justinpbarnett/agtop/internal/ui/border"
 	"github.com/justinpbarnett/agtop/internal/ui/styles"
@@ -22,8 +23,14 @@ type Detail struct {
 	focused     bool
 	viewport    viewport.Model
 	gTap        DoubleTap
+	specRunID   string
+	spec        string
+	forecast    ForecastFunc
 }
 
+// ForecastFunc estimates the remaining cost of an unfinished run.
+type ForecastFunc func(r *run.Run) (cost.Forecast, bool)
+
 func NewDetail() Detail {
 	return Detail{
 		viewport: viewport.New(0, 0),
@@ -110,6 +117,46 @@ func (d *Detail) SetRun(r *run.Run) {
 	}
 }
 
+// SetSpec stores the spec of a run waiting at an approve gate. It is shown
+// below the run's fields while that run is selected and still gated.
+func (d *Detail) SetSpec(runID, content string) {
+	d.specRunID = runID
+	d.spec = strings.TrimRight(content, "\n")
+	if d.selectedRun != nil && d.selectedRun.ID == runID {
+		d.viewport.SetContent(d.renderDetails())
+	}
+}
+
+// gateSpec returns the spec to review for the selected run, if it is waiting
+// at an approve gate.
+func (d Detail) gateSpec() string {
+	r := d.selectedRun
+	if r == nil || r.State != run.StateAwaitingApproval || r.ID != d.specRunID {
+		return ""
+	}
+	return d.spec
+}
+
+// SetForecaster sets how the remaining cost of unfinished runs is
+// estimated. Without one, no forecast is shown.
+func (d *Detail) SetForecaster(fn ForecastFunc) {
+	d.forecast = fn
+}
+
+// forecastText returns the selected run's cost forecast, or "" when it has
+// finished or there is no history to estimate from.
+func (d Detail) forecastText() string {
+	r := d.selectedRun
+	if d.forecast == nil || r == nil || r.IsTerminal() {
+		return ""
+	}
+	f, ok := d.forecast(r)
+	if !ok {
+		return ""
+	}
+	return f.Remaining()
+}
+
 func (d *Detail) SetSize(w, h int) {
 	d.width = w
 	d.height = h
@@ -172,6 +219,9 @@ func (d Detail) plainText() string {
 		stepText = fmt.Sprintf("%s (%d/%d)", skillName, r.SkillIndex, r.SkillTotal)
 	}
 	row("Step", stepText)
+	for _, n := range r.Nodes {
+		row("Steps", fmt.Sprintf("%s %s (%s)", n.Icon(), n.Name, n.State))
+	}
 	row("Branch", r.Branch)
 
 	model := r.Model
@@ -183,6 +233,12 @@ func (d Detail) plainText() string {
 	if r.Cost > 0 {
 		row("Cost", text.FormatCost(r.Cost))
 	}
+	if forecast := d.forecastText(); forecast != "" {
+		row("Forecast", forecast)
+	}
+	if cache := cacheSummary(r); cache != "" {
+		row("Cache", cache)
+	}
 	if r.Worktree != "" {
 		row("Worktree", shortenPath(r.Worktree))
 	}
@@ -201,6 +257,9 @@ func (d Detail) plainText() string {
 	if r.Error != "" {
 		row("Error", r.Error)
 	}
+	if spec := d.gateSpec(); spec != "" {
+		fmt.Fprintf(&b, "\nSpec (%s):\n%s\n", r.SpecFile, spec)
+	}
 
 	return strings.TrimRight(b.String(), "\n")
 }
@@ -290,6 +349,25 @@ func (d Detail) renderDetails() string {
 	}
 	fmt.Fprintf(&b, "  %s\n", row("Step", stepText))
 
+	for i, n := range r.Nodes {
+		key := fmt.Sprintf("%-9s: ", "Steps")
+		if i > 0 {
+			key = strings.Repeat(" ", 11)
+		}
+		nodeStyle := lipgloss.NewStyle().Foreground(styles.NodeStateColor(n.State))
+		line := nodeStyle.Render(n.Icon()) + " " + valStyle.Render(n.Name)
+		if n.Skill != "" && n.Skill != n.Name {
+			line += keyStyle.Render(" (" + n.Skill + ")")
+		}
+		if len(n.Needs) > 0 {
+			line += keyStyle.Render(" ← " + strings.Join(n.Needs, ", "))
+		}
+		if n.Loop != "" {
+			line += keyStyle.Render(fmt.Sprintf(" ↺ %s %d/%d", n.Loop, n.Loops, n.MaxLoops))
+		}
+		fmt.Fprintf(&b, "  %s%s\n", keyStyle.Render(key), line)
+	}
+
 	fmt.Fprintf(&b, "  %s\n", row("Branch", r.Branch))
 
 	model := r.Model
@@ -303,6 +381,14 @@ func (d Detail) renderDetails() string {
 		fmt.Fprintf(&b, "  %s\n", styledRow("Cost", text.FormatCost(r.Cost), costStyle))
 	}
 
+	if forecast := d.forecastText(); forecast != "" {
+		fmt.Fprintf(&b, "  %s\n", row("Forecast", forecast))
+	}
+
+	if cache := cacheSummary(r); cache != "" {
+		fmt.Fprintf(&b, "  %s\n", row("Cache", cache))
+	}
+
 	if r.Worktree != "" {
 		fmt.Fprintf(&b, "  %s\n", row("Worktree", shortenPath(r.Worktree)))
 	}
@@ -335,6 +421,14 @@ func (d Detail) renderDetails() string {
 		fmt.Fprintf(&b, "  %s\n", styledRow("Error", r.Error, errorStyle))
 	}
 
+	if spec := d.gateSpec(); spec != "" {
+		header := lipgloss.NewStyle().Foreground(styles.RunStateColor(r.State)).Bold(true)
+		fmt.Fprintf(&b, "\n  %s %s\n", header.Render("Spec"), keyStyle.Render("— a: approve  x: reject"))
+		for _, line := range strings.Split(spec, "\n") {
+			fmt.Fprintf(&b, "  %s\n", valStyle.Render(line))
+		}
+	}
+
 	return b.String()
 }
 
@@ -355,3 +449,13 @@ func shortenPath(path string) string {
 	}
 	return path
 }
+
+// cacheSummary describes a run's prompt cache use, or returns "" when the
+// runtime reported none.
+func cacheSummary(r *run.Run) string {
+	if r.CacheRead+r.CacheWrite == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s hit · %s read · %s written",
+		text.FormatPercent(r.CacheHitRate()*100), text.FormatTokens(r.CacheRead), text.FormatTokens(r.CacheWrite))
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/justinpbarnett/agtop/internal/cost"
 	"github.com/justinpbarnett/agtop/internal/run"
 )
 
@@ -155,6 +156,46 @@ func TestDetailPRURL(t *testing.T) {
 	}
 }
 
+func TestDetailCacheHitRate(t *testing.T) {
+	d := NewDetail()
+	d.SetSize(80, 20)
+
+	r := &run.Run{
+		ID:         "014",
+		Branch:     "feat/cache",
+		State:      run.StateRunning,
+		TokensIn:   100_000,
+		CacheRead:  1_200_000,
+		CacheWrite: 40_000,
+	}
+	d.SetRun(r)
+	view := d.View()
+
+	if !strings.Contains(view, "90% hit · 1.2M read · 40.0k written") {
+		t.Errorf("expected cache row, got:\n%s", view)
+	}
+}
+
+func TestDetailForecast(t *testing.T) {
+	d := NewDetail()
+	d.SetSize(80, 20)
+	d.SetForecaster(func(r *run.Run) (cost.Forecast, bool) {
+		return cost.Forecast{P50: 3.2, P90: 7.8, Steps: 2}, true
+	})
+
+	r := &run.Run{ID: "015", Branch: "feat/forecast", State: run.StateRunning, Cost: 1.1}
+	d.SetRun(r)
+	if view := d.View(); !strings.Contains(view, "≈ $3.20 more (p50), $7.80 (p90)") {
+		t.Errorf("expected forecast row, got:\n%s", view)
+	}
+
+	r.State = run.StateCompleted
+	d.SetRun(r)
+	if view := d.View(); strings.Contains(view, "Forecast") {
+		t.Errorf("expected no forecast for a finished run, got:\n%s", view)
+	}
+}
+
 func TestDetailNoMergeStatusWhenEmpty(t *testing.T) {
 	d := NewDetail()
 	d.SetSize(80, 15)
@@ -283,3 +324,65 @@ func TestDetailScrollResetOnRunChange(t *testing.T) {
 		t.Errorf("expected scroll to reset to 0 on run change, got %d", d.viewport.YOffset)
 	}
 }
+
+func TestDetailGraphNodes(t *testing.T) {
+	d := NewDetail()
+	d.SetSize(80, 20)
+
+	r := &run.Run{
+		ID:       "043",
+		Workflow: "parallel",
+		State:    run.StateRunning,
+		Nodes: []run.NodeStatus{
+			{Name: "spec", Skill: "spec", State: run.NodeCompleted},
+			{Name: "lint", Skill: "test", Needs: []string{"spec"}, State: run.NodeRunning},
+			{Name: "review", Skill: "review", Needs: []string{"spec", "lint"}, State: run.NodePending},
+			{Name: "fix", Needs: []string{"review"}, Loop: "lint", Loops: 1, MaxLoops: 3, State: run.NodePending},
+		},
+	}
+	d.SetRun(r)
+
+	view := d.View()
+	if !strings.Contains(view, "✓ spec") {
+		t.Error("expected completed spec node")
+	}
+	if !strings.Contains(view, "● lint (test) ← spec") {
+		t.Error("expected running lint node with skill and needs")
+	}
+	if !strings.Contains(view, "◌ review ← spec, lint") {
+		t.Error("expected pending review node with needs")
+	}
+	if !strings.Contains(view, "◌ fix ← review ↺ lint 1/3") {
+		t.Error("expected loop node with loop count")
+	}
+}
+
+func TestDetailShowsSpecAtApprovalGate(t *testing.T) {
+	d := NewDetail()
+	d.SetSize(80, 30)
+
+	r := &run.Run{
+		ID:       "044",
+		Workflow: "gated",
+		State:    run.StateAwaitingApproval,
+		SpecFile: "specs/feature.md",
+	}
+	d.SetRun(r)
+	d.SetSpec("044", "# Feature\nAdd the thing\n")
+
+	view := d.View()
+	if !strings.Contains(view, "Add the thing") {
+		t.Error("expected spec content while awaiting approval")
+	}
+	if !strings.Contains(d.plainText(), "Spec (specs/feature.md)") {
+		t.Error("expected spec in yanked text")
+	}
+
+	// Once the gate is passed the spec is no longer shown.
+	approved := *r
+	approved.State = run.StateRunning
+	d.SetRun(&approved)
+	if strings.Contains(d.View(), "Add the thing") {
+		t.Error("spec should be hidden after approval")
+	}
+}
@@ -17,7 +17,7 @@ type HelpOverlay struct {
 func NewHelpOverlay() *HelpOverlay {
 	return &HelpOverlay{
 		width:  44,
-		height: 26,
+		height: 27,
 	}
 }
 
@@ -57,9 +57,10 @@ func (h HelpOverlay) View() string {
 	b.WriteString(kv("r", "Restart") + "\n")
 	b.WriteString(kv("c", "Cancel") + "\n")
 	b.WriteString(kv("d", "Delete run") + "\n")
-	b.WriteString(kv("a", "Accept") + "\n")
+	b.WriteString(kv("a", "Accept / Approve gate") + "\n")
 	b.WriteString(kv("x", "Reject") + "\n")
 	b.WriteString(kv("u", "Follow up") + "\n")
+	b.WriteString(kv("i", "Interject message") + "\n")
 	b.WriteString(kv("D", "Dev server toggle") + "\n")
 	b.WriteString("\n")
 	b.WriteString(sectionStyle.Render("Global") + "\n")
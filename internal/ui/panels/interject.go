@@ -0,0 +1,111 @@
+package panels
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/justinpbarnett/agtop/internal/ui/border"
+	"github.com/justinpbarnett/agtop/internal/ui/styles"
+)
+
+// SubmitInterjectMsg is sent when the user confirms the interject modal.
+type SubmitInterjectMsg struct {
+	RunID   string
+	Message string
+}
+
+// InterjectModal collects a message to send into the conversation of a
+// running agent.
+type InterjectModal struct {
+	runID          string
+	skill          string
+	messageInput   textarea.Model
+	width          int
+	height         int
+	textareaHeight int
+}
+
+func NewInterjectModal(runID, skill string, screenW, screenH int) *InterjectModal {
+	ta := textarea.New()
+	ta.Placeholder = "Message to the running agent..."
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.Focus()
+
+	m := &InterjectModal{
+		runID:        runID,
+		skill:        skill,
+		messageInput: ta,
+	}
+	m.SetSize(screenW, screenH)
+	return m
+}
+
+func (m *InterjectModal) SetSize(screenW, screenH int) {
+	m.width = screenW * 60 / 100
+	m.height = screenH * 40 / 100
+	if m.width < 40 {
+		m.width = 40
+	}
+	if m.height < 10 {
+		m.height = 10
+	}
+
+	innerW := m.width - 2
+	// inner height = total - 2 (borders) - 3 (blank line + context line + blank)
+	m.textareaHeight = m.height - 5
+	if m.textareaHeight < 3 {
+		m.textareaHeight = 3
+	}
+	m.messageInput.SetWidth(innerW)
+	m.messageInput.SetHeight(m.textareaHeight)
+}
+
+func (m *InterjectModal) Init() tea.Cmd {
+	return m.messageInput.Focus()
+}
+
+func (m *InterjectModal) Update(msg tea.Msg) (*InterjectModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return nil, func() tea.Msg { return CloseModalMsg{} }
+		case "ctrl+s":
+			text := strings.TrimSpace(m.messageInput.Value())
+			if text == "" {
+				return m, nil
+			}
+			rid := m.runID
+			return nil, func() tea.Msg {
+				return SubmitInterjectMsg{RunID: rid, Message: text}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.messageInput, cmd = m.messageInput.Update(msg)
+	return m, cmd
+}
+
+func (m *InterjectModal) View() string {
+	var b strings.Builder
+
+	// Context line showing which live session receives the message
+	b.WriteString(styles.TextSecondaryStyle.Render("Run "))
+	b.WriteString(styles.TextDimStyle.Render(m.runID))
+	if m.skill != "" {
+		b.WriteString(styles.TextSecondaryStyle.Render(" | "))
+		b.WriteString(styles.TextDimStyle.Render(m.skill))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(m.messageInput.View())
+
+	bottomKb := []border.Keybind{
+		{Key: "^S", Label: " send"},
+		{Key: "Esc", Label: " cancel"},
+	}
+	return border.RenderPanel("Interject", b.String(), bottomKb, m.width, m.height, true)
+}
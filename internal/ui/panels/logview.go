@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -20,8 +21,9 @@ var ellipsisFrames = []string{".  ", ".. ", "..."}
 
 // Log view tab indices.
 const (
-	tabLog  = 0
-	tabDiff = 1
+	tabLog      = 0
+	tabDiff     = 1
+	tabTimeline = 2
 )
 
 // logLineRe matches log lines like "[14:32:01 route] message"
@@ -48,8 +50,10 @@ type LogView struct {
 	lastEvicted     int
 
 	// Tab state
-	activeTab int
-	diffView  DiffView
+	activeTab  int
+	diffView   DiffView
+	timeline   *process.Timeline
+	timelineVP viewport.Model
 
 	// Search state
 	searching    bool
@@ -86,6 +90,7 @@ func NewLogView() LogView {
 		searchInput: ti,
 		scrollSpeed: 3,
 		diffView:    NewDiffView(),
+		timelineVP:  viewport.New(0, 0),
 		gTap:        NewDoubleTap(gTapIDLogView),
 	}
 }
@@ -114,6 +119,9 @@ func (l LogView) Update(msg tea.Msg) (LogView, tea.Cmd) {
 			if l.searchQuery != "" {
 				l.recomputeMatches()
 			}
+			if l.activeTab == tabTimeline {
+				l.refreshTimeline()
+			}
 			return l, nil
 		}
 	case GTimerExpiredMsg:
@@ -129,8 +137,34 @@ func (l LogView) Update(msg tea.Msg) (LogView, tea.Cmd) {
 		return l, nil
 	case AnimTickMsg:
 		l.tickStep++
+		// Keep the bars of running tool calls growing
+		if l.activeTab == tabTimeline && l.active {
+			l.refreshTimeline()
+		}
 		return l, nil
 	case tea.KeyMsg:
+		if l.activeTab == tabTimeline {
+			switch msg.String() {
+			case "h", "left":
+				l.activeTab = tabDiff
+				l.updateDiffFocus()
+				return l, nil
+			case "l", "right":
+				return l, nil
+			case "enter":
+				return l, func() tea.Msg { return FullscreenMsg{Panel: 1} }
+			case "G":
+				l.timelineVP.GotoBottom()
+				return l, nil
+			case "g":
+				l.timelineVP.GotoTop()
+				return l, nil
+			}
+			var cmd tea.Cmd
+			l.timelineVP, cmd = l.timelineVP.Update(msg)
+			return l, cmd
+		}
+
 		// On diff tab, delegate keys to diffView
 		if l.activeTab == tabDiff {
 			switch msg.String() {
@@ -138,6 +172,11 @@ func (l LogView) Update(msg tea.Msg) (LogView, tea.Cmd) {
 				l.activeTab = tabLog
 				l.updateDiffFocus()
 				return l, nil
+			case "l", "right":
+				l.activeTab = tabTimeline
+				l.updateDiffFocus()
+				l.refreshTimeline()
+				return l, nil
 			case "enter":
 				return l, func() tea.Msg { return FullscreenMsg{Panel: 1} }
 			}
@@ -350,17 +389,18 @@ func (l LogView) View() string {
 		}
 		logLabel = fmt.Sprintf("Log: %s", strings.Join(parts, " — "))
 	}
-	diffLabel := "Diff"
+	labels := []string{logLabel, "Diff", "Timeline"}
 
-	var title string
-	if l.activeTab == tabLog {
-		title = "[3] " + styles.TitleStyle.Render(logLabel) +
-			styles.TextDimStyle.Render(" │ ") +
-			styles.TextDimStyle.Render(diffLabel)
-	} else {
-		title = "[3] " + styles.TextDimStyle.Render(logLabel) +
-			styles.TextDimStyle.Render(" │ ") +
-			styles.TitleStyle.Render(diffLabel)
+	title := "[3] "
+	for i, label := range labels {
+		if i > 0 {
+			title += styles.TextDimStyle.Render(" │ ")
+		}
+		if i == l.activeTab {
+			title += styles.TitleStyle.Render(label)
+		} else {
+			title += styles.TextDimStyle.Render(label)
+		}
 	}
 
 	var keybinds []border.Keybind
@@ -416,6 +456,15 @@ func (l LogView) View() string {
 			dots := ellipsisFrames[l.tickStep%len(ellipsisFrames)]
 			content += "\n" + styles.TextDimStyle.Render("  "+dots)
 		}
+	} else if l.activeTab == tabTimeline {
+		content = l.timelineVP.View()
+		if l.focused {
+			keybinds = []border.Keybind{
+				{Key: "⏎", Label: " fullscreen"},
+				{Key: "G", Label: "bottom"},
+				{Key: "g", Label: " top"},
+			}
+		}
 	} else {
 		content = l.diffView.Content()
 		if l.focused {
@@ -441,6 +490,9 @@ func (l *LogView) SetSize(w, h int) {
 		innerH = 0
 	}
 	l.diffView.SetSize(innerW, innerH)
+	l.timelineVP.Width = innerW
+	l.timelineVP.Height = innerH
+	l.refreshTimeline()
 }
 
 func (l *LogView) SetFocused(focused bool) {
@@ -461,6 +513,9 @@ func (l LogView) ConsumesKeys() bool {
 	if l.activeTab == tabDiff {
 		return l.diffView.ConsumesKeys()
 	}
+	if l.activeTab == tabTimeline {
+		return false
+	}
 	return l.searching || l.searchQuery != "" || l.sel.Active()
 }
 
@@ -503,6 +558,19 @@ func (l *LogView) UpdateRunMeta(skill, branch string, buf *process.RingBuffer, e
 	l.refreshContent()
 }
 
+// SetTimeline sets the tool-call timeline shown on the Timeline tab.
+func (l *LogView) SetTimeline(tl *process.Timeline) {
+	l.timeline = tl
+	if l.activeTab == tabTimeline {
+		l.refreshTimeline()
+	}
+}
+
+// refreshTimeline re-renders the Timeline tab, keeping its scroll position.
+func (l *LogView) refreshTimeline() {
+	l.timelineVP.SetContent(renderTimeline(l.timeline, l.timelineVP.Width, time.Now()))
+}
+
 // Diff proxy methods — called by the app to pass diff data into the embedded DiffView.
 
 func (l *LogView) SetDiff(diff, stat string) { l.diffView.SetDiff(diff, stat) }
@@ -606,6 +674,10 @@ func (l *LogView) renderEntries() string {
 		if e.Skill != "" {
 			prefix += skillStyle.Render(e.Skill) + " "
 		}
+		// Sub-agent events hang under the Task call that started them
+		for d := 0; d < e.Depth; d++ {
+			prefix += tsStyle.Render("│") + " "
+		}
 
 		// Collapse/expand indicator — only shown when the entry has expandable detail
 		hasDetail := e.Detail != "" && e.Detail != e.Summary
@@ -878,6 +950,9 @@ func (l *LogView) updateCopyMode(msg tea.KeyMsg) (LogView, tea.Cmd) {
 
 // StartMouseSelection begins a mouse drag selection at the given panel-relative coordinates.
 func (l *LogView) StartMouseSelection(relX, relY int) {
+	if l.activeTab == tabTimeline {
+		return
+	}
 	if l.activeTab == tabDiff {
 		l.diffView.StartMouseSelection(relX, relY)
 		return
@@ -889,6 +964,9 @@ func (l *LogView) StartMouseSelection(relX, relY int) {
 
 // ExtendMouseSelection updates the cursor position during a mouse drag.
 func (l *LogView) ExtendMouseSelection(relX, relY int) {
+	if l.activeTab == tabTimeline {
+		return
+	}
 	if l.activeTab == tabDiff {
 		l.diffView.ExtendMouseSelection(relX, relY)
 		return
@@ -903,6 +981,9 @@ func (l *LogView) ExtendMouseSelection(relX, relY int) {
 // FinalizeMouseSelection ends the mouse drag and returns the selected text.
 // Returns empty string for single-click (no drag).
 func (l *LogView) FinalizeMouseSelection(relX, relY int) string {
+	if l.activeTab == tabTimeline {
+		return ""
+	}
 	if l.activeTab == tabDiff {
 		return l.diffView.FinalizeMouseSelection(relX, relY)
 	}
@@ -922,6 +1003,9 @@ func (l *LogView) FinalizeMouseSelection(relX, relY int) string {
 
 // CancelMouseSelection clears mouse selection state without copying.
 func (l *LogView) CancelMouseSelection() {
+	if l.activeTab == tabTimeline {
+		return
+	}
 	if l.activeTab == tabDiff {
 		l.diffView.CancelMouseSelection()
 		return
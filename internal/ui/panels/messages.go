@@ -22,6 +22,13 @@ type DiffResultMsg struct {
 	Err      error
 }
 
+// SpecResultMsg delivers the spec file of a run waiting at an approve gate.
+type SpecResultMsg struct {
+	RunID   string
+	Content string
+	Err     error
+}
+
 // SubmitNewRunMsg is sent when the user confirms the new run modal.
 type SubmitNewRunMsg struct {
 	Prompt   string
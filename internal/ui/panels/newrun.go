@@ -7,6 +7,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/justinpbarnett/agtop/internal/ui/border"
 	cliputil "github.com/justinpbarnett/agtop/internal/ui/clipboard"
@@ -75,6 +76,11 @@ var models = []modelOption{
 	{name: "sonnet", model: "sonnet"},
 }
 
+// NewRunForecastFunc returns the forecast cost of a new run of workflow,
+// and a warning when it reaches the per-run cost threshold. Both are empty
+// when there is no forecast.
+type NewRunForecastFunc func(workflow string) (forecast, warning string)
+
 type NewRunModal struct {
 	promptInput    textarea.Model
 	workflow       string
@@ -85,6 +91,7 @@ type NewRunModal struct {
 	screenH        int
 	textareaHeight int
 	attachedImages []string // paths to temp image files
+	forecast       NewRunForecastFunc
 
 	// Mouse selection state
 	mouseSelecting   bool
@@ -132,6 +139,12 @@ func (m *NewRunModal) SetSize(screenW, screenH int) {
 	m.promptInput.SetHeight(m.textareaHeight)
 }
 
+// SetForecaster makes the modal show the forecast cost of the selected
+// workflow.
+func (m *NewRunModal) SetForecaster(fn NewRunForecastFunc) {
+	m.forecast = fn
+}
+
 func (m *NewRunModal) Init() tea.Cmd {
 	return m.promptInput.Focus()
 }
@@ -297,15 +310,29 @@ func (m *NewRunModal) View() string {
 	b.WriteString(taView)
 	b.WriteString("\n")
 
-	// Image indicator line (occupies the blank line between textarea and workflow)
+	// Image indicator and forecast line (occupies the blank line between
+	// textarea and workflow)
+	var info strings.Builder
 	if n := len(m.attachedImages); n > 0 {
 		noun := "image"
 		if n > 1 {
 			noun = "images"
 		}
-		b.WriteString(styles.TextSecondaryStyle.Render("Images    "))
-		b.WriteString(styles.SelectedOptionStyle.Render(fmt.Sprintf("%d %s attached", n, noun)))
+		info.WriteString(styles.TextSecondaryStyle.Render("Images    "))
+		info.WriteString(styles.SelectedOptionStyle.Render(fmt.Sprintf("%d %s attached", n, noun)))
+		info.WriteString("  ")
+	}
+	if m.forecast != nil {
+		if forecast, warning := m.forecast(m.workflow); forecast != "" {
+			info.WriteString(styles.TextSecondaryStyle.Render("Forecast "))
+			info.WriteString(keyStyle.Render(forecast))
+			if warning != "" {
+				info.WriteString("  ")
+				info.WriteString(lipgloss.NewStyle().Foreground(styles.StatusWarning).Render("⚠ " + warning))
+			}
+		}
 	}
+	b.WriteString(ansi.Truncate(info.String(), m.width-2, "…"))
 	b.WriteString("\n")
 
 	// Workflow row
@@ -199,6 +199,27 @@ func TestNewRunModalView(t *testing.T) {
 	}
 }
 
+func TestNewRunModalForecast(t *testing.T) {
+	m := NewNewRunModal(160, 40)
+	m.SetForecaster(func(workflow string) (string, string) {
+		if workflow != "build" {
+			return "", ""
+		}
+		return "≈ $3.20 (p50), $7.80 (p90)", "forecast cost may exceed threshold"
+	})
+
+	if containsPlain(m.View(), "Forecast") {
+		t.Error("expected no forecast for auto")
+	}
+	m, _ = m.Update(newRunKeyMsg("alt+w"))
+	view := m.View()
+	for _, s := range []string{"Forecast", "$7.80 (p90)", "may exceed threshold"} {
+		if !containsPlain(view, s) {
+			t.Errorf("view missing %q", s)
+		}
+	}
+}
+
 // containsPlain checks if s contains sub, ignoring ANSI escape sequences.
 func containsPlain(s, sub string) bool {
 	// Strip ANSI for a simple check
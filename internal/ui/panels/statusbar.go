@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/justinpbarnett/agtop/internal/cost"
 	"github.com/justinpbarnett/agtop/internal/run"
 	"github.com/justinpbarnett/agtop/internal/ui/styles"
 	"github.com/justinpbarnett/agtop/internal/ui/text"
@@ -34,6 +35,8 @@ const (
 type StatusBar struct {
 	width      int
 	store      *run.Store
+	ledger     *cost.Ledger
+	budget     cost.Budget
 	flash      string
 	flashLevel FlashLevel
 	flashUntil time.Time
@@ -52,7 +55,7 @@ func (s StatusBar) View() string {
 		switch r.State {
 		case run.StateRunning, run.StateRouting:
 			running++
-		case run.StateQueued, run.StatePaused:
+		case run.StateQueued, run.StatePaused, run.StateAwaitingApproval:
 			queued++
 		case run.StateCompleted, run.StateAccepted, run.StateFailed, run.StateRejected:
 			done++
@@ -89,6 +92,9 @@ func (s StatusBar) View() string {
 	helpHint := styles.TextSecondaryStyle.Render("?:help")
 
 	left := " " + version + sep + counts + sep + tokensStr + sep + costStr
+	if budgetStr := s.budgetGauge(); budgetStr != "" {
+		left += sep + budgetStr
+	}
 
 	right := helpHint + " "
 	rightWidth := lipgloss.Width(right)
@@ -126,6 +132,44 @@ func (s StatusBar) View() string {
 	return text.Truncate(left+strings.Repeat(" ", gap)+right, s.width)
 }
 
+var budgetPeriods = map[string]string{
+	"daily":   "today",
+	"weekly":  "this week",
+	"monthly": "this month",
+}
+
+// budgetGauge renders what is left of the budget cap closest to being
+// reached, or "" when no budget is set.
+func (s StatusBar) budgetGauge() string {
+	if s.ledger == nil || !s.budget.Enabled() {
+		return ""
+	}
+	spend, err := s.ledger.Spend(time.Now())
+	if err != nil {
+		return ""
+	}
+	st, ok := s.budget.Tightest(spend)
+	if !ok {
+		return ""
+	}
+	color := styles.StatusSuccess
+	switch {
+	case st.Spent >= st.Limit:
+		color = styles.StatusError
+	case st.Ratio() >= cost.BudgetWarnRatio:
+		color = styles.StatusWarning
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(
+		fmt.Sprintf("Budget: %s left %s", text.FormatCost(st.Remaining()), budgetPeriods[st.Period]),
+	)
+}
+
+// SetBudget shows the spend of ledger against budget.
+func (s *StatusBar) SetBudget(ledger *cost.Ledger, budget cost.Budget) {
+	s.ledger = ledger
+	s.budget = budget
+}
+
 func (s *StatusBar) SetFlash(msg string) {
 	s.SetFlashWithLevel(msg, FlashInfo)
 }
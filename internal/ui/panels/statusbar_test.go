@@ -3,8 +3,10 @@ package panels
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/justinpbarnett/agtop/internal/cost"
 	"github.com/justinpbarnett/agtop/internal/run"
 )
 
@@ -113,3 +115,22 @@ func TestStatusBarNoFlashNoOverflow(t *testing.T) {
 		t.Errorf("status bar width %d exceeds terminal width 80 (no flash)", w)
 	}
 }
+
+func TestStatusBarBudgetGauge(t *testing.T) {
+	ledger := cost.NewLedger(t.TempDir())
+	if err := ledger.Record("001", 45, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	sb := NewStatusBar(run.NewStore())
+	sb.SetSize(160)
+
+	if strings.Contains(sb.View(), "Budget:") {
+		t.Error("expected no budget gauge without a budget")
+	}
+
+	sb.SetBudget(ledger, cost.Budget{Daily: 50, Monthly: 1000})
+	view := sb.View()
+	if !strings.Contains(view, "Budget: $5.00 left today") {
+		t.Errorf("expected the daily budget to be shown, got %q", view)
+	}
+}
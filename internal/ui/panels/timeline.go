@@ -0,0 +1,212 @@
+package panels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/justinpbarnett/agtop/internal/process"
+	"github.com/justinpbarnett/agtop/internal/ui/styles"
+)
+
+// Timeline column widths.
+const (
+	timelineLabelWidth = 32
+	timelineDurWidth   = 8
+	timelineMinBar     = 10
+)
+
+// renderTimeline draws a run's tool calls as a Gantt chart, one section per
+// skill process. Each section header splits the skill's wall time into time
+// spent in tools and time spent waiting on the model.
+func renderTimeline(tl *process.Timeline, width int, now time.Time) string {
+	if tl == nil {
+		return styles.TextDimStyle.Render("No timeline for this run")
+	}
+	spans := tl.Skills()
+	if len(spans) == 0 {
+		return styles.TextDimStyle.Render("Waiting for output...")
+	}
+	calls := tl.Calls()
+
+	bySpan := make(map[int][]process.ToolCall)
+	for _, c := range calls {
+		bySpan[c.Span] = append(bySpan[c.Span], c)
+	}
+
+	labelW := timelineLabelWidth
+	if labelW > width/3 {
+		labelW = width / 3
+	}
+	barW := width - labelW - timelineDurWidth - 2
+	if barW < timelineMinBar {
+		barW = 0
+	}
+
+	var total, totalTools time.Duration
+	var sections []string
+	for i, span := range spans {
+		end := span.End
+		if end.IsZero() {
+			end = now
+		}
+		wall := end.Sub(span.Start)
+		tools := toolTime(bySpan[i], span, end)
+		total += wall
+		totalTools += tools
+
+		header := styles.TextSecondaryStyle.Render(span.Name) + " " +
+			styles.TextDimStyle.Render(fmt.Sprintf("%s · tools %s · model %s",
+				formatSpan(wall), formatSpan(tools), formatSpan(wall-tools)))
+		if span.End.IsZero() {
+			header += styles.TextDimStyle.Render(" …")
+		}
+		lines := []string{header}
+		if len(bySpan[i]) == 0 {
+			lines = append(lines, styles.TextDimStyle.Render("  no tool calls"))
+		}
+		for _, c := range bySpan[i] {
+			lines = append(lines, timelineRow(c, span, end, labelW, barW, now))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	summary := styles.TitleStyle.Render("Total") + " " + styles.TextDimStyle.Render(fmt.Sprintf("%s · tools %s (%s) · model %s",
+		formatSpan(total), formatSpan(totalTools), formatShare(totalTools, total), formatSpan(total-totalTools)))
+	return summary + "\n\n" + strings.Join(sections, "\n\n")
+}
+
+// timelineRow renders one tool call: its label, a bar placed within the
+// skill's span, and its duration.
+func timelineRow(c process.ToolCall, span process.SkillSpan, end time.Time, labelW, barW int, now time.Time) string {
+	label := strings.TrimPrefix(c.Summary, "Tool: ")
+	label = strings.Repeat("  ", c.Depth+1) + label
+	label = ansi.Truncate(label, labelW, "…")
+	label += strings.Repeat(" ", labelW-lipgloss.Width(label))
+
+	// A call that never got a result in a finished skill has no known end.
+	unknown := !c.Done() && !span.End.IsZero()
+
+	barStyle := lipgloss.NewStyle().Foreground(styles.StatusSuccess)
+	dur := formatSpan(c.Duration(now))
+	switch {
+	case c.Error:
+		barStyle = lipgloss.NewStyle().Foreground(styles.StatusError)
+		dur = "✗ " + dur
+	case unknown:
+		barStyle = styles.TextDimStyle
+		dur = "—"
+	case !c.Done():
+		barStyle = lipgloss.NewStyle().Foreground(styles.StatusRunning)
+	}
+	durCol := fmt.Sprintf("%*s", timelineDurWidth, dur)
+
+	if barW == 0 {
+		return label + " " + barStyle.Render(durCol)
+	}
+
+	wall := end.Sub(span.Start)
+	col := func(t time.Time) int {
+		if wall <= 0 {
+			return 0
+		}
+		x := int(float64(barW) * float64(t.Sub(span.Start)) / float64(wall))
+		if x < 0 {
+			return 0
+		}
+		if x > barW {
+			return barW
+		}
+		return x
+	}
+	callEnd := c.End
+	if !c.Done() {
+		callEnd = end
+	}
+	from := col(c.Start)
+	to := col(callEnd)
+	if from >= barW {
+		from = barW - 1
+	}
+	if to <= from {
+		to = from + 1
+	}
+	glyph := "█"
+	if unknown {
+		glyph, to = "·", from+1
+	}
+	bar := strings.Repeat(" ", from) + barStyle.Render(strings.Repeat(glyph, to-from)) + strings.Repeat(" ", barW-to)
+	return label + " " + bar + " " + barStyle.Render(durCol)
+}
+
+// toolTime returns how much of a span was spent inside top-level tool
+// calls. Overlapping calls are counted once; calls of Task sub-agents run
+// inside their Task call and are skipped.
+func toolTime(calls []process.ToolCall, span process.SkillSpan, end time.Time) time.Duration {
+	type interval struct{ from, to time.Time }
+	var ivs []interval
+	for _, c := range calls {
+		if c.Depth > 0 {
+			continue
+		}
+		to := c.End
+		if !c.Done() {
+			if !span.End.IsZero() {
+				continue
+			}
+			to = end
+		}
+		from := c.Start
+		if from.Before(span.Start) {
+			from = span.Start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if to.After(from) {
+			ivs = append(ivs, interval{from, to})
+		}
+	}
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i].from.Before(ivs[j].from) })
+
+	var total time.Duration
+	var cur interval
+	for i, iv := range ivs {
+		switch {
+		case i == 0:
+			cur = iv
+		case iv.from.After(cur.to):
+			total += cur.to.Sub(cur.from)
+			cur = iv
+		case iv.to.After(cur.to):
+			cur.to = iv.to
+		}
+	}
+	if len(ivs) > 0 {
+		total += cur.to.Sub(cur.from)
+	}
+	return total
+}
+
+// formatSpan formats a duration with second precision, or tenths of a
+// second below a minute: "0.4s", "12.3s", "4m12s", "1h2m0s".
+func formatSpan(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// formatShare formats part as a percentage of whole.
+func formatShare(part, whole time.Duration) string {
+	if whole <= 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", 100*float64(part)/float64(whole))
+}
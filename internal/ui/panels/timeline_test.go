@@ -0,0 +1,80 @@
+package panels
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/justinpbarnett/agtop/internal/process"
+)
+
+func sampleTimeline(t0 time.Time) *process.Timeline {
+	tl := process.NewTimeline()
+	tl.StartSkill("build", t0)
+	tl.Record(process.StreamEvent{Type: process.EventToolUse, ToolName: "Bash", ToolInput: `{"command":"go test ./..."}`, ToolID: "a"}, "build", t0.Add(10*time.Second))
+	tl.Record(process.StreamEvent{Type: process.EventToolResult, ToolID: "a", IsError: true}, "build", t0.Add(40*time.Second))
+	tl.Record(process.StreamEvent{Type: process.EventToolUse, ToolName: "Read", ToolInput: `{"file_path":"main.go"}`, ToolID: "b"}, "build", t0.Add(20*time.Second))
+	tl.Record(process.StreamEvent{Type: process.EventToolResult, ToolID: "b"}, "build", t0.Add(30*time.Second))
+	tl.EndSkill(t0.Add(time.Minute))
+	return tl
+}
+
+func TestRenderTimeline(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	out := renderTimeline(sampleTimeline(t0), 100, t0.Add(2*time.Minute))
+
+	// The Read call overlaps the Bash call, so tools took 30s of the minute.
+	for _, want := range []string{
+		"Total 1m0s · tools 30.0s (50%) · model 30.0s",
+		"build 1m0s · tools 30.0s · model 30.0s",
+		"Bash — go test ./...",
+		"✗ 30.0s",
+		"Read — main.go",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in timeline:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderTimelineEmpty(t *testing.T) {
+	if out := renderTimeline(nil, 80, time.Now()); !strings.Contains(out, "No timeline") {
+		t.Errorf("unexpected output for nil timeline: %q", out)
+	}
+}
+
+func TestRenderTimelineUnpairedCall(t *testing.T) {
+	t0 := time.Now()
+	tl := process.NewTimeline()
+	tl.StartSkill("build", t0)
+	tl.Record(process.StreamEvent{Type: process.EventToolUse, ToolName: "Edit"}, "build", t0.Add(time.Second))
+	tl.EndSkill(t0.Add(2 * time.Second))
+
+	out := renderTimeline(tl, 100, t0.Add(time.Minute))
+	if !strings.Contains(out, "—") || !strings.Contains(out, "tools 0.0s") {
+		t.Errorf("expected an unpaired call with no duration:\n%s", out)
+	}
+}
+
+func TestLogViewTimelineTab(t *testing.T) {
+	lv := NewLogView()
+	lv.SetSize(100, 20)
+	lv.SetFocused(true)
+	t0 := time.Now().Add(-time.Hour)
+	lv.SetTimeline(sampleTimeline(t0))
+
+	lv, _ = lv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	lv, _ = lv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	if lv.ActiveTab() != tabTimeline {
+		t.Fatalf("expected timeline tab after l l, got %d", lv.ActiveTab())
+	}
+	if view := lv.View(); !strings.Contains(view, "Bash — go test") {
+		t.Errorf("expected timeline content, got:\n%s", view)
+	}
+
+	lv, _ = lv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	if lv.ActiveTab() != tabDiff {
+		t.Errorf("expected diff tab after h, got %d", lv.ActiveTab())
+	}
+}
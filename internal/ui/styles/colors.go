@@ -55,7 +55,7 @@ func RunStateColor(state run.State) lipgloss.AdaptiveColor {
 		return StatusSuccess
 	case run.StateFailed, run.StateRejected:
 		return StatusError
-	case run.StatePaused, run.StateReviewing:
+	case run.StatePaused, run.StateReviewing, run.StateAwaitingApproval:
 		return StatusWarning
 	case run.StateQueued:
 		return StatusPending
@@ -63,3 +63,19 @@ func RunStateColor(state run.State) lipgloss.AdaptiveColor {
 		return TextDim
 	}
 }
+
+// NodeStateColor returns the status color for a graph workflow step.
+func NodeStateColor(state run.NodeState) lipgloss.AdaptiveColor {
+	switch state {
+	case run.NodeRunning:
+		return StatusRunning
+	case run.NodeCompleted:
+		return StatusSuccess
+	case run.NodeFailed:
+		return StatusError
+	case run.NodeSkipped:
+		return TextDim
+	default:
+		return StatusPending
+	}
+}